@@ -1,6 +1,7 @@
 package project_test
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/infraboard/mcube/v2/cmd/mcube/project"
@@ -15,6 +16,6 @@ func TestSaveFile(t *testing.T) {
 		Name: "test",
 	}
 
-	err := p.SaveFile(project.PROJECT_SETTING_FILE_PATH)
+	err := p.SaveFile(filepath.Join(t.TempDir(), project.PROJECT_SETTING_FILE_PATH))
 	should.NoError(err)
 }
@@ -0,0 +1,257 @@
+package accesslog
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/infraboard/mcube/v2/http/context"
+	"github.com/infraboard/mcube/v2/http/middleware/realip"
+	"github.com/infraboard/mcube/v2/http/response"
+	"github.com/infraboard/mcube/v2/ioc/config/log"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 访问日志的字段
+const (
+	FIELD_METHOD     = "method"
+	FIELD_ROUTE      = "route"
+	FIELD_PATH       = "path"
+	FIELD_QUERY      = "query"
+	FIELD_STATUS     = "status"
+	FIELD_BYTES_IN   = "bytes_in"
+	FIELD_BYTES_OUT  = "bytes_out"
+	FIELD_LATENCY    = "latency"
+	FIELD_REMOTE_IP  = "remote_ip"
+	FIELD_TRACE_ID   = "trace_id"
+	FIELD_REQUEST_ID = "request_id"
+	FIELD_USER       = "user"
+	FIELD_USER_AGENT = "user_agent"
+	FIELD_HOST       = "host"
+	FIELD_PROTO      = "proto"
+)
+
+// 所有支持的字段, 没有配置字段白名单时输出除 query 以外的所有字段
+var (
+	ALL_FIELDS = []string{
+		FIELD_METHOD, FIELD_ROUTE, FIELD_PATH, FIELD_QUERY, FIELD_STATUS,
+		FIELD_BYTES_IN, FIELD_BYTES_OUT, FIELD_LATENCY, FIELD_REMOTE_IP,
+		FIELD_TRACE_ID, FIELD_REQUEST_ID, FIELD_USER, FIELD_USER_AGENT,
+		FIELD_HOST, FIELD_PROTO,
+	}
+	DEFAULT_FIELDS = slices.DeleteFunc(slices.Clone(ALL_FIELDS), func(f string) bool { return f == FIELD_QUERY })
+)
+
+// Options 结构化访问日志的参数
+type Options struct {
+	// 字段白名单, 为空时使用 DEFAULT_FIELDS
+	Fields []string
+	// 不记录日志的路径, 以 * 结尾时按前缀匹配
+	ExcludePaths []string
+	// 2xx 响应的采样率, 取值 0~1, 错误响应以及慢请求总是记录
+	SuccessSampleRate float64
+	// 慢请求阈值, 超过时日志级别提升为 warn, 0 表示不检查
+	SlowThreshold time.Duration
+}
+
+// DefaultOptions 默认参数, 不记录健康检查以及指标接口, 记录所有的请求
+func DefaultOptions() *Options {
+	return &Options{
+		ExcludePaths:      []string{"/healthz", "/readyz", "/metrics"},
+		SuccessSampleRate: 1,
+		SlowThreshold:     time.Second,
+	}
+}
+
+// UserFunc 从请求中获取用户信息, 返回空表示没有用户信息
+type UserFunc func(r *http.Request) string
+
+// Record 一次请求的访问记录, 由各个框架的适配层填充
+type Record struct {
+	// 请求处理完成后的请求, 用于读取认证等中间件写入上下文的信息
+	Request *http.Request
+	// 路由模板, 比如 /books/{id}, 未匹配路由时为空
+	Route    string
+	Status   int
+	BytesOut int64
+	Latency  time.Duration
+}
+
+// Structured 结构化访问日志, gin、go-restful 以及 httprouter 共用
+type Structured struct {
+	opts      *Options
+	fields    []string
+	logger    *zerolog.Logger
+	userFuncs []UserFunc
+	sample    func() float64
+}
+
+// NewStructured 创建结构化访问日志, 字段白名单中存在不支持的字段时返回错误
+func NewStructured(opts *Options) (*Structured, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	fields := opts.Fields
+	if len(fields) == 0 {
+		fields = DEFAULT_FIELDS
+	}
+	for _, f := range fields {
+		if !slices.Contains(ALL_FIELDS, f) {
+			return nil, fmt.Errorf("access log field %s not supported, supported fields: %v", f, ALL_FIELDS)
+		}
+	}
+	return &Structured{opts: opts, fields: fields, logger: log.Sub("access_log"), sample: rand.Float64}, nil
+}
+
+// SetLogger 设置输出的 logger, 默认为 access_log 子 logger, 需要在处理请求之前设置
+func (s *Structured) SetLogger(l *zerolog.Logger) {
+	s.logger = l
+}
+
+// AddUserFunc 添加获取用户信息的函数, 按添加顺序使用第一个非空的结果
+func (s *Structured) AddUserFunc(fn UserFunc) {
+	s.userFuncs = append(s.userFuncs, fn)
+}
+
+// Skip 请求路径是否不需要记录
+func (s *Structured) Skip(r *http.Request) bool {
+	for _, p := range s.opts.ExcludePaths {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				return true
+			}
+		} else if r.URL.Path == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Log 记录访问日志: 5xx 为 error, 4xx 以及慢请求为 warn, 其他为 info
+// 2xx 的非慢请求按采样率记录
+func (s *Structured) Log(rec *Record) {
+	slow := s.opts.SlowThreshold > 0 && rec.Latency >= s.opts.SlowThreshold
+	var level zerolog.Level
+	switch {
+	case rec.Status >= 500:
+		level = zerolog.ErrorLevel
+	case rec.Status >= 400 || slow:
+		level = zerolog.WarnLevel
+	default:
+		level = zerolog.InfoLevel
+		if rec.Status < 300 && s.opts.SuccessSampleRate < 1 && s.sample() >= s.opts.SuccessSampleRate {
+			return
+		}
+	}
+
+	e := s.getLogger().WithLevel(level)
+	if e == nil {
+		return
+	}
+	r := rec.Request
+	for _, f := range s.fields {
+		switch f {
+		case FIELD_METHOD:
+			e.Str(f, r.Method)
+		case FIELD_ROUTE:
+			e.Str(f, rec.Route)
+		case FIELD_PATH:
+			e.Str(f, r.URL.Path)
+		case FIELD_QUERY:
+			e.Str(f, r.URL.RawQuery)
+		case FIELD_STATUS:
+			e.Int(f, rec.Status)
+		case FIELD_BYTES_IN:
+			e.Int64(f, max(r.ContentLength, 0))
+		case FIELD_BYTES_OUT:
+			e.Int64(f, max(rec.BytesOut, 0))
+		case FIELD_LATENCY:
+			e.Dur(f, rec.Latency)
+		case FIELD_REMOTE_IP:
+			e.Str(f, realip.FromRequest(r))
+		case FIELD_TRACE_ID:
+			if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+				e.Str(f, sc.TraceID().String())
+			}
+		case FIELD_REQUEST_ID:
+			if v := r.Header.Get("X-Request-Id"); v != "" {
+				e.Str(f, v)
+			}
+		case FIELD_USER:
+			if v := s.user(r); v != "" {
+				e.Str(f, v)
+			}
+		case FIELD_USER_AGENT:
+			e.Str(f, r.UserAgent())
+		case FIELD_HOST:
+			e.Str(f, r.Host)
+		case FIELD_PROTO:
+			e.Str(f, r.Proto)
+		}
+	}
+	if slow {
+		e.Bool("slow", true)
+	}
+	e.Msg("access")
+}
+
+func (s *Structured) user(r *http.Request) string {
+	for _, fn := range s.userFuncs {
+		if v := fn(r); v != "" {
+			return v
+		}
+	}
+	// httprouter 认证后的信息
+	if rc := context.GetContext(r); rc.AuthInfo != nil {
+		if v, ok := rc.AuthInfo.(fmt.Stringer); ok {
+			return v.String()
+		}
+		if v, ok := rc.AuthInfo.(string); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+func (s *Structured) getLogger() *zerolog.Logger {
+	return s.logger
+}
+
+// Handler 实现中间件, 可以通过 router.Use 用于 httprouter 以及标准库的 http.Handler
+func (s *Structured) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if s.Skip(r) {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		start := time.Now()
+		res, ok := rw.(response.Response)
+		if !ok {
+			res = response.NewResponse(rw)
+		}
+		// 提前放入请求上下文, 路由匹配以及认证的结果写入其中
+		rc := context.GetContext(r)
+		r = context.WithContext(r, rc)
+
+		next.ServeHTTP(res, r)
+
+		rec := &Record{
+			Request:  r,
+			Status:   res.Status(),
+			BytesOut: int64(res.Size()),
+			Latency:  time.Since(start),
+		}
+		if rc.Entry != nil {
+			rec.Route = rc.Entry.Path
+		}
+		if rec.Status == 0 {
+			rec.Status = http.StatusOK
+		}
+		s.Log(rec)
+	})
+}
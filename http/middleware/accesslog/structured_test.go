@@ -0,0 +1,96 @@
+package accesslog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/infraboard/mcube/v2/http/middleware/accesslog"
+	"github.com/infraboard/mcube/v2/http/router/httprouter"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func newStructured(t *testing.T, opts *accesslog.Options) (*accesslog.Structured, *bytes.Buffer) {
+	s, err := accesslog.NewStructured(opts)
+	require.NoError(t, err)
+	buf := bytes.NewBuffer(nil)
+	l := zerolog.New(buf)
+	s.SetLogger(&l)
+	return s, buf
+}
+
+func lines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		m := map[string]any{}
+		require.NoError(t, json.Unmarshal([]byte(line), &m))
+		out = append(out, m)
+	}
+	return out
+}
+
+func TestStructuredHandler(t *testing.T) {
+	should := require.New(t)
+	s, buf := newStructured(t, accesslog.DefaultOptions())
+	s.AddUserFunc(func(r *http.Request) string { return r.Header.Get("X-User") })
+
+	r := httprouter.New()
+	r.Use(s)
+	r.Handle("GET", "/books/:id", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("not found"))
+	})
+	r.Handle("GET", "/healthz", indexHandler)
+
+	req := httptest.NewRequest("GET", "/books/1?a=b", nil)
+	req.Header.Set("X-Forwarded-For", "10.0.0.1")
+	req.Header.Set("X-User", "alice")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+
+	logs := lines(t, buf)
+	should.Len(logs, 1)
+	should.Equal("warn", logs[0]["level"])
+	should.Equal("/books/:id", logs[0]["route"])
+	should.Equal("/books/1", logs[0]["path"])
+	should.Equal(float64(404), logs[0]["status"])
+	should.Equal(float64(9), logs[0]["bytes_out"])
+	should.Equal("10.0.0.1", logs[0]["remote_ip"])
+	should.Equal("alice", logs[0]["user"])
+	should.NotContains(logs[0], "query")
+}
+
+func TestStructuredLevel(t *testing.T) {
+	should := require.New(t)
+	s, buf := newStructured(t, &accesslog.Options{
+		Fields:            []string{accesslog.FIELD_STATUS},
+		SuccessSampleRate: 0,
+		SlowThreshold:     time.Second,
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	// 2xx 不采样, 错误以及慢请求总是记录
+	s.Log(&accesslog.Record{Request: req, Status: 200})
+	s.Log(&accesslog.Record{Request: req, Status: 200, Latency: 2 * time.Second})
+	s.Log(&accesslog.Record{Request: req, Status: 500})
+
+	logs := lines(t, buf)
+	should.Len(logs, 2)
+	should.Equal("warn", logs[0]["level"])
+	should.Equal(true, logs[0]["slow"])
+	should.Equal("error", logs[1]["level"])
+	should.Len(logs[1], 3)
+}
+
+func TestStructuredFields(t *testing.T) {
+	_, err := accesslog.NewStructured(&accesslog.Options{Fields: []string{"unknown"}})
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package realip
 
 import (
+	"net"
 	"net/http"
 	"strings"
 
@@ -90,3 +91,16 @@ func (r *realip) realIP(req *http.Request) string {
 
 	return ip
 }
+
+// FromRequest 按 X-Forwarded-For、X-Real-IP 的顺序获取客户端IP, 都没有时使用连接的地址
+func FromRequest(req *http.Request) string {
+	r := &realip{}
+	r.addScanKey(defaultScanForwareHeaderKey)
+	if ip := r.realIP(req); ip != "" {
+		return ip
+	}
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		return host
+	}
+	return req.RemoteAddr
+}
@@ -0,0 +1,61 @@
+package admin_test
+
+import (
+	"testing"
+
+	"github.com/infraboard/mcube/v2/ioc/apps/admin"
+)
+
+func TestMask(t *testing.T) {
+	a := admin.NewDefaultAdmin()
+	masked := a.Mask(map[string]any{
+		"host":          "127.0.0.1",
+		"password":      "123456",
+		"client_secret": "abc",
+		"encrypt_key":   "",
+		"key_file":      "etc/server.key",
+		"dsn":           "root:123456@tcp(127.0.0.1:3306)/test",
+		"auth": map[string]any{
+			"Token": "xxx",
+		},
+		"extras": map[string]string{
+			"api_key": "yyy",
+		},
+	})
+
+	if masked["host"] != "127.0.0.1" || masked["key_file"] != "etc/server.key" {
+		t.Fatalf("normal values should not be masked, got %v", masked)
+	}
+	if masked["password"] != admin.MASKED_VALUE || masked["client_secret"] != admin.MASKED_VALUE ||
+		masked["dsn"] != admin.MASKED_VALUE {
+		t.Fatalf("secret values should be masked, got %v", masked)
+	}
+	if masked["encrypt_key"] != "" {
+		t.Fatalf("empty secret should keep empty, got %v", masked)
+	}
+	if masked["auth"].(map[string]any)["Token"] != admin.MASKED_VALUE ||
+		masked["extras"].(map[string]any)["api_key"] != admin.MASKED_VALUE {
+		t.Fatalf("nested secret values should be masked, got %v", masked)
+	}
+}
+
+func TestCheckToken(t *testing.T) {
+	a := admin.NewDefaultAdmin()
+	a.Token = "admin-token"
+
+	if err := a.CheckToken("", ""); err == nil {
+		t.Fatal("token should be required")
+	}
+	if err := a.CheckToken("wrong", ""); err == nil {
+		t.Fatal("wrong token should be rejected")
+	}
+	if err := a.CheckToken("admin-token", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.CheckToken("", "Bearer admin-token"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.CheckToken("", "admin-token"); err == nil {
+		t.Fatal("authorization without Bearer prefix should be rejected")
+	}
+}
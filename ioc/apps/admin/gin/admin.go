@@ -0,0 +1,88 @@
+package gin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	h_response "github.com/infraboard/mcube/v2/http/gin/response"
+	"github.com/infraboard/mcube/v2/ioc"
+	"github.com/infraboard/mcube/v2/ioc/apps/admin"
+	ioc_gin "github.com/infraboard/mcube/v2/ioc/config/gin"
+	ioc_http "github.com/infraboard/mcube/v2/ioc/config/http"
+	"github.com/infraboard/mcube/v2/ioc/config/log"
+	"github.com/rs/zerolog"
+)
+
+func init() {
+	ioc.Api().Registry(&AdminHandler{
+		Admin: *admin.NewDefaultAdmin(),
+	})
+}
+
+type AdminHandler struct {
+	ioc.ObjectImpl
+	admin.Admin
+	log *zerolog.Logger
+}
+
+func (h *AdminHandler) Name() string {
+	return admin.AppName
+}
+
+func (h *AdminHandler) Meta() ioc.ObjectMeta {
+	meta := ioc.DefaultObjectMeta()
+	meta.CustomPathPrefix = h.Path
+	return meta
+}
+
+func (h *AdminHandler) Init() error {
+	h.log = log.Sub(admin.AppName)
+	if h.Token == "" {
+		h.log.Warn().Msg("admin token not configured, admin api disabled")
+		return nil
+	}
+	h.Registry()
+	return nil
+}
+
+func (h *AdminHandler) Registry() {
+	r := ioc_gin.ObjectRouter(h)
+	r.Use(h.Auth)
+	r.GET("/namespaces", h.Namespaces)
+	r.GET("/objects", h.Objects)
+	r.GET("/dependencies", h.Dependencies)
+	r.GET("/configs", h.Configs)
+
+	h.log.Info().Msgf("Get the ioc admin api using http://%s%s", ioc_http.Get().Addr(), h.Path)
+}
+
+// Auth 校验访问令牌
+func (h *AdminHandler) Auth(c *gin.Context) {
+	if err := h.CheckToken(c.GetHeader(admin.TOKEN_HEADER), c.GetHeader("Authorization")); err != nil {
+		h_response.Failed(c, err)
+		return
+	}
+	c.Next()
+}
+
+func (h *AdminHandler) Namespaces(c *gin.Context) {
+	c.JSON(http.StatusOK, ioc.DefaultStore.Namespaces())
+}
+
+func (h *AdminHandler) Objects(c *gin.Context) {
+	c.JSON(http.StatusOK, ioc.DefaultStore.Objects())
+}
+
+// Dependencies 依赖图, format=dot 时返回 Graphviz DOT 格式
+func (h *AdminHandler) Dependencies(c *gin.Context) {
+	graph := h.DependencyGraph()
+	if c.Query("format") == "dot" {
+		c.Data(http.StatusOK, admin.MIME_DOT, []byte(graph.DOT()))
+		return
+	}
+	c.JSON(http.StatusOK, graph)
+}
+
+func (h *AdminHandler) Configs(c *gin.Context) {
+	c.JSON(http.StatusOK, h.Admin.Configs())
+}
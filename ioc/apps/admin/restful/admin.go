@@ -0,0 +1,110 @@
+package restful
+
+import (
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	"github.com/emicklei/go-restful/v3"
+	"github.com/infraboard/mcube/v2/http/restful/response"
+	"github.com/infraboard/mcube/v2/ioc"
+	"github.com/infraboard/mcube/v2/ioc/apps/admin"
+	"github.com/infraboard/mcube/v2/ioc/config/gorestful"
+	"github.com/infraboard/mcube/v2/ioc/config/http"
+	"github.com/infraboard/mcube/v2/ioc/config/log"
+	"github.com/rs/zerolog"
+)
+
+func init() {
+	ioc.Api().Registry(&AdminHandler{
+		Admin: *admin.NewDefaultAdmin(),
+	})
+}
+
+type AdminHandler struct {
+	ioc.ObjectImpl
+	admin.Admin
+	log *zerolog.Logger
+}
+
+func (h *AdminHandler) Name() string {
+	return admin.AppName
+}
+
+func (h *AdminHandler) Meta() ioc.ObjectMeta {
+	meta := ioc.DefaultObjectMeta()
+	meta.CustomPathPrefix = h.Path
+	return meta
+}
+
+func (h *AdminHandler) Init() error {
+	h.log = log.Sub(admin.AppName)
+	if h.Token == "" {
+		h.log.Warn().Msg("admin token not configured, admin api disabled")
+		return nil
+	}
+	h.Registry()
+	return nil
+}
+
+func (h *AdminHandler) Registry() {
+	tags := []string{"容器管理"}
+
+	ws := gorestful.ObjectRouter(h)
+	ws.Filter(h.Auth)
+	ws.Route(ws.GET("/namespaces").To(h.Namespaces).
+		Doc("查询命名空间").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", []ioc.NamespaceInfo{}))
+	ws.Route(ws.GET("/objects").To(h.Objects).
+		Doc("查询托管的对象").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", []ioc.ObjectInfo{}))
+	ws.Route(ws.GET("/dependencies").To(h.Dependencies).
+		Doc("查询对象依赖图, format=dot 时返回 Graphviz DOT 格式").
+		Param(ws.QueryParameter("format", "json/dot").DataType("string")).
+		Produces(restful.MIME_JSON, admin.MIME_DOT).
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", ioc.DependencyGraph{}))
+	ws.Route(ws.GET("/configs").To(h.Configs).
+		Doc("查询对象当前生效的配置, 敏感配置已脱敏").
+		Metadata(restfulspec.KeyOpenAPITags, tags))
+
+	h.log.Info().Msgf("Get the ioc admin api using http://%s%s", http.Get().Addr(), h.Path)
+}
+
+// Auth 校验访问令牌
+func (h *AdminHandler) Auth(r *restful.Request, w *restful.Response, chain *restful.FilterChain) {
+	if err := h.CheckToken(r.HeaderParameter(admin.TOKEN_HEADER), r.HeaderParameter("Authorization")); err != nil {
+		response.Failed(w, err)
+		return
+	}
+	chain.ProcessFilter(r, w)
+}
+
+func (h *AdminHandler) Namespaces(r *restful.Request, w *restful.Response) {
+	h.write(w, ioc.DefaultStore.Namespaces())
+}
+
+func (h *AdminHandler) Objects(r *restful.Request, w *restful.Response) {
+	h.write(w, ioc.DefaultStore.Objects())
+}
+
+func (h *AdminHandler) Dependencies(r *restful.Request, w *restful.Response) {
+	graph := h.DependencyGraph()
+	if r.QueryParameter("format") == "dot" {
+		w.Header().Set("Content-Type", admin.MIME_DOT)
+		if _, err := w.Write([]byte(graph.DOT())); err != nil {
+			h.log.Error().Msgf("send dot response error, %s", err)
+		}
+		return
+	}
+	h.write(w, graph)
+}
+
+func (h *AdminHandler) Configs(r *restful.Request, w *restful.Response) {
+	h.write(w, h.Admin.Configs())
+}
+
+func (h *AdminHandler) write(w *restful.Response, data any) {
+	if err := w.WriteAsJson(data); err != nil {
+		h.log.Error().Msgf("send response error, %s", err)
+	}
+}
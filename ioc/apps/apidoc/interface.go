@@ -1,5 +1,12 @@
 package apidoc
 
+import (
+	"reflect"
+
+	"github.com/go-openapi/spec"
+	"github.com/infraboard/mcube/v2/ioc"
+)
+
 const (
 	AppName = "apidoc"
 )
@@ -20,3 +27,18 @@ type ApiDoc struct {
 	// Swagger UI path
 	UIPath string `json:"ui_path" yaml:"ui_path" toml:"ui_path" env:"UI_PATH"`
 }
+
+var swaggerEnricherType = reflect.TypeOf((*SwaggerEnricher)(nil)).Elem()
+
+// SwaggerEnricher 补充接口文档, 比如 grpc gateway 根据 proto 描述生成的接口
+// 注册在 Api 命名空间中的对象实现该接口即可
+type SwaggerEnricher interface {
+	EnrichSwagger(swo *spec.Swagger)
+}
+
+// EnrichSwagger 使用 Api 命名空间中所有的 SwaggerEnricher 补充接口文档
+func EnrichSwagger(swo *spec.Swagger) {
+	for _, obj := range ioc.DefaultStore.Namespace(ioc.API_NAMESPACE).ImplementInterface(swaggerEnricherType) {
+		obj.(SwaggerEnricher).EnrichSwagger(swo)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	restfulspec "github.com/emicklei/go-restful-openapi/v2"
 	"github.com/emicklei/go-restful/v3"
+	"github.com/go-openapi/spec"
 	"github.com/infraboard/mcube/v2/ioc"
 	"github.com/infraboard/mcube/v2/ioc/apps/apidoc"
 	"github.com/infraboard/mcube/v2/ioc/config/application"
@@ -100,7 +101,7 @@ func (h *SwaggerApiDoc) SwaggerDocConfig() restfulspec.Config {
 		Host:                          application.Get().Host(),
 		WebServices:                   restful.RegisteredWebServices(),
 		APIPath:                       http.Get().ApiObjectPathPrefix(h),
-		PostBuildSwaggerObjectHandler: http.Get().SwagerDocs,
+		PostBuildSwaggerObjectHandler: h.PostBuildSwagger,
 		DefinitionNameHandler: func(name string) string {
 			if name == "state" || name == "sizeCache" || name == "unknownFields" {
 				return ""
@@ -110,3 +111,9 @@ func (h *SwaggerApiDoc) SwaggerDocConfig() restfulspec.Config {
 		Schemes: []string{"http", "https"},
 	}
 }
+
+// PostBuildSwagger 补充安全定义以及 grpc gateway 等自动生成的接口
+func (h *SwaggerApiDoc) PostBuildSwagger(swo *spec.Swagger) {
+	http.Get().SwagerDocs(swo)
+	apidoc.EnrichSwagger(swo)
+}
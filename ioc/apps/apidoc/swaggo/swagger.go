@@ -1,10 +1,13 @@
 package swaggo
 
 import (
+	"encoding/json"
 	"fmt"
+	stdhttp "net/http"
 	"path/filepath"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/spec"
 	"github.com/infraboard/mcube/v2/ioc"
 	"github.com/infraboard/mcube/v2/ioc/apps/apidoc"
 	"github.com/infraboard/mcube/v2/ioc/config/application"
@@ -78,7 +81,17 @@ func (h *SwaggerApiDoc) ApiUIPath() string {
 }
 
 func (h *SwaggerApiDoc) SwaggerJson(ctx *gin.Context) {
-	ctx.Writer.WriteString(swag.GetSwagger(h.InstanceName).ReadDoc())
+	doc := swag.GetSwagger(h.InstanceName).ReadDoc()
+
+	// 补充 grpc gateway 等自动生成的接口
+	swo := &spec.Swagger{}
+	if err := json.Unmarshal([]byte(doc), swo); err != nil {
+		h.log.Error().Msgf("parse swagger doc error, %s", err)
+		ctx.Writer.WriteString(doc)
+		return
+	}
+	apidoc.EnrichSwagger(swo)
+	ctx.JSON(stdhttp.StatusOK, swo)
 }
 
 func (h *SwaggerApiDoc) SwaggerUI(ctx *gin.Context) {
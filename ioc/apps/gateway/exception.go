@@ -0,0 +1,57 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/infraboard/mcube/v2/exception"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPC 状态码对应的 HTTP 状态码
+var httpStatus = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// HttpStatus GRPC 状态码对应的 HTTP 状态码
+func HttpStatus(code codes.Code) int {
+	if v, ok := httpStatus[code]; ok {
+		return v
+	}
+	return http.StatusInternalServerError
+}
+
+// NewApiException 转换 GRPC 调用的错误, 业务异常保持不变, 其他错误按 GRPC 状态码转换
+func NewApiException(err error) *exception.ApiException {
+	var e *exception.ApiException
+	if errors.As(err, &e) {
+		return e
+	}
+
+	st := status.Convert(err)
+	code := HttpStatus(st.Code())
+	reason := http.StatusText(code)
+	if reason == "" {
+		reason = st.Code().String()
+	}
+	return exception.NewApiException(code, reason).
+		WithMessage(st.Message()).
+		WithMeta("grpc_code", st.Code().String())
+}
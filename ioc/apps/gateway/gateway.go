@@ -0,0 +1,64 @@
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/infraboard/mcube/v2/exception"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Gateway 根据 GRPC 服务的 google.api.http 注解自动生成 REST 接口, 请求在进程内转交给 GRPC 服务处理
+type Gateway struct {
+	// 响应使用 proto 字段名称, 默认使用 json 名称(小驼峰)
+	UseProtoNames bool `json:"use_proto_names" yaml:"use_proto_names" toml:"use_proto_names" env:"USE_PROTO_NAMES"`
+	// 响应中输出零值字段
+	EmitUnpopulated bool `json:"emit_unpopulated" yaml:"emit_unpopulated" toml:"emit_unpopulated" env:"EMIT_UNPOPULATED"`
+	// 请求体的最大字节数, 超过时返回 400
+	MaxBodyBytes int64 `json:"max_body_bytes" yaml:"max_body_bytes" toml:"max_body_bytes" env:"MAX_BODY_BYTES"`
+
+	svr    *grpc.Server
+	routes []*Route
+}
+
+// Load 加载 GRPC 服务的路由, 返回的错误为跳过的方法, 不影响其他路由
+func (g *Gateway) Load(svr *grpc.Server) ([]*Route, error) {
+	routes, err := LoadRoutes(svr)
+	for _, r := range routes {
+		r.MaxBodyBytes = g.MaxBodyBytes
+	}
+	g.svr, g.routes = svr, routes
+	return routes, err
+}
+
+// Routes 已加载的路由
+func (g *Gateway) Routes() []*Route {
+	return g.routes
+}
+
+// Handle 处理路由的 HTTP 请求, 返回 JSON 格式的响应
+// 返回的错误已经转换为 ApiException, 由 gin/go-restful 的 response.Failed 返回
+func (g *Gateway) Handle(r *http.Request, route *Route, params map[string]string) ([]byte, error) {
+	in, err := route.NewRequest(r, params)
+	if err != nil {
+		return nil, exception.NewBadRequest("%s", err)
+	}
+
+	out := route.output.New().Interface()
+	if err := Invoke(r.Context(), g.svr, r, route.Method, in, out); err != nil {
+		return nil, NewApiException(err)
+	}
+
+	data, err := route.Marshal(out, g.marshalOptions())
+	if err != nil {
+		return nil, exception.NewInternalServerError("encode response: %s", err)
+	}
+	return data, nil
+}
+
+func (g *Gateway) marshalOptions() protojson.MarshalOptions {
+	return protojson.MarshalOptions{
+		UseProtoNames:   g.UseProtoNames,
+		EmitUnpopulated: g.EmitUnpopulated,
+	}
+}
@@ -0,0 +1,351 @@
+package gateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/spec"
+	"github.com/infraboard/mcube/v2/exception"
+	"github.com/infraboard/mcube/v2/ioc/apps/gateway"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const service = "gwtest.v1.BookService"
+
+var files = func() protoreflect.FileDescriptor {
+	field := func(name string, num int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string, repeated bool) *descriptorpb.FieldDescriptorProto {
+		label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		if repeated {
+			label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+		}
+		f := &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(num), Type: typ.Enum(), Label: label.Enum()}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	message := func(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+	}
+	method := func(name, in, out string, rule *annotations.HttpRule) *descriptorpb.MethodDescriptorProto {
+		opts := &descriptorpb.MethodOptions{}
+		proto.SetExtension(opts, annotations.E_Http, rule)
+		return &descriptorpb.MethodDescriptorProto{Name: proto.String(name), InputType: proto.String(in), OutputType: proto.String(out), Options: opts}
+	}
+	const (
+		str   = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		i64   = descriptorpb.FieldDescriptorProto_TYPE_INT64
+		i32   = descriptorpb.FieldDescriptorProto_TYPE_INT32
+		msg   = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+		enum  = descriptorpb.FieldDescriptorProto_TYPE_ENUM
+		bool_ = descriptorpb.FieldDescriptorProto_TYPE_BOOL
+	)
+
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("gwtest/v1/book.proto"),
+		Package: proto.String("gwtest.v1"),
+		Syntax:  proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Kind"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("KIND_UNSPECIFIED"), Number: proto.Int32(0)},
+				{Name: proto.String("NOVEL"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			message("Book",
+				field("name", 1, str, "", false),
+				field("title", 2, str, "", false),
+				field("pages", 3, i64, "", false),
+				field("kind", 4, enum, ".gwtest.v1.Kind", false),
+				field("tags", 5, str, "", true),
+			),
+			message("GetBookRequest", field("name", 1, str, "", false), field("full_view", 2, bool_, "", false)),
+			message("CreateBookRequest", field("parent", 1, str, "", false), field("book", 2, msg, ".gwtest.v1.Book", false)),
+			message("UpdateBookRequest", field("book", 1, msg, ".gwtest.v1.Book", false)),
+			message("ListBooksRequest", field("parent", 1, str, "", false), field("page_size", 2, i32, "", false), field("tags", 3, str, "", true)),
+			message("ListBooksResponse", field("books", 1, msg, ".gwtest.v1.Book", true)),
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("BookService"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				method("GetBook", ".gwtest.v1.GetBookRequest", ".gwtest.v1.Book", &annotations.HttpRule{
+					Pattern: &annotations.HttpRule_Get{Get: "/v1/books/{name}"},
+					AdditionalBindings: []*annotations.HttpRule{
+						{Pattern: &annotations.HttpRule_Get{Get: "/v1/files/{name=**}"}},
+					},
+				}),
+				method("CreateBook", ".gwtest.v1.CreateBookRequest", ".gwtest.v1.Book", &annotations.HttpRule{
+					Pattern: &annotations.HttpRule_Post{Post: "/v1/shelves/{parent}/books"}, Body: "book",
+				}),
+				method("UpdateBook", ".gwtest.v1.UpdateBookRequest", ".gwtest.v1.Book", &annotations.HttpRule{
+					Pattern: &annotations.HttpRule_Patch{Patch: "/v1/books/{book.name}"}, Body: "book",
+				}),
+				method("ListBooks", ".gwtest.v1.ListBooksRequest", ".gwtest.v1.ListBooksResponse", &annotations.HttpRule{
+					Pattern: &annotations.HttpRule_Get{Get: "/v1/shelves/{parent}/books"}, ResponseBody: "books",
+				}),
+				// 不支持的路径模板
+				method("DeleteBook", ".gwtest.v1.GetBookRequest", ".gwtest.v1.Book", &annotations.HttpRule{
+					Pattern: &annotations.HttpRule_Delete{Delete: "/v1/{name=shelves/*/books/*}"},
+				}),
+			},
+		}},
+	}
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	if err != nil {
+		panic(err)
+	}
+	if err := protoregistry.GlobalFiles.RegisterFile(fd); err != nil {
+		panic(err)
+	}
+	for i := 0; i < fd.Messages().Len(); i++ {
+		if err := protoregistry.GlobalTypes.RegisterMessage(dynamicpb.NewMessageType(fd.Messages().Get(i))); err != nil {
+			panic(err)
+		}
+	}
+	return fd
+}()
+
+// newServer 使用动态消息实现 BookService, 返回请求中的字段方便检查
+func newServer() *grpc.Server {
+	msgs := files.Messages()
+	book := msgs.ByName("Book")
+	get := func(m protoreflect.Message, name string) protoreflect.Value {
+		return m.Get(m.Descriptor().Fields().ByName(protoreflect.Name(name)))
+	}
+	set := func(m protoreflect.Message, name string, v protoreflect.Value) {
+		m.Set(m.Descriptor().Fields().ByName(protoreflect.Name(name)), v)
+	}
+
+	handle := func(in protoreflect.MessageDescriptor, fn func(ctx context.Context, req protoreflect.Message) (proto.Message, error)) grpc.MethodHandler {
+		return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			req := dynamicpb.NewMessage(in)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			return fn(ctx, req)
+		}
+	}
+
+	desc := &grpc.ServiceDesc{
+		ServiceName: service,
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{
+			{MethodName: "GetBook", Handler: handle(msgs.ByName("GetBookRequest"), func(ctx context.Context, req protoreflect.Message) (proto.Message, error) {
+				name := get(req, "name").String()
+				switch name {
+				case "missing":
+					return nil, status.Error(codes.NotFound, "book missing not found")
+				case "biz":
+					e := exception.NewConflict("book %s locked", name)
+					grpc.SetTrailer(ctx, metadata.Pairs(exception.TRAILER_ERROR_JSON_KEY, e.ToJson()))
+					return nil, status.Error(codes.Aborted, e.Error())
+				}
+				resp := dynamicpb.NewMessage(book)
+				set(resp, "name", protoreflect.ValueOfString(name))
+				md, _ := metadata.FromIncomingContext(ctx)
+				set(resp, "title", protoreflect.ValueOfString(strings.Join(md.Get("authorization"), ",")))
+				if get(req, "full_view").Bool() {
+					set(resp, "pages", protoreflect.ValueOfInt64(100))
+				}
+				return resp, nil
+			})},
+			{MethodName: "CreateBook", Handler: handle(msgs.ByName("CreateBookRequest"), func(ctx context.Context, req protoreflect.Message) (proto.Message, error) {
+				resp := get(req, "book").Message().Interface().(*dynamicpb.Message)
+				set(resp, "name", protoreflect.ValueOfString(get(req, "parent").String()+"/"+get(resp, "title").String()))
+				return resp, nil
+			})},
+			{MethodName: "UpdateBook", Handler: handle(msgs.ByName("UpdateBookRequest"), func(ctx context.Context, req protoreflect.Message) (proto.Message, error) {
+				return get(req, "book").Message().Interface(), nil
+			})},
+			{MethodName: "ListBooks", Handler: handle(msgs.ByName("ListBooksRequest"), func(ctx context.Context, req protoreflect.Message) (proto.Message, error) {
+				resp := dynamicpb.NewMessage(msgs.ByName("ListBooksResponse"))
+				list := resp.Mutable(resp.Descriptor().Fields().ByName("books")).List()
+				tags := get(req, "tags").List()
+				for i := 0; i < int(get(req, "page_size").Int()); i++ {
+					b := dynamicpb.NewMessage(book)
+					set(b, "name", protoreflect.ValueOfString(get(req, "parent").String()))
+					bt := b.Mutable(book.Fields().ByName("tags")).List()
+					for j := 0; j < tags.Len(); j++ {
+						bt.Append(tags.Get(j))
+					}
+					list.Append(protoreflect.ValueOfMessage(b))
+				}
+				return resp, nil
+			})},
+			{MethodName: "DeleteBook", Handler: handle(msgs.ByName("GetBookRequest"), func(ctx context.Context, req protoreflect.Message) (proto.Message, error) {
+				return dynamicpb.NewMessage(book), nil
+			})},
+		},
+	}
+	svr := grpc.NewServer()
+	svr.RegisterService(desc, struct{}{})
+	return svr
+}
+
+func load(t *testing.T) (*gateway.Gateway, map[string]*gateway.Route) {
+	g := gateway.DefaultGateway()
+	routes, err := g.Load(newServer())
+	if err == nil || !strings.Contains(err.Error(), "DeleteBook") {
+		t.Fatalf("want DeleteBook skipped, got %v", err)
+	}
+	m := map[string]*gateway.Route{}
+	for _, r := range routes {
+		m[r.HttpMethod+" "+r.Path] = r
+	}
+	return g, m
+}
+
+func TestLoadRoutes(t *testing.T) {
+	_, routes := load(t)
+	if len(routes) != 5 {
+		t.Fatalf("want 5 routes, got %d: %v", len(routes), routes)
+	}
+
+	cases := []struct {
+		key, restful, gin, openapi string
+	}{
+		{"GET /v1/books/{name}", "/v1/books/{name}", "/v1/books/:name", "/v1/books/{name}"},
+		{"GET /v1/files/{name=**}", "/v1/files/{name:*}", "/v1/files/*name", "/v1/files/{name}"},
+		{"PATCH /v1/books/{book.name}", "/v1/books/{book_name}", "/v1/books/:book_name", "/v1/books/{book_name}"},
+	}
+	for _, c := range cases {
+		r := routes[c.key]
+		if r == nil {
+			t.Fatalf("route %s not found", c.key)
+		}
+		if r.RestfulPath() != c.restful || r.GinPath() != c.gin || r.OpenAPIPath() != c.openapi {
+			t.Fatalf("%s: got %s, %s, %s", c.key, r.RestfulPath(), r.GinPath(), r.OpenAPIPath())
+		}
+		if r.Method != "/"+service+"/"+strings.Split(r.Method, "/")[2] || r.Service != service {
+			t.Fatalf("%s: unexpected method %s", c.key, r.Method)
+		}
+	}
+}
+
+func do(t *testing.T, g *gateway.Gateway, r *gateway.Route, req *http.Request, params map[string]string) map[string]any {
+	data, err := g.Handle(req, r, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := map[string]any{}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatalf("%s: %s", err, data)
+	}
+	return resp
+}
+
+func TestHandle(t *testing.T) {
+	g, routes := load(t)
+
+	// 路径参数、查询参数以及请求头
+	req := httptest.NewRequest(http.MethodGet, "/v1/books/b1?fullView=true", nil)
+	req.Header.Set("Authorization", "Bearer abc")
+	resp := do(t, g, routes["GET /v1/books/{name}"], req, map[string]string{"name": "b1"})
+	if resp["name"] != "b1" || resp["title"] != "Bearer abc" || resp["pages"] != "100" {
+		t.Fatalf("unexpected response %v", resp)
+	}
+
+	// 请求体绑定到字段
+	req = httptest.NewRequest(http.MethodPost, "/v1/shelves/s1/books", strings.NewReader(`{"title":"go","kind":"NOVEL","tags":["a"]}`))
+	resp = do(t, g, routes["POST /v1/shelves/{parent}/books"], req, map[string]string{"parent": "s1"})
+	if resp["name"] != "s1/go" || resp["kind"] != "NOVEL" {
+		t.Fatalf("unexpected response %v", resp)
+	}
+
+	// 嵌套字段的路径参数覆盖请求体中的值
+	req = httptest.NewRequest(http.MethodPatch, "/v1/books/b2", strings.NewReader(`{"name":"other","title":"new"}`))
+	resp = do(t, g, routes["PATCH /v1/books/{book.name}"], req, map[string]string{"book_name": "b2"})
+	if resp["name"] != "b2" || resp["title"] != "new" {
+		t.Fatalf("unexpected response %v", resp)
+	}
+
+	// response_body 以及重复的查询参数
+	req = httptest.NewRequest(http.MethodGet, "/v1/shelves/s1/books?page_size=2&tags=a&tags=b", nil)
+	data, err := g.Handle(req, routes["GET /v1/shelves/{parent}/books"], map[string]string{"parent": "s1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var books []map[string]any
+	if err := json.Unmarshal(data, &books); err != nil {
+		t.Fatalf("%s: %s", err, data)
+	}
+	if len(books) != 2 || books[1]["name"] != "s1" || len(books[1]["tags"].([]any)) != 2 {
+		t.Fatalf("unexpected response %s", data)
+	}
+}
+
+func TestHandleError(t *testing.T) {
+	g, routes := load(t)
+	r := routes["GET /v1/books/{name}"]
+
+	// GRPC 状态码转换为 HTTP 状态码
+	_, err := g.Handle(httptest.NewRequest(http.MethodGet, "/v1/books/missing", nil), r, map[string]string{"name": "missing"})
+	e, ok := err.(*exception.ApiException)
+	if !ok || e.GetHttpCode() != http.StatusNotFound || e.Message != "book missing not found" {
+		t.Fatalf("want not found exception, got %#v", err)
+	}
+
+	// Trailer 中的业务异常保持不变
+	_, err = g.Handle(httptest.NewRequest(http.MethodGet, "/v1/books/biz", nil), r, map[string]string{"name": "biz"})
+	if !exception.IsConflictError(err) {
+		t.Fatalf("want conflict exception, got %#v", err)
+	}
+
+	// 参数错误
+	_, err = g.Handle(httptest.NewRequest(http.MethodGet, "/v1/books/b1?full_view=x", nil), r, map[string]string{"name": "b1"})
+	if e, ok := err.(*exception.ApiException); !ok || e.GetHttpCode() != http.StatusBadRequest {
+		t.Fatalf("want bad request exception, got %#v", err)
+	}
+
+	// 请求体只能解析到绑定的字段, 以及请求体大小限制
+	create := routes["POST /v1/shelves/{parent}/books"]
+	for _, body := range []string{`{"title":"go"},"parent":"s2"`, `{"title":"` + strings.Repeat("a", gateway.DEFAULT_MAX_BODY_BYTES) + `"}`} {
+		req := httptest.NewRequest(http.MethodPost, "/v1/shelves/s1/books", strings.NewReader(body))
+		_, err = g.Handle(req, create, map[string]string{"parent": "s1"})
+		if e, ok := err.(*exception.ApiException); !ok || e.GetHttpCode() != http.StatusBadRequest {
+			t.Fatalf("want bad request exception, got %#v", err)
+		}
+	}
+}
+
+func TestEnrichSwagger(t *testing.T) {
+	g, _ := load(t)
+	swo := &spec.Swagger{}
+	g.EnrichSwagger(swo)
+
+	item, ok := swo.Paths.Paths["/v1/shelves/{parent}/books"]
+	if !ok || item.Get == nil || item.Post == nil {
+		t.Fatalf("unexpected paths %v", swo.Paths.Paths)
+	}
+	if item.Get.Responses.StatusCodeResponses[http.StatusOK].Schema.Type[0] != "array" {
+		t.Fatal("response_body should be documented as array")
+	}
+	var query []string
+	for _, p := range item.Get.Parameters {
+		if p.In == "query" {
+			query = append(query, p.Name)
+		}
+	}
+	if strings.Join(query, ",") != "pageSize,tags" {
+		t.Fatalf("unexpected query params %v", query)
+	}
+	book, ok := swo.Definitions["gwtest.v1.Book"]
+	if !ok || book.Properties["pages"].Type[0] != "string" || len(book.Properties["kind"].Enum) != 2 {
+		t.Fatalf("unexpected book definition %#v", book)
+	}
+}
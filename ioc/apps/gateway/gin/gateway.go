@@ -0,0 +1,72 @@
+package gin
+
+import (
+	stdhttp "net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/infraboard/mcube/v2/http/gin/response"
+	"github.com/infraboard/mcube/v2/ioc"
+	"github.com/infraboard/mcube/v2/ioc/apps/gateway"
+	ioc_gin "github.com/infraboard/mcube/v2/ioc/config/gin"
+	ioc_grpc "github.com/infraboard/mcube/v2/ioc/config/grpc"
+	"github.com/infraboard/mcube/v2/ioc/config/log"
+	"github.com/rs/zerolog"
+)
+
+func init() {
+	ioc.Api().Registry(&GrpcGateway{
+		Gateway: gateway.DefaultGateway(),
+	})
+}
+
+// 等待所有的GRPC服务都注册后, 根据 google.api.http 注解生成 gin 路由
+type GrpcGateway struct {
+	ioc.ObjectImpl
+	log *zerolog.Logger
+
+	*gateway.Gateway
+}
+
+func (h *GrpcGateway) Name() string {
+	return gateway.AppName
+}
+
+func (i *GrpcGateway) Priority() int {
+	return -100
+}
+
+func (h *GrpcGateway) Init() error {
+	h.log = log.Sub(gateway.AppName)
+	h.Registry()
+	return nil
+}
+
+func (h *GrpcGateway) Registry() {
+	routes, err := h.Load(ioc_grpc.Get().Server())
+	if err != nil {
+		h.log.Warn().Msgf("skip grpc methods, %s", err)
+	}
+
+	r := ioc_gin.RootRouter()
+	for _, route := range routes {
+		r.Handle(route.HttpMethod, route.GinPath(), h.handler(route))
+		h.log.Info().Msgf("%s", route)
+	}
+}
+
+func (h *GrpcGateway) handler(route *gateway.Route) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		params := make(map[string]string, len(c.Params))
+		for _, p := range c.Params {
+			// 通配参数的值以 / 开头
+			params[p.Key] = strings.TrimPrefix(p.Value, "/")
+		}
+		data, err := h.Handle(c.Request, route, params)
+		if err != nil {
+			response.Failed(c, err)
+			return
+		}
+		c.Data(stdhttp.StatusOK, "application/json", data)
+	}
+}
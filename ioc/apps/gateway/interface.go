@@ -0,0 +1,13 @@
+package gateway
+
+const (
+	AppName = "grpc_gateway"
+	// 默认的请求体最大字节数, 与 GRPC 服务默认接收的最大消息一致
+	DEFAULT_MAX_BODY_BYTES = 4 << 20
+)
+
+func DefaultGateway() *Gateway {
+	return &Gateway{
+		MaxBodyBytes: DEFAULT_MAX_BODY_BYTES,
+	}
+}
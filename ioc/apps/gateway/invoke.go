@@ -0,0 +1,169 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/infraboard/mcube/v2/exception"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// 不转发给 GRPC 服务的请求头
+var skipHeaders = map[string]bool{
+	"Connection":        true,
+	"Content-Length":    true,
+	"Content-Type":      true,
+	"Host":              true,
+	"Keep-Alive":        true,
+	"Te":                true,
+	"Trailer":           true,
+	"Transfer-Encoding": true,
+	"Upgrade":           true,
+	"Accept-Encoding":   true,
+}
+
+// Invoke 在进程内调用 GRPC 方法, 请求通过 grpc.Server.ServeHTTP 处理, 不经过网络
+// 服务端的拦截器以及 stats handler 照常执行, HTTP 请求头作为 GRPC metadata,
+// 客户端地址以及 TLS 连接信息作为 GRPC peer 信息
+func Invoke(ctx context.Context, svr http.Handler, origin *http.Request, method string, in, out proto.Message) error {
+	payload, err := proto.Marshal(in)
+	if err != nil {
+		return err
+	}
+	frame := make([]byte, 5+len(payload))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(payload)))
+	copy(frame[5:], payload)
+
+	header := http.Header{}
+	for k, v := range origin.Header {
+		if !skipHeaders[k] {
+			header[k] = v
+		}
+	}
+	header.Set("Content-Type", "application/grpc")
+	header.Set("Te", "trailers")
+
+	req := (&http.Request{
+		Method:        http.MethodPost,
+		URL:           &url.URL{Path: method},
+		RequestURI:    method,
+		Proto:         "HTTP/2.0",
+		ProtoMajor:    2,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(frame)),
+		ContentLength: int64(len(frame)),
+		Host:          origin.Host,
+		RemoteAddr:    origin.RemoteAddr,
+		TLS:           origin.TLS,
+	}).WithContext(ctx)
+
+	w := &responseRecorder{header: http.Header{}}
+	svr.ServeHTTP(w, req)
+
+	if err := w.err(); err != nil {
+		return err
+	}
+	return w.decode(out)
+}
+
+// responseRecorder 记录 GRPC 的响应, 头部和 Trailer 都写入 header
+type responseRecorder struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (w *responseRecorder) Header() http.Header {
+	return w.header
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *responseRecorder) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *responseRecorder) Flush() {}
+
+// trailer 读取响应头或者 Trailer 中的值
+func (w *responseRecorder) trailer(key string) string {
+	if v := w.header.Get(key); v != "" {
+		return v
+	}
+	for k, v := range w.header {
+		if len(v) > 0 && strings.EqualFold(strings.TrimPrefix(k, http.TrailerPrefix), key) {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// err 转换 GRPC 的状态, 服务通过 Trailer 返回的业务异常(err_json)保持不变
+func (w *responseRecorder) err() error {
+	if v := w.trailer(exception.TRAILER_ERROR_JSON_KEY); v != "" {
+		return exception.NewApiExceptionFromString(v)
+	}
+
+	s := w.trailer("Grpc-Status")
+	if s == "" {
+		if w.code != 0 && w.code != http.StatusOK {
+			return status.Errorf(codes.Internal, "grpc handler http status %d: %s", w.code, w.body.String())
+		}
+		return status.Error(codes.Internal, "grpc handler returns no status")
+	}
+	code, err := strconv.Atoi(s)
+	if err != nil {
+		return status.Errorf(codes.Internal, "invalid grpc status %s", s)
+	}
+	if codes.Code(code) == codes.OK {
+		return nil
+	}
+
+	if v := w.trailer("Grpc-Status-Details-Bin"); v != "" {
+		if data, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(v, "=")); err == nil {
+			st := &spb.Status{}
+			if proto.Unmarshal(data, st) == nil {
+				return status.ErrorProto(st)
+			}
+		}
+	}
+	msg, err := url.PathUnescape(w.trailer("Grpc-Message"))
+	if err != nil {
+		msg = w.trailer("Grpc-Message")
+	}
+	return status.Error(codes.Code(code), msg)
+}
+
+// decode 解析响应体中的消息, 格式为 1 字节压缩标识 + 4 字节长度 + 消息
+func (w *responseRecorder) decode(out proto.Message) error {
+	data := w.body.Bytes()
+	if len(data) < 5 {
+		return status.Error(codes.Internal, "grpc handler returns no message")
+	}
+	if data[0] != 0 {
+		return status.Error(codes.Internal, "compressed grpc message not supported")
+	}
+	n := binary.BigEndian.Uint32(data[1:5])
+	if uint32(len(data)-5) < n {
+		return status.Error(codes.Internal, fmt.Sprintf("grpc message truncated, want %d bytes, got %d", n, len(data)-5))
+	}
+	return proto.Unmarshal(data[5:5+n], out)
+}
@@ -0,0 +1,226 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// EnrichSwagger 根据 proto 描述补充路由的接口文档, 已存在的同名接口会被覆盖
+func (g *Gateway) EnrichSwagger(swo *spec.Swagger) {
+	if len(g.routes) == 0 {
+		return
+	}
+	if swo.Paths == nil {
+		swo.Paths = &spec.Paths{}
+	}
+	if swo.Paths.Paths == nil {
+		swo.Paths.Paths = map[string]spec.PathItem{}
+	}
+	if swo.Definitions == nil {
+		swo.Definitions = spec.Definitions{}
+	}
+
+	ids := map[string]int{}
+	for _, r := range g.routes {
+		op := g.operation(r, swo.Definitions)
+		// additional_bindings 的 operationId 添加序号, 保持唯一
+		if n := ids[op.ID]; n > 0 {
+			ids[op.ID]++
+			op.ID = fmt.Sprintf("%s_%d", op.ID, n)
+		} else {
+			ids[op.ID] = 1
+		}
+		path := r.OpenAPIPath()
+		item := swo.Paths.Paths[path]
+		switch r.HttpMethod {
+		case http.MethodGet:
+			item.Get = op
+		case http.MethodPut:
+			item.Put = op
+		case http.MethodPost:
+			item.Post = op
+		case http.MethodDelete:
+			item.Delete = op
+		case http.MethodPatch:
+			item.Patch = op
+		case http.MethodHead:
+			item.Head = op
+		case http.MethodOptions:
+			item.Options = op
+		default:
+			continue
+		}
+		swo.Paths.Paths[path] = item
+	}
+}
+
+func (g *Gateway) operation(r *Route, defs spec.Definitions) *spec.Operation {
+	input := r.input.Descriptor()
+	op := spec.NewOperation(strings.ReplaceAll(strings.TrimPrefix(r.Method, "/"), "/", "_")).
+		WithTags(r.Service).
+		WithSummary(r.Method).
+		WithConsumes("application/json").
+		WithProduces("application/json")
+
+	// 路径参数
+	bound := map[string]bool{}
+	for _, s := range r.segments {
+		if s.field == "" {
+			continue
+		}
+		bound[s.field] = true
+		fds, _ := fieldPath(input, s.field)
+		p := spec.PathParam(s.param()).WithDescription(s.field)
+		p.SimpleSchema, p.Enum = simpleSchema(fds[len(fds)-1])
+		op.AddParam(p)
+	}
+
+	// 请求体
+	switch r.Body {
+	case "":
+	case "*":
+		op.AddParam(spec.BodyParam("body", g.schema(input, defs)).AsRequired())
+	default:
+		fd := findField(input, r.Body)
+		bound[string(fd.Name())] = true
+		op.AddParam(spec.BodyParam(string(fd.Name()), g.fieldSchema(fd, defs)).AsRequired())
+	}
+
+	// 查询参数, 只列出未绑定的顶层非消息字段
+	if r.Body != "*" {
+		fields := input.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			fd := fields.Get(i)
+			if bound[string(fd.Name())] || fd.IsMap() || fd.Message() != nil {
+				continue
+			}
+			p := spec.QueryParam(g.fieldName(fd))
+			if fd.IsList() {
+				items := &spec.Items{}
+				items.SimpleSchema, items.Enum = simpleSchema(fd)
+				p.Type = "array"
+				p.Items = items
+				p.CollectionFormat = "multi"
+			} else {
+				p.SimpleSchema, p.Enum = simpleSchema(fd)
+			}
+			op.AddParam(p)
+		}
+	}
+
+	// 响应
+	output := g.schema(r.output.Descriptor(), defs)
+	if r.ResponseBody != "" {
+		output = g.fieldSchema(findField(r.output.Descriptor(), r.ResponseBody), defs)
+	}
+	op.RespondsWith(http.StatusOK, spec.NewResponse().WithDescription("OK").WithSchema(output))
+	return op
+}
+
+func (g *Gateway) fieldName(fd protoreflect.FieldDescriptor) string {
+	if g.UseProtoNames {
+		return string(fd.Name())
+	}
+	return fd.JSONName()
+}
+
+// schema 消息的文档定义, 普通消息添加到 definitions 中并返回引用
+func (g *Gateway) schema(md protoreflect.MessageDescriptor, defs spec.Definitions) *spec.Schema {
+	if s := wellKnownSchema(md); s != nil {
+		return s
+	}
+
+	name := string(md.FullName())
+	ref := spec.RefSchema("#/definitions/" + name)
+	if _, ok := defs[name]; ok {
+		return ref
+	}
+	// 先占位, 避免循环引用的消息无限递归
+	defs[name] = spec.Schema{}
+	s := spec.Schema{}
+	s.Typed("object", "")
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		s.SetProperty(g.fieldName(fd), *g.fieldSchema(fd, defs))
+	}
+	defs[name] = s
+	return ref
+}
+
+func (g *Gateway) fieldSchema(fd protoreflect.FieldDescriptor, defs spec.Definitions) *spec.Schema {
+	var item *spec.Schema
+	switch {
+	case fd.IsMap():
+		return spec.MapProperty(g.fieldSchema(fd.MapValue(), defs))
+	case fd.Message() != nil:
+		item = g.schema(fd.Message(), defs)
+	default:
+		ss, enum := simpleSchema(fd)
+		item = &spec.Schema{}
+		item.Typed(ss.Type, ss.Format)
+		item.Enum = enum
+	}
+	if fd.IsList() {
+		return spec.ArrayProperty(item)
+	}
+	return item
+}
+
+// simpleSchema 标量字段的类型以及枚举值, 与 protojson 的编码一致, 64 位整数编码为字符串
+func simpleSchema(fd protoreflect.FieldDescriptor) (spec.SimpleSchema, []any) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return spec.SimpleSchema{Type: "boolean"}, nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return spec.SimpleSchema{Type: "integer", Format: "int32"}, nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return spec.SimpleSchema{Type: "integer", Format: "int64"}, nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return spec.SimpleSchema{Type: "string", Format: "int64"}, nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return spec.SimpleSchema{Type: "string", Format: "uint64"}, nil
+	case protoreflect.FloatKind:
+		return spec.SimpleSchema{Type: "number", Format: "float"}, nil
+	case protoreflect.DoubleKind:
+		return spec.SimpleSchema{Type: "number", Format: "double"}, nil
+	case protoreflect.BytesKind:
+		return spec.SimpleSchema{Type: "string", Format: "byte"}, nil
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		enum := make([]any, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			enum = append(enum, string(values.Get(i).Name()))
+		}
+		return spec.SimpleSchema{Type: "string"}, enum
+	}
+	return spec.SimpleSchema{Type: "string"}, nil
+}
+
+// wellKnownSchema protobuf 内置类型在 protojson 中的编码
+func wellKnownSchema(md protoreflect.MessageDescriptor) *spec.Schema {
+	switch md.FullName() {
+	case "google.protobuf.Timestamp":
+		return spec.DateTimeProperty()
+	case "google.protobuf.Duration", "google.protobuf.FieldMask",
+		"google.protobuf.StringValue", "google.protobuf.BytesValue",
+		"google.protobuf.Int64Value", "google.protobuf.UInt64Value":
+		return spec.StringProperty()
+	case "google.protobuf.Int32Value", "google.protobuf.UInt32Value":
+		return spec.Int32Property()
+	case "google.protobuf.FloatValue", "google.protobuf.DoubleValue":
+		return spec.Float64Property()
+	case "google.protobuf.BoolValue":
+		return spec.BoolProperty()
+	case "google.protobuf.Struct", "google.protobuf.Any", "google.protobuf.Empty":
+		s := &spec.Schema{}
+		return s.Typed("object", "")
+	case "google.protobuf.Value", "google.protobuf.ListValue":
+		return &spec.Schema{}
+	}
+	return nil
+}
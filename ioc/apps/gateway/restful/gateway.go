@@ -0,0 +1,77 @@
+package restful
+
+import (
+	stdhttp "net/http"
+
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	"github.com/emicklei/go-restful/v3"
+	"github.com/infraboard/mcube/v2/http/restful/response"
+	"github.com/infraboard/mcube/v2/ioc"
+	"github.com/infraboard/mcube/v2/ioc/apps/gateway"
+	"github.com/infraboard/mcube/v2/ioc/config/gorestful"
+	ioc_grpc "github.com/infraboard/mcube/v2/ioc/config/grpc"
+	"github.com/infraboard/mcube/v2/ioc/config/log"
+	"github.com/rs/zerolog"
+)
+
+func init() {
+	ioc.Api().Registry(&GrpcGateway{
+		Gateway: gateway.DefaultGateway(),
+	})
+}
+
+// 等待所有的GRPC服务都注册后, 根据 google.api.http 注解生成 go-restful 路由
+type GrpcGateway struct {
+	ioc.ObjectImpl
+	log *zerolog.Logger
+
+	*gateway.Gateway
+}
+
+func (h *GrpcGateway) Name() string {
+	return gateway.AppName
+}
+
+func (i *GrpcGateway) Priority() int {
+	return -100
+}
+
+func (h *GrpcGateway) Init() error {
+	h.log = log.Sub(gateway.AppName)
+	h.Registry()
+	return nil
+}
+
+func (h *GrpcGateway) Registry() {
+	routes, err := h.Load(ioc_grpc.Get().Server())
+	if err != nil {
+		h.log.Warn().Msgf("skip grpc methods, %s", err)
+	}
+	if len(routes) == 0 {
+		return
+	}
+
+	ws := new(restful.WebService)
+	ws.Consumes(restful.MIME_JSON).Produces(restful.MIME_JSON)
+	for _, r := range routes {
+		ws.Route(ws.Method(r.HttpMethod).Path(r.RestfulPath()).To(h.handler(r)).
+			Doc(r.Method).
+			Metadata(restfulspec.KeyOpenAPITags, []string{r.Service}),
+		)
+		h.log.Info().Msgf("%s", r)
+	}
+	gorestful.RootRouter().Add(ws)
+}
+
+func (h *GrpcGateway) handler(route *gateway.Route) restful.RouteFunction {
+	return func(r *restful.Request, w *restful.Response) {
+		data, err := h.Handle(r.Request, route, r.PathParameters())
+		if err != nil {
+			response.Failed(w, err)
+			return
+		}
+		w.Header().Set(restful.HEADER_ContentType, restful.MIME_JSON)
+		w.WriteHeader(stdhttp.StatusOK)
+		w.Write(data)
+	}
+}
@@ -0,0 +1,330 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// Route 根据 google.api.http 注解生成的 REST 路由
+type Route struct {
+	// GRPC 服务全名, 比如 library.v1.BookService
+	Service string
+	// GRPC 方法全名, 格式为 /package.Service/Method
+	Method string
+	// HTTP 方法
+	HttpMethod string
+	// 注解中定义的路径模板, 比如 /v1/{name=*}/books
+	Path string
+	// 请求体对应的字段, * 表示整个请求
+	Body string
+	// 响应体对应的字段, 为空表示整个响应
+	ResponseBody string
+	// 请求体的最大字节数, 小于等于 0 时使用 DEFAULT_MAX_BODY_BYTES
+	MaxBodyBytes int64
+
+	segments []segment
+	desc     protoreflect.MethodDescriptor
+	input    protoreflect.MessageType
+	output   protoreflect.MessageType
+}
+
+// segment 路径模板中的一段
+type segment struct {
+	literal string
+	// 变量绑定的字段路径, 比如 book.id
+	field string
+	// 变量匹配剩余的所有路径, 即 {name=**}
+	wildcard bool
+}
+
+// param 路由参数名称, 字段路径中的 . 替换为 _
+func (s segment) param() string {
+	return strings.ReplaceAll(s.field, ".", "_")
+}
+
+// RestfulPath go-restful 的路由路径
+func (r *Route) RestfulPath() string {
+	return r.render(func(s segment) string {
+		if s.wildcard {
+			return "{" + s.param() + ":*}"
+		}
+		return "{" + s.param() + "}"
+	})
+}
+
+// GinPath gin 的路由路径
+func (r *Route) GinPath() string {
+	return r.render(func(s segment) string {
+		if s.wildcard {
+			return "*" + s.param()
+		}
+		return ":" + s.param()
+	})
+}
+
+// OpenAPIPath 接口文档中的路径
+func (r *Route) OpenAPIPath() string {
+	return r.render(func(s segment) string {
+		return "{" + s.param() + "}"
+	})
+}
+
+func (r *Route) render(variable func(segment) string) string {
+	var b strings.Builder
+	for _, s := range r.segments {
+		b.WriteString("/")
+		if s.field == "" {
+			b.WriteString(s.literal)
+		} else {
+			b.WriteString(variable(s))
+		}
+	}
+	if b.Len() == 0 {
+		return "/"
+	}
+	return b.String()
+}
+
+// PathParams 路径参数名称与绑定的字段路径
+func (r *Route) PathParams() map[string]string {
+	params := map[string]string{}
+	for _, s := range r.segments {
+		if s.field != "" {
+			params[s.param()] = s.field
+		}
+	}
+	return params
+}
+
+// Input 请求的消息类型
+func (r *Route) Input() protoreflect.MessageType {
+	return r.input
+}
+
+// Output 响应的消息类型
+func (r *Route) Output() protoreflect.MessageType {
+	return r.output
+}
+
+func (r *Route) String() string {
+	return fmt.Sprintf("%s %s --> %s", r.HttpMethod, r.Path, r.Method)
+}
+
+// LoadRoutes 读取 GRPC 服务中所有方法的 google.api.http 注解生成路由
+// 没有注解的方法直接跳过, 流式方法以及不支持的路径模板跳过并返回错误
+func LoadRoutes(svr *grpc.Server) ([]*Route, error) {
+	infos := svr.GetServiceInfo()
+	names := make([]string, 0, len(infos))
+	for name := range infos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var (
+		routes []*Route
+		errs   []error
+	)
+	for _, name := range names {
+		d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			continue
+		}
+		sd, ok := d.(protoreflect.ServiceDescriptor)
+		if !ok {
+			continue
+		}
+		for _, mi := range infos[name].Methods {
+			md := sd.Methods().ByName(protoreflect.Name(mi.Name))
+			if md == nil {
+				continue
+			}
+			rule, ok := proto.GetExtension(md.Options(), annotations.E_Http).(*annotations.HttpRule)
+			if !ok || rule == nil {
+				continue
+			}
+			method := fmt.Sprintf("/%s/%s", name, mi.Name)
+			if mi.IsClientStream || mi.IsServerStream {
+				errs = append(errs, fmt.Errorf("%s: streaming method not supported", method))
+				continue
+			}
+			input, err := protoregistry.GlobalTypes.FindMessageByName(md.Input().FullName())
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", method, err))
+				continue
+			}
+			output, err := protoregistry.GlobalTypes.FindMessageByName(md.Output().FullName())
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", method, err))
+				continue
+			}
+
+			for _, b := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+				r, err := newRoute(b)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", method, err))
+					continue
+				}
+				r.Service, r.Method = name, method
+				r.desc, r.input, r.output = md, input, output
+				if err := r.validate(); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", method, err))
+					continue
+				}
+				routes = append(routes, r)
+			}
+		}
+	}
+	return routes, errors.Join(errs...)
+}
+
+func newRoute(rule *annotations.HttpRule) (*Route, error) {
+	r := &Route{Body: rule.GetBody(), ResponseBody: rule.GetResponseBody()}
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		r.HttpMethod, r.Path = http.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		r.HttpMethod, r.Path = http.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		r.HttpMethod, r.Path = http.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		r.HttpMethod, r.Path = http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		r.HttpMethod, r.Path = http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		r.HttpMethod, r.Path = strings.ToUpper(p.Custom.GetKind()), p.Custom.GetPath()
+	default:
+		return nil, fmt.Errorf("http rule has no pattern")
+	}
+
+	segments, err := parseTemplate(r.Path)
+	if err != nil {
+		return nil, err
+	}
+	r.segments = segments
+	return r, nil
+}
+
+// parseTemplate 解析路径模板, 支持字面量、{field}、{field=*} 以及结尾的 {field=**}
+// 自定义动词(:verb)以及带字面量的变量(比如 {name=shelves/*})不能映射为 gin/go-restful 的路由, 返回错误
+func parseTemplate(tpl string) ([]segment, error) {
+	if !strings.HasPrefix(tpl, "/") {
+		return nil, fmt.Errorf("path template %s must start with /", tpl)
+	}
+
+	var (
+		segments []segment
+		rest     = tpl[1:]
+	)
+	for rest != "" {
+		var part string
+		if strings.HasPrefix(rest, "{") {
+			end := strings.Index(rest, "}")
+			if end < 0 {
+				return nil, fmt.Errorf("path template %s: unclosed variable", tpl)
+			}
+			part, rest = rest[:end+1], rest[end+1:]
+		} else {
+			end := strings.Index(rest, "/")
+			if end < 0 {
+				end = len(rest)
+			}
+			part, rest = rest[:end], rest[end:]
+		}
+		if rest != "" && !strings.HasPrefix(rest, "/") {
+			return nil, fmt.Errorf("path template %s: custom verb not supported", tpl)
+		}
+		rest = strings.TrimPrefix(rest, "/")
+
+		s, err := parseSegment(part)
+		if err != nil {
+			return nil, fmt.Errorf("path template %s: %w", tpl, err)
+		}
+		if len(segments) > 0 && segments[len(segments)-1].wildcard {
+			return nil, fmt.Errorf("path template %s: ** must be the last segment", tpl)
+		}
+		segments = append(segments, s)
+	}
+	return segments, nil
+}
+
+func parseSegment(part string) (segment, error) {
+	switch {
+	case part == "":
+		return segment{}, fmt.Errorf("empty segment")
+	case strings.Contains(part, ":"):
+		return segment{}, fmt.Errorf("custom verb not supported")
+	case part == "*" || part == "**":
+		return segment{}, fmt.Errorf("anonymous wildcard not supported")
+	case !strings.HasPrefix(part, "{"):
+		return segment{literal: part}, nil
+	}
+
+	field, pattern, _ := strings.Cut(strings.Trim(part, "{}"), "=")
+	if field == "" {
+		return segment{}, fmt.Errorf("variable %s has no field", part)
+	}
+	switch pattern {
+	case "", "*":
+		return segment{field: field}, nil
+	case "**":
+		return segment{field: field, wildcard: true}, nil
+	default:
+		return segment{}, fmt.Errorf("variable pattern %s not supported", part)
+	}
+}
+
+// validate 检查路径参数以及请求体、响应体绑定的字段是否存在
+func (r *Route) validate() error {
+	for _, s := range r.segments {
+		if s.field == "" {
+			continue
+		}
+		if _, err := fieldPath(r.input.Descriptor(), s.field); err != nil {
+			return err
+		}
+	}
+	if r.Body != "" && r.Body != "*" && findField(r.input.Descriptor(), r.Body) == nil {
+		return fmt.Errorf("body field %s not found in %s", r.Body, r.input.Descriptor().FullName())
+	}
+	if r.ResponseBody != "" && findField(r.output.Descriptor(), r.ResponseBody) == nil {
+		return fmt.Errorf("response body field %s not found in %s", r.ResponseBody, r.output.Descriptor().FullName())
+	}
+	return nil
+}
+
+// findField 按 proto 字段名或者 json 名称查找字段
+func findField(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	if fd := md.Fields().ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+	return md.Fields().ByJSONName(name)
+}
+
+// fieldPath 查找字段路径, 比如 book.id, 除最后一个字段外都需要是非重复的消息字段
+func fieldPath(md protoreflect.MessageDescriptor, path string) ([]protoreflect.FieldDescriptor, error) {
+	var fds []protoreflect.FieldDescriptor
+	for i, name := range strings.Split(path, ".") {
+		if i > 0 {
+			last := fds[i-1]
+			if last.Message() == nil || last.IsList() || last.IsMap() {
+				return nil, fmt.Errorf("field %s is not a message", last.FullName())
+			}
+			md = last.Message()
+		}
+		fd := findField(md, name)
+		if fd == nil {
+			return nil, fmt.Errorf("field %s not found in %s", name, md.FullName())
+		}
+		fds = append(fds, fd)
+	}
+	return fds, nil
+}
@@ -0,0 +1,248 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// NewRequest 根据 HTTP 请求构造 GRPC 请求: 先解析请求体, 再填充路径参数, 最后填充查询参数
+// 请求体为整个请求(*)时忽略查询参数, 未知的查询参数直接忽略
+func (r *Route) NewRequest(req *http.Request, params map[string]string) (proto.Message, error) {
+	msg := r.input.New()
+
+	if r.Body != "" && req.Body != nil {
+		limit := r.MaxBodyBytes
+		if limit <= 0 {
+			limit = DEFAULT_MAX_BODY_BYTES
+		}
+		data, err := io.ReadAll(http.MaxBytesReader(nil, req.Body, limit))
+		if err != nil {
+			return nil, fmt.Errorf("read body: %w", err)
+		}
+		if len(data) > 0 {
+			if err := r.decodeBody(msg, data); err != nil {
+				return nil, fmt.Errorf("decode body: %w", err)
+			}
+		}
+	}
+
+	bound := map[string]bool{}
+	for name, field := range r.PathParams() {
+		bound[field] = true
+		if err := setField(msg, field, []string{params[name]}); err != nil {
+			return nil, fmt.Errorf("path param %s: %w", name, err)
+		}
+	}
+
+	if r.Body == "*" {
+		return msg.Interface(), nil
+	}
+	for key, values := range req.URL.Query() {
+		if bound[key] || (r.Body != "" && strings.Split(key, ".")[0] == r.Body) {
+			continue
+		}
+		if _, err := fieldPath(r.input.Descriptor(), key); err != nil {
+			continue
+		}
+		if err := setField(msg, key, values); err != nil {
+			return nil, fmt.Errorf("query param %s: %w", key, err)
+		}
+	}
+	return msg.Interface(), nil
+}
+
+// decodeBody 解析请求体, body 为字段时请求体只解析到该字段
+func (r *Route) decodeBody(msg protoreflect.Message, data []byte) error {
+	opts := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if r.Body == "*" {
+		return opts.Unmarshal(data, msg.Interface())
+	}
+
+	fd := findField(r.input.Descriptor(), r.Body)
+	if string(bytes.TrimSpace(data)) == "null" {
+		return nil
+	}
+	switch {
+	case fd.IsMap():
+		return fmt.Errorf("map field %s not supported", fd.FullName())
+	case fd.IsList():
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		list := msg.Mutable(fd).List()
+		for _, item := range items {
+			v, err := decodeValue(fd, item, list.NewElement, opts)
+			if err != nil {
+				return err
+			}
+			list.Append(v)
+		}
+	case fd.Message() != nil:
+		return opts.Unmarshal(data, msg.Mutable(fd).Message().Interface())
+	default:
+		v, err := decodeValue(fd, data, func() protoreflect.Value { return msg.NewField(fd) }, opts)
+		if err != nil {
+			return err
+		}
+		msg.Set(fd, v)
+	}
+	return nil
+}
+
+// decodeValue 解析 JSON 格式的字段值, 字符串按查询参数的规则转换, 数字与布尔值使用原始文本
+func decodeValue(fd protoreflect.FieldDescriptor, data json.RawMessage, newMessage func() protoreflect.Value, opts protojson.UnmarshalOptions) (protoreflect.Value, error) {
+	if fd.Message() != nil {
+		v := newMessage()
+		if err := opts.Unmarshal(data, v.Message().Interface()); err != nil {
+			return protoreflect.Value{}, err
+		}
+		return v, nil
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return protoreflect.Value{}, fmt.Errorf("empty value for %s", fd.FullName())
+	}
+	switch data[0] {
+	case '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return protoreflect.Value{}, err
+		}
+		return parseValue(fd, s, newMessage)
+	case '{', '[', 'n':
+		return protoreflect.Value{}, fmt.Errorf("invalid value %s for %s", data, fd.FullName())
+	}
+	if !json.Valid(data) {
+		return protoreflect.Value{}, fmt.Errorf("invalid value %s for %s", data, fd.FullName())
+	}
+	return parseValue(fd, string(data), newMessage)
+}
+
+// setField 按字段路径设置字段的值, 重复字段追加所有的值, 其他字段使用最后一个值
+func setField(msg protoreflect.Message, path string, values []string) error {
+	fds, err := fieldPath(msg.Descriptor(), path)
+	if err != nil {
+		return err
+	}
+	for _, fd := range fds[:len(fds)-1] {
+		msg = msg.Mutable(fd).Message()
+	}
+
+	fd := fds[len(fds)-1]
+	switch {
+	case fd.IsMap():
+		return fmt.Errorf("map field %s not supported", fd.FullName())
+	case fd.IsList():
+		list := msg.Mutable(fd).List()
+		for _, s := range values {
+			v, err := parseValue(fd, s, list.NewElement)
+			if err != nil {
+				return err
+			}
+			list.Append(v)
+		}
+	case len(values) > 0:
+		v, err := parseValue(fd, values[len(values)-1], func() protoreflect.Value { return msg.NewField(fd) })
+		if err != nil {
+			return err
+		}
+		msg.Set(fd, v)
+	}
+	return nil
+}
+
+// parseValue 把字符串转换为字段类型的值, 消息类型的字段(比如 Timestamp)按 JSON 字符串解析
+func parseValue(fd protoreflect.FieldDescriptor, s string, newMessage func() protoreflect.Value) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(s)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfInt32(int32(v)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(s, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(s, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(s, 10, 64)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(s, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(s, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.BytesKind:
+		v, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			v, err = base64.URLEncoding.DecodeString(s)
+		}
+		return protoreflect.ValueOfBytes(v), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(s)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		v, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid enum value %s for %s", s, fd.Enum().FullName())
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), nil
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		v := newMessage()
+		quoted, _ := json.Marshal(s)
+		if err := protojson.Unmarshal(quoted, v.Message().Interface()); err != nil {
+			return protoreflect.Value{}, err
+		}
+		return v, nil
+	}
+	return protoreflect.Value{}, fmt.Errorf("field %s kind %s not supported", fd.FullName(), fd.Kind())
+}
+
+// Marshal 把 GRPC 响应转换为 JSON, 定义了 response_body 时只返回该字段
+func (r *Route) Marshal(resp proto.Message, opts protojson.MarshalOptions) ([]byte, error) {
+	data, err := opts.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	if r.ResponseBody == "" {
+		return data, nil
+	}
+
+	fd := findField(r.output.Descriptor(), r.ResponseBody)
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	key := fd.JSONName()
+	if opts.UseProtoNames {
+		key = string(fd.Name())
+	}
+	if v, ok := fields[key]; ok {
+		return v, nil
+	}
+	// 未输出零值字段时返回对应类型的空值
+	switch {
+	case fd.IsList():
+		return []byte("[]"), nil
+	case fd.IsMap() || fd.Message() != nil:
+		return []byte("{}"), nil
+	}
+	return []byte("null"), nil
+}
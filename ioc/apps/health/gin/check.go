@@ -1,6 +1,8 @@
 package gin
 
 import (
+	stdhttp "net/http"
+
 	"github.com/gin-gonic/gin"
 	h_response "github.com/infraboard/mcube/v2/http/gin/response"
 	"github.com/infraboard/mcube/v2/ioc"
@@ -16,7 +18,8 @@ import (
 func init() {
 	ioc.Api().Registry(&HealthChecker{
 		HealthCheck: ioc_health.HealthCheck{
-			Path: ioc_health.DEFAUL_HEALTH_PATH,
+			Path:      ioc_health.DEFAUL_HEALTH_PATH,
+			ReadyPath: ioc_health.DEFAUL_READY_PATH,
 		},
 	})
 }
@@ -38,6 +41,7 @@ func (h *HealthChecker) Init() error {
 		h.Service = health.NewServer()
 	}
 	h.log = log.Sub("health_check")
+	ioc_health.SyncServingStatus(h.Service)
 	h.Registry()
 	return nil
 }
@@ -51,8 +55,12 @@ func (h *HealthChecker) Meta() ioc.ObjectMeta {
 func (h *HealthChecker) Registry() {
 	r := ioc_gin.ObjectRouter(h)
 	r.GET("/", h.HealthHandleFunc)
-
 	h.log.Info().Msgf("Get the Health using http://%s%s", http.Get().Addr(), h.Path)
+
+	if h.ReadyPath != "" {
+		ioc_gin.RootRouter().GET(h.ReadyPath, h.ReadyHandleFunc)
+		h.log.Info().Msgf("Get the Readiness using http://%s%s", http.Get().Addr(), h.ReadyPath)
+	}
 }
 
 func (h *HealthChecker) HealthHandleFunc(c *gin.Context) {
@@ -68,3 +76,13 @@ func (h *HealthChecker) HealthHandleFunc(c *gin.Context) {
 
 	h_response.Success(c, ioc_health.NewHealth(resp))
 }
+
+// ReadyHandleFunc 所有监听都已就绪时返回200, 否则返回503
+func (h *HealthChecker) ReadyHandleFunc(c *gin.Context) {
+	resp := ioc_health.NewReadiness()
+	if !resp.Ready {
+		c.JSON(stdhttp.StatusServiceUnavailable, resp)
+		return
+	}
+	c.JSON(stdhttp.StatusOK, resp)
+}
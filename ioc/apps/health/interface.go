@@ -1,6 +1,10 @@
 package health
 
 import (
+	"sync"
+
+	"github.com/infraboard/mcube/v2/ioc/server/lifecycle"
+	"google.golang.org/grpc/health"
 	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
 )
 
@@ -10,10 +14,13 @@ const (
 
 const (
 	DEFAUL_HEALTH_PATH = "/healthz"
+	DEFAUL_READY_PATH  = "/readyz"
 )
 
 type HealthCheck struct {
 	Path string `json:"path" yaml:"path" toml:"path" env:"HTTP_HEALTH_CHECK_PATH"`
+	// 就绪检查路径, 所有监听都已就绪时返回200, 否则返回503
+	ReadyPath string `json:"ready_path" yaml:"ready_path" toml:"ready_path" env:"HTTP_READY_CHECK_PATH"`
 }
 
 type HealthCheckResponse struct {
@@ -29,3 +36,45 @@ func NewHealth(hc *healthgrpc.HealthCheckResponse) *HealthCheckResponse {
 		Status: hc.Status.String(),
 	}
 }
+
+// ReadinessResponse 就绪检查结果
+type ReadinessResponse struct {
+	Ready bool            `json:"ready"`
+	Phase lifecycle.Phase `json:"phase"`
+}
+
+func NewReadiness() *ReadinessResponse {
+	return &ReadinessResponse{
+		Ready: lifecycle.IsReady(),
+		Phase: lifecycle.Current(),
+	}
+}
+
+// 已同步状态的 health.Server, 避免重复 Init 时重复订阅阶段变化
+var synced sync.Map
+
+// SyncServingStatus 服务进入 serving 阶段后 GRPC 健康状态为 SERVING, 其他阶段为 NOT_SERVING
+// 未通过 server 启动(阶段为空)时保持 health.Server 原有状态
+func SyncServingStatus(svc healthgrpc.HealthServer) {
+	hs, ok := svc.(*health.Server)
+	if !ok {
+		return
+	}
+	if _, loaded := synced.LoadOrStore(hs, struct{}{}); loaded {
+		return
+	}
+	lifecycle.OnPhaseChange(func(from, to lifecycle.Phase) {
+		setServingStatus(hs, to)
+	})
+	setServingStatus(hs, lifecycle.Current())
+}
+
+func setServingStatus(hs *health.Server, p lifecycle.Phase) {
+	switch p {
+	case lifecycle.PHASE_SERVING:
+		hs.SetServingStatus("", healthgrpc.HealthCheckResponse_SERVING)
+	case lifecycle.PHASE_CONFIGURING, lifecycle.PHASE_INITIALIZING,
+		lifecycle.PHASE_DRAINING, lifecycle.PHASE_STOPPED:
+		hs.SetServingStatus("", healthgrpc.HealthCheckResponse_NOT_SERVING)
+	}
+}
@@ -0,0 +1,46 @@
+package health_test
+
+import (
+	"context"
+	"testing"
+
+	ioc_health "github.com/infraboard/mcube/v2/ioc/apps/health"
+	"github.com/infraboard/mcube/v2/ioc/server/lifecycle"
+	"google.golang.org/grpc/health"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestSyncServingStatus(t *testing.T) {
+	lifecycle.SetPhase("")
+	t.Cleanup(func() { lifecycle.SetPhase("") })
+
+	hs := health.NewServer()
+	status := func() healthgrpc.HealthCheckResponse_ServingStatus {
+		resp, err := hs.Check(context.Background(), ioc_health.NewHealthCheckRequest())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Status
+	}
+
+	// 重复 Init 只订阅一次
+	ioc_health.SyncServingStatus(hs)
+	ioc_health.SyncServingStatus(hs)
+	// 未通过 server 启动时保持 SERVING
+	if s := status(); s != healthgrpc.HealthCheckResponse_SERVING {
+		t.Fatalf("want SERVING without lifecycle, got %s", s)
+	}
+
+	lifecycle.SetPhase(lifecycle.PHASE_CONFIGURING)
+	if s := status(); s != healthgrpc.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("want NOT_SERVING when configuring, got %s", s)
+	}
+	lifecycle.SetPhase(lifecycle.PHASE_SERVING)
+	if s := status(); s != healthgrpc.HealthCheckResponse_SERVING {
+		t.Fatalf("want SERVING when serving, got %s", s)
+	}
+	lifecycle.SetPhase(lifecycle.PHASE_DRAINING)
+	if s := status(); s != healthgrpc.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("want NOT_SERVING when draining, got %s", s)
+	}
+}
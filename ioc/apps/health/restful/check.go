@@ -1,6 +1,8 @@
 package restful
 
 import (
+	stdhttp "net/http"
+
 	restfulspec "github.com/emicklei/go-restful-openapi/v2"
 	"github.com/emicklei/go-restful/v3"
 	"github.com/infraboard/mcube/v2/http/restful/response"
@@ -17,7 +19,8 @@ import (
 func init() {
 	ioc.Api().Registry(&HealthChecker{
 		HealthCheck: ioc_health.HealthCheck{
-			Path: ioc_health.DEFAUL_HEALTH_PATH,
+			Path:      ioc_health.DEFAUL_HEALTH_PATH,
+			ReadyPath: ioc_health.DEFAUL_READY_PATH,
 		},
 	})
 }
@@ -40,6 +43,7 @@ func (h *HealthChecker) Init() error {
 	}
 
 	h.log = log.Sub("health_check")
+	ioc_health.SyncServingStatus(h.Service)
 	h.Registry()
 	return nil
 }
@@ -58,8 +62,19 @@ func (h *HealthChecker) Registry() {
 		Doc("查询服务当前状态").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
 		Returns(200, "OK", ioc_health.HealthCheckResponse{}))
-
 	h.log.Info().Msgf("Get the Health using http://%s%s", http.Get().Addr(), h.Path)
+
+	if h.ReadyPath != "" {
+		rws := new(restful.WebService)
+		rws.Path(h.ReadyPath).Produces(restful.MIME_JSON)
+		rws.Route(rws.GET("/").To(h.ReadyHandleFunc).
+			Doc("查询服务是否就绪").
+			Metadata(restfulspec.KeyOpenAPITags, tags).
+			Returns(200, "OK", ioc_health.ReadinessResponse{}).
+			Returns(503, "Not Ready", ioc_health.ReadinessResponse{}))
+		gorestful.RootRouter().Add(rws)
+		h.log.Info().Msgf("Get the Readiness using http://%s%s", http.Get().Addr(), h.ReadyPath)
+	}
 }
 
 func (h *HealthChecker) HealthHandleFunc(r *restful.Request, w *restful.Response) {
@@ -78,3 +93,15 @@ func (h *HealthChecker) HealthHandleFunc(r *restful.Request, w *restful.Response
 		h.log.Error().Msgf("send success response error, %s", err)
 	}
 }
+
+// ReadyHandleFunc 所有监听都已就绪时返回200, 否则返回503
+func (h *HealthChecker) ReadyHandleFunc(r *restful.Request, w *restful.Response) {
+	resp := ioc_health.NewReadiness()
+	status := stdhttp.StatusOK
+	if !resp.Ready {
+		status = stdhttp.StatusServiceUnavailable
+	}
+	if err := w.WriteHeaderAndJson(status, resp, restful.MIME_JSON); err != nil {
+		h.log.Error().Msgf("send readiness response error, %s", err)
+	}
+}
@@ -0,0 +1,108 @@
+package ioc_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/infraboard/mcube/v2/ioc"
+)
+
+// StrictStorage 严格注入测试使用的接口
+type StrictStorage interface {
+	Put(key string)
+}
+
+type StrictRedis struct {
+	ioc.ObjectImpl
+}
+
+func (s *StrictRedis) Name() string   { return "strict-redis" }
+func (s *StrictRedis) Put(key string) {}
+
+type StrictMemory struct {
+	ioc.ObjectImpl
+}
+
+func (s *StrictMemory) Name() string   { return "strict-memory" }
+func (s *StrictMemory) Put(key string) {}
+
+// RequiredConsumer 通过 required=true 声明必须存在的依赖
+type RequiredConsumer struct {
+	ioc.ObjectImpl
+	Missing *StrictRedis `ioc:"autowire=true;namespace=strict_required;name=strict-redis;required=true"`
+	Option  *StrictRedis `ioc:"autowire=true;namespace=strict_required;name=optional"`
+}
+
+func (c *RequiredConsumer) Name() string { return "required-consumer" }
+
+// AmbiguousConsumer 接口存在多个实现, 未指定 name
+type AmbiguousConsumer struct {
+	ioc.ObjectImpl
+	Storage StrictStorage `ioc:"autowire=true;namespace=strict_ambiguous"`
+	Missing *StrictRedis  `ioc:"autowire=true;namespace=strict_ambiguous;name=not-exist"`
+}
+
+func (c *AmbiguousConsumer) Name() string { return "ambiguous-consumer" }
+
+func TestAutowireRequired(t *testing.T) {
+	c := ioc.NewContainer()
+	ns := c.Namespace("strict_required")
+	ns.Registry(&RequiredConsumer{})
+
+	err := ns.Autowire()
+	if err == nil {
+		t.Fatal("expect error for missing required dependency")
+	}
+	if !strings.Contains(err.Error(), "required-consumer field Missing") {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// 非严格模式下, 未声明 required 的依赖不存在时不报错
+	if strings.Contains(err.Error(), "field Option") {
+		t.Fatalf("optional dependency should not be reported: %s", err)
+	}
+
+	// 注册依赖后注入成功
+	ns.Registry(&StrictRedis{})
+	if err := ns.Autowire(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAutowireStrictMode(t *testing.T) {
+	c := ioc.NewContainer()
+	ns := c.Namespace("strict_ambiguous")
+	ns.Registry(&StrictRedis{})
+	ns.Registry(&StrictMemory{})
+	consumer := &AmbiguousConsumer{}
+	ns.Registry(consumer)
+
+	// 非严格模式: 取第一个实现, 缺失的依赖保持为空
+	if err := ns.Autowire(); err != nil {
+		t.Fatal(err)
+	}
+	if consumer.Storage == nil {
+		t.Fatal("storage should be injected in non-strict mode")
+	}
+
+	c.SetStrictAutowire(true)
+
+	err := ns.Autowire()
+	if err == nil {
+		t.Fatal("expect error in strict mode")
+	}
+
+	// 所有问题合并为一个错误
+	msg := err.Error()
+	for _, want := range []string{
+		"ambiguous-consumer field Storage",
+		"strict-redis.v1",
+		"strict-memory.v1",
+		"ambiguous-consumer field Missing",
+		"not-exist",
+		"namespace strict_ambiguous",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error should contain %q, got: %s", want, msg)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package ioc
 
 import (
 	"path/filepath"
+	"time"
 )
 
 // ConfigLoader 配置加载器，提供流畅的Builder API
@@ -55,18 +56,51 @@ func (c *ConfigLoader) FromEnv(prefix string) *ConfigLoader {
 	return c
 }
 
+// FromSource 添加远程配置源, 配置源按添加顺序在配置文件之后加载
+// 例如: FromSource(ioc.NewHTTPSource("http://config-center/apps/demo.toml"))
+func (c *ConfigLoader) FromSource(src ConfigSource) *ConfigLoader {
+	c.req.ConfigSources = append(c.req.ConfigSources, src)
+	return c
+}
+
+// Profiles 按激活的环境叠加配置文件, 参考 ProfilePaths
+// 指定 profiles 时同时设置激活的环境, 否则读取环境变量 MCUBE_PROFILES
+func (c *ConfigLoader) Profiles(profiles ...string) *ConfigLoader {
+	if len(profiles) > 0 {
+		SetProfiles(profiles...)
+	}
+	c.req.ConfigFile.Profile = true
+	return c
+}
+
 // SkipIfNotExist 如果配置文件不存在则跳过，不报错
 func (c *ConfigLoader) SkipIfNotExist() *ConfigLoader {
 	c.req.ConfigFile.SkipIFNotExist = true
 	return c
 }
 
+// Watch 监听配置文件变化, 变化时热更新实现了 ConfigChangeHook 的对象
+// 配置源是否监听由配置源自己决定, 参考 WatchableConfigSource
+// interval 为文件检查间隔, 小于等于0时使用默认值
+func (c *ConfigLoader) Watch(interval time.Duration) *ConfigLoader {
+	c.req.ConfigFile.Watch = true
+	c.req.ConfigFile.WatchInterval = interval
+	return c
+}
+
 // ForceReload 强制重新加载，即使已经加载过
 func (c *ConfigLoader) ForceReload() *ConfigLoader {
 	c.req.ForceLoad = true
 	return c
 }
 
+// StrictAutowire 开启严格注入模式
+// 依赖不存在或者接口存在多个实现且未指定 name 时, 所有问题会合并为一个错误返回
+func (c *ConfigLoader) StrictAutowire() *ConfigLoader {
+	c.req.StrictAutowire = true
+	return c
+}
+
 // Load 执行配置加载
 func (c *ConfigLoader) Load() error {
 	return ConfigIocObject(c.req)
@@ -96,6 +130,13 @@ func WithEnvPrefix(prefix string) func(*LoadConfigRequest) {
 	}
 }
 
+// WithConfigSources 函数式选项：添加远程配置源
+func WithConfigSources(srcs ...ConfigSource) func(*LoadConfigRequest) {
+	return func(req *LoadConfigRequest) {
+		req.ConfigSources = append(req.ConfigSources, srcs...)
+	}
+}
+
 // SkipNotExist 函数式选项：跳过不存在的文件
 func SkipNotExist() func(*LoadConfigRequest) {
 	return func(req *LoadConfigRequest) {
@@ -103,6 +144,13 @@ func SkipNotExist() func(*LoadConfigRequest) {
 	}
 }
 
+// WithStrictAutowire 函数式选项：开启严格注入模式
+func WithStrictAutowire() func(*LoadConfigRequest) {
+	return func(req *LoadConfigRequest) {
+		req.StrictAutowire = true
+	}
+}
+
 // Load 函数式风格的配置加载
 // 使用示例:
 //
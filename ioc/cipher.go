@@ -0,0 +1,90 @@
+package ioc
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ConfigDecryptor 配置解密器, 加载配置时解密对象中的密文配置
+// application 配置默认注册为解密器, 密文格式为: 密文前缀(默认 @ciphered@) + 密文
+type ConfigDecryptor interface {
+	// 配置值是否为密文
+	IsCiphered(value string) bool
+	// 解密配置值
+	DecryptConfigValue(value string) (string, error)
+}
+
+var configDecryptor ConfigDecryptor
+
+// SetConfigDecryptor 设置配置解密器, 为 nil 时不解密
+func SetConfigDecryptor(d ConfigDecryptor) {
+	configDecryptor = d
+}
+
+// decryptObject 解密对象中的密文配置, 包括字符串字段、字符串切片以及值为字符串的 map
+// 只解密配置字段(规则与 configFields 一致), 嵌套结构体会递归解密, 注入的依赖(ioc 标签)以及解密器自身不会解密
+func decryptObject(obj Object) error {
+	d := configDecryptor
+	if d == nil || reflect.TypeOf(obj) == reflect.TypeOf(d) {
+		return nil
+	}
+	return decryptValue(d, reflect.ValueOf(obj), obj.Name(), map[uintptr]bool{})
+}
+
+func decryptValue(d ConfigDecryptor, v reflect.Value, path string, visited map[uintptr]bool) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || v.Elem().Kind() != reflect.Struct || visited[v.Pointer()] {
+			return nil
+		}
+		visited[v.Pointer()] = true
+		return decryptValue(d, v.Elem(), path, visited)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			sf, field := t.Field(i), v.Field(i)
+			// 嵌入的结构体展开, 其他字段只解密配置字段
+			if !(sf.Anonymous && sf.Type.Kind() == reflect.Struct) && (!isConfigField(sf) || !field.CanSet()) {
+				continue
+			}
+			if err := decryptValue(d, field, path+"."+sf.Name, visited); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		if !d.IsCiphered(v.String()) {
+			return nil
+		}
+		plain, err := d.DecryptConfigValue(v.String())
+		if err != nil {
+			return fmt.Errorf("decrypt %s error, %w", path, err)
+		}
+		v.SetString(plain)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := decryptValue(d, v.Index(i), fmt.Sprintf("%s[%d]", path, i), visited); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			value := iter.Value().String()
+			if !d.IsCiphered(value) {
+				continue
+			}
+			plain, err := d.DecryptConfigValue(value)
+			if err != nil {
+				return fmt.Errorf("decrypt %s[%v] error, %w", path, iter.Key(), err)
+			}
+			v.SetMapIndex(iter.Key(), reflect.ValueOf(plain).Convert(v.Type().Elem()))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package ioc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// reverseDecryptor 测试用的解密器, 密文为 "enc:" + 反转的明文
+type reverseDecryptor struct {
+	ObjectImpl
+	Prefix string `toml:"prefix" env:"PREFIX"`
+}
+
+func (d *reverseDecryptor) Name() string { return "cipher" }
+
+func (d *reverseDecryptor) IsCiphered(value string) bool {
+	return d.Prefix != "" && strings.HasPrefix(value, d.Prefix)
+}
+
+func (d *reverseDecryptor) DecryptConfigValue(value string) (string, error) {
+	value = strings.TrimPrefix(value, d.Prefix)
+	if value == "" {
+		return "", fmt.Errorf("empty ciphertext")
+	}
+	runes := []rune(value)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes), nil
+}
+
+type cipherTestAuth struct {
+	Password string `toml:"password"`
+}
+
+// CipherTestConfig 包含密文配置的对象
+type CipherTestConfig struct {
+	ObjectImpl
+	Password string            `toml:"password" env:"PASSWORD"`
+	Hosts    []string          `toml:"hosts"`
+	Extras   map[string]string `toml:"extras"`
+	Auth     cipherTestAuth    `toml:"auth"`
+	AuthPtr  *cipherTestAuth   `toml:"auth_ptr"`
+	// 不是配置字段, 不解密
+	Raw  string          `toml:"-" json:"-" yaml:"-"`
+	Peer *cipherTestAuth `ioc:"autowire=true;namespace=cipher_test"`
+}
+
+func (c *CipherTestConfig) Name() string { return "cipher_test" }
+
+func TestDecryptConfig(t *testing.T) {
+	d := &reverseDecryptor{}
+	SetConfigDecryptor(d)
+	t.Cleanup(func() { SetConfigDecryptor(nil) })
+
+	// 密钥(这里是前缀)与密文在同一个文件中, 并且解密器在密文对象之后注册
+	path := filepath.Join(t.TempDir(), "application.toml")
+	if err := os.WriteFile(path, []byte(`
+[cipher_test]
+password = "enc:321"
+hosts = ["enc:1.0.0.721", "localhost"]
+extras = { token = "enc:nekot", plain = "value" }
+auth = { password = "enc:htua" }
+auth_ptr = { password = "enc:rtp" }
+
+[cipher]
+prefix = "enc:"
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &CipherTestConfig{Raw: "enc:war", Peer: &cipherTestAuth{Password: "enc:reep"}}
+	store := &defaultStore{
+		store: []*NamespaceStore{newNamespaceStore("cipher_test")},
+	}
+	store.Namespace("cipher_test").Registry(conf).Registry(d)
+
+	req := NewLoadConfigRequest()
+	req.ConfigFile.Enabled = true
+	req.ConfigFile.Paths = []string{path}
+	if err := store.LoadConfig(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.Password != "123" {
+		t.Fatalf("password should be decrypted, got %s", conf.Password)
+	}
+	if conf.Hosts[0] != "127.0.0.1" || conf.Hosts[1] != "localhost" {
+		t.Fatalf("unexpected hosts %v", conf.Hosts)
+	}
+	if conf.Extras["token"] != "token" || conf.Extras["plain"] != "value" {
+		t.Fatalf("unexpected extras %v", conf.Extras)
+	}
+	if conf.Auth.Password != "auth" || conf.AuthPtr.Password != "ptr" {
+		t.Fatalf("nested struct should be decrypted, got %+v %+v", conf.Auth, conf.AuthPtr)
+	}
+	if d.Prefix != "enc:" {
+		t.Fatal("decryptor itself should not be decrypted")
+	}
+	if conf.Raw != "enc:war" || conf.Peer.Password != "enc:reep" {
+		t.Fatalf("non config fields should not be decrypted, got %s %s", conf.Raw, conf.Peer.Password)
+	}
+
+	// 环境变量中的密文
+	t.Setenv("CIPHER_TEST_PASSWORD", "enc:vne")
+	if err := store.Namespace("cipher_test").LoadFromEnv(""); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Password != "env" {
+		t.Fatalf("env password should be decrypted, got %s", conf.Password)
+	}
+
+	// 解密失败时返回错误
+	t.Setenv("CIPHER_TEST_PASSWORD", "enc:")
+	err := store.Namespace("cipher_test").LoadFromEnv("")
+	if err == nil || !strings.Contains(err.Error(), "cipher_test.Password") {
+		t.Fatalf("expect decrypt error, got %v", err)
+	}
+}
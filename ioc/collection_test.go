@@ -0,0 +1,116 @@
+package ioc_test
+
+import (
+	"testing"
+
+	"github.com/infraboard/mcube/v2/ioc"
+)
+
+// EventHandler 集合注入测试使用的接口
+type EventHandler interface {
+	Handle(event string) string
+}
+
+// NamedHandler 可以指定名称、优先级、版本以及标签的 EventHandler 实现
+type NamedHandler struct {
+	ioc.ObjectImpl
+	name     string
+	priority int
+	version  string
+	labels   map[string]string
+}
+
+func (h *NamedHandler) Name() string  { return h.name }
+func (h *NamedHandler) Priority() int { return h.priority }
+
+func (h *NamedHandler) Version() string {
+	if h.version == "" {
+		return ioc.DEFAULT_VERSION
+	}
+	return h.version
+}
+
+func (h *NamedHandler) Meta() ioc.ObjectMeta {
+	meta := ioc.DefaultObjectMeta()
+	for k, v := range h.labels {
+		meta.Extra[k] = v
+	}
+	return meta
+}
+
+func (h *NamedHandler) Handle(event string) string {
+	return h.name + ":" + event
+}
+
+// EventBus 注入所有 EventHandler
+type EventBus struct {
+	ioc.ObjectImpl
+	Handlers    []EventHandler             `ioc:"autowire=true;namespace=collection_test"`
+	HandlerMap  map[string]EventHandler    `ioc:"autowire=true;namespace=collection_test"`
+	Audits      []EventHandler             `ioc:"autowire=true;namespace=collection_test;labels=kind:audit"`
+	Legacy      map[string]EventHandler    `ioc:"autowire=true;namespace=collection_test;version=v1"`
+	Concrete    []*NamedHandler            `ioc:"autowire=true;namespace=collection_test"`
+	NotRequired []ioc.PostStopHook         `ioc:"autowire=true;namespace=collection_empty"`
+	Required    map[string]ioc.PreStopHook `ioc:"autowire=true;namespace=collection_empty;required=true"`
+}
+
+func (b *EventBus) Name() string { return "event-bus" }
+
+func TestCollectionAutowire(t *testing.T) {
+	ns := ioc.DefaultStore.Namespace("collection_test")
+	ns.Registry(&NamedHandler{name: "low", priority: 1})
+	ns.Registry(&NamedHandler{name: "high", priority: 10, labels: map[string]string{"kind": "audit"}})
+	ns.Registry(&NamedHandler{name: "mid", priority: 5})
+	ns.Registry(&NamedHandler{name: "mid", priority: 5, version: "v2"})
+	bus := &EventBus{}
+	ns.Registry(bus)
+
+	err := ns.Autowire()
+	if err == nil {
+		t.Fatal("expect error for empty required collection")
+	}
+
+	// 按优先级从高到低排序, 同名对象只注入最新版本
+	got := []string{}
+	for _, h := range bus.Handlers {
+		got = append(got, h.(*NamedHandler).name+"@"+h.(*NamedHandler).Version())
+	}
+	want := []string{"high@v1", "mid@v2", "low@v1"}
+	if len(got) != len(want) {
+		t.Fatalf("handlers: got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("handlers: got %v, want %v", got, want)
+		}
+	}
+
+	if len(bus.HandlerMap) != 3 || bus.HandlerMap["mid"].(*NamedHandler).Version() != "v2" {
+		t.Fatalf("handler map: got %v", bus.HandlerMap)
+	}
+
+	if len(bus.Audits) != 1 || bus.Audits[0].(*NamedHandler).name != "high" {
+		t.Fatalf("audits: got %v", bus.Audits)
+	}
+
+	if len(bus.Legacy) != 3 || bus.Legacy["mid"].(*NamedHandler).Version() != "v1" {
+		t.Fatalf("legacy: got %v", bus.Legacy)
+	}
+
+	if len(bus.Concrete) != 3 {
+		t.Fatalf("concrete: got %v", bus.Concrete)
+	}
+
+	if bus.NotRequired == nil || len(bus.NotRequired) != 0 {
+		t.Fatalf("not required collection should be empty, got %v", bus.NotRequired)
+	}
+
+	// 注册满足条件的对象后注入成功（同时避免影响使用 DefaultStore 的其他测试）
+	ioc.DefaultStore.Namespace("collection_empty").Registry(&NamedHandler{name: "stopper"})
+	if err := ns.Autowire(); err != nil {
+		t.Fatal(err)
+	}
+	if len(bus.Required) != 1 {
+		t.Fatalf("required: got %v", bus.Required)
+	}
+}
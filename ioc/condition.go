@@ -0,0 +1,245 @@
+package ioc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Condition 对象的注册条件, 通过 RegistryIf 注册的对象只有所有条件都满足时才会启用
+// 未启用的对象不会加载配置变化、注入、初始化以及关闭, 也不能通过 Get 等方法获取
+type Condition struct {
+	desc  string
+	match func(*ConditionContext) bool
+}
+
+// NewCondition 自定义条件
+func NewCondition(desc string, match func(*ConditionContext) bool) Condition {
+	return Condition{desc: desc, match: match}
+}
+
+func (c Condition) String() string {
+	return c.desc
+}
+
+// Match 条件是否满足
+func (c Condition) Match(ctx *ConditionContext) bool {
+	return c.match(ctx)
+}
+
+// OnConfig 配置的值等于 value 时启用, key 格式为 对象名称.配置键, 比如: lock.provider
+// 条件在配置加载完成后计算, 因此配置文件、配置源以及环境变量中的配置都会生效
+func OnConfig(key string, value any) Condition {
+	return NewCondition(fmt.Sprintf("config %s=%v", key, value), func(ctx *ConditionContext) bool {
+		v, ok := ctx.ConfigValue(key)
+		return ok && fmt.Sprint(v) == fmt.Sprint(value)
+	})
+}
+
+// OnEnv 环境变量存在且不为空时启用, 指定了 values 时环境变量的值需要等于其中之一
+func OnEnv(key string, values ...string) Condition {
+	desc := fmt.Sprintf("env %s", key)
+	if len(values) > 0 {
+		desc = fmt.Sprintf("env %s in %v", key, values)
+	}
+	return NewCondition(desc, func(ctx *ConditionContext) bool {
+		v := os.Getenv(key)
+		if len(values) == 0 {
+			return v != ""
+		}
+		return slices.Contains(values, v)
+	})
+}
+
+// OnObject 命名空间中存在启用的同名对象时启用
+func OnObject(namespace, name string) Condition {
+	return NewCondition(fmt.Sprintf("object %s:%s", namespace, name), func(ctx *ConditionContext) bool {
+		return ctx.HasObject(namespace, name)
+	})
+}
+
+// OnInterface 命名空间中存在启用的 T 接口实现时启用
+func OnInterface[T any](namespace string) Condition {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return NewCondition(fmt.Sprintf("interface %s:%s", namespace, t), func(ctx *ConditionContext) bool {
+		return ctx.HasInterface(namespace, t)
+	})
+}
+
+// OnProfile 任意一个环境激活时启用
+func OnProfile(values ...string) Condition {
+	return NewCondition(fmt.Sprintf("profile in %v", values), func(ctx *ConditionContext) bool {
+		for _, p := range ctx.Profiles() {
+			if slices.Contains(values, p) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ConditionContext 计算条件时可以查询的容器信息
+type ConditionContext struct {
+	store *defaultStore
+}
+
+// ConfigValue 查询对象的配置, key 格式为 对象名称.配置键, 嵌套的配置使用 . 连接
+func (c *ConditionContext) ConfigValue(key string) (any, bool) {
+	name, path, ok := strings.Cut(key, ".")
+	if !ok {
+		return nil, false
+	}
+	for _, ns := range c.store.namespacesByPriority() {
+		for _, w := range ns.activeItems() {
+			if w.Name != name {
+				continue
+			}
+			var value any = ConfigValues(w.Value)
+			for _, k := range strings.Split(path, ".") {
+				m, ok := value.(map[string]any)
+				if !ok {
+					return nil, false
+				}
+				if value, ok = m[k]; !ok {
+					return nil, false
+				}
+			}
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// HasObject 命名空间中是否存在启用的同名对象
+func (c *ConditionContext) HasObject(namespace, name string) bool {
+	return len(c.store.Namespace(namespace).versions(name)) > 0
+}
+
+// HasInterface 命名空间中是否存在启用的接口实现
+func (c *ConditionContext) HasInterface(namespace string, objType reflect.Type) bool {
+	return len(c.store.Namespace(namespace).implementWrappers(objType, "")) > 0
+}
+
+// Profiles 激活的环境
+func (c *ConditionContext) Profiles() []string {
+	return Profiles()
+}
+
+// RegistryIf 条件注册, 所有条件都满足时对象才会启用
+// 使用示例:
+//
+//	ioc.Config().RegistryIf(&RedisLock{}, ioc.OnConfig("lock.provider", "redis"))
+func (s *NamespaceStore) RegistryIf(v Object, conditions ...Condition) StoreUser {
+	return s.registry(v, func(w *ObjectWrapper) {
+		w.conditions = conditions
+	})
+}
+
+// Disabled 对象的注册条件是否不满足
+func (obj *ObjectWrapper) Disabled() bool {
+	return obj.disabled.Load()
+}
+
+// Conditions 对象的注册条件
+func (obj *ObjectWrapper) Conditions() []Condition {
+	return obj.conditions
+}
+
+// unmatchedCondition 返回第一个不满足的条件
+func (obj *ObjectWrapper) unmatchedCondition(ctx *ConditionContext) (Condition, bool) {
+	for _, c := range obj.conditions {
+		if !c.Match(ctx) {
+			return c, true
+		}
+	}
+	return Condition{}, false
+}
+
+// activeItems 启用的对象
+func (s *NamespaceStore) activeItems() []*ObjectWrapper {
+	items := s.getItems()
+	for i, item := range items {
+		if !item.Disabled() {
+			continue
+		}
+		// 存在未启用的对象时才复制
+		active := slices.Clone(items[:i])
+		for _, item := range items[i+1:] {
+			if !item.Disabled() {
+				active = append(active, item)
+			}
+		}
+		return active
+	}
+	return items
+}
+
+// ResolveConditions 计算所有对象的注册条件
+// 条件可能依赖其他条件对象是否启用, 因此先启用所有条件对象, 再逐轮禁用条件不满足的对象, 直到没有变化
+// 同一命名空间中启用的同名同版本对象超过一个时返回错误
+func (s *defaultStore) ResolveConditions() error {
+	var conditional []*ObjectWrapper
+	for _, ns := range s.store {
+		for _, w := range ns.getItems() {
+			if len(w.conditions) > 0 {
+				conditional = append(conditional, w)
+			}
+		}
+	}
+	if len(conditional) == 0 {
+		return nil
+	}
+
+	wasDisabled := make(map[*ObjectWrapper]bool, len(conditional))
+	for _, w := range conditional {
+		wasDisabled[w] = w.disabled.Swap(false)
+	}
+
+	ctx := &ConditionContext{store: s}
+	unmatched := map[*ObjectWrapper]Condition{}
+	for changed := true; changed; {
+		changed = false
+		for _, w := range conditional {
+			if w.Disabled() {
+				continue
+			}
+			if c, ok := w.unmatchedCondition(ctx); ok {
+				w.disabled.Store(true)
+				unmatched[w] = c
+				changed = true
+			}
+		}
+	}
+
+	for _, w := range conditional {
+		switch {
+		case w.Disabled() && !wasDisabled[w]:
+			debug("[IOC] %s disabled, condition not matched: %s", ObjectUid(w), unmatched[w])
+			w.setState(OBJECT_DISABLED, fmt.Errorf("condition not matched: %s", unmatched[w]))
+		case !w.Disabled() && wasDisabled[w]:
+			w.setState(OBJECT_REGISTERED, nil)
+		}
+	}
+	return s.checkDuplicate()
+}
+
+// checkDuplicate 检查启用的对象中是否存在同名同版本的对象
+func (s *defaultStore) checkDuplicate() error {
+	var errs []error
+	for _, ns := range s.store {
+		items := ns.activeItems()
+		for i, w := range items {
+			for _, other := range items[:i] {
+				if other.Name == w.Name && CompareVersion(other.Version, w.Version) == 0 {
+					errs = append(errs, fmt.Errorf("[%s] ioc obj %s (version %s) has already registered, conditions of duplicated objects should not match at the same time",
+						ns.Namespace, w.Name, w.Version))
+					break
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,137 @@
+package ioc
+
+import (
+	"strings"
+	"testing"
+)
+
+// ConditionTestConfig 条件注册测试使用的配置对象
+type ConditionTestConfig struct {
+	ObjectImpl
+	Provider string `toml:"provider" env:"PROVIDER"`
+}
+
+func (c *ConditionTestConfig) Name() string { return "cond_config" }
+
+// ConditionTestLock 条件注册测试使用的接口
+type ConditionTestLock interface {
+	Lock() string
+}
+
+// ConditionTestObject 条件注册测试使用的对象
+type ConditionTestObject struct {
+	ObjectImpl
+	name   string
+	inited bool
+}
+
+func (o *ConditionTestObject) Name() string { return o.name }
+
+func (o *ConditionTestObject) Init() error {
+	o.inited = true
+	return nil
+}
+
+func (o *ConditionTestObject) Lock() string { return o.name }
+
+// ConditionTestConsumer 注入 ConditionTestLock 接口的对象
+type ConditionTestConsumer struct {
+	ObjectImpl
+	Lock ConditionTestLock `ioc:"autowire=true;namespace=default"`
+}
+
+func (o *ConditionTestConsumer) Name() string { return "consumer" }
+
+func TestRegistryIf(t *testing.T) {
+	t.Setenv("COND_CONFIG_PROVIDER", "redis")
+	t.Setenv("COND_TEST_FEATURE", "on")
+	SetProfiles("dev")
+	defer resetProfiles()
+
+	c := NewContainer()
+	c.Config().Registry(&ConditionTestConfig{Provider: "go_cache"})
+
+	redis := &ConditionTestObject{name: "redis_lock"}
+	goCache := &ConditionTestObject{name: "go_cache_lock"}
+	feature := &ConditionTestObject{name: "feature"}
+	missingEnv := &ConditionTestObject{name: "missing_env"}
+	prod := &ConditionTestObject{name: "prod_only"}
+	dependent := &ConditionTestObject{name: "dependent"}
+	chained := &ConditionTestObject{name: "chained"}
+	consumer := &ConditionTestConsumer{}
+
+	c.Default().
+		RegistryIf(redis, OnConfig("cond_config.provider", "redis")).
+		RegistryIf(goCache, OnConfig("cond_config.provider", "go_cache")).
+		RegistryIf(feature, OnEnv("COND_TEST_FEATURE", "on"), OnProfile("dev", "test")).
+		RegistryIf(missingEnv, OnEnv("COND_TEST_MISSING")).
+		RegistryIf(prod, OnProfile("prod")).
+		RegistryIf(dependent, OnObject(DEFAULT_NAMESPACE, "prod_only")).
+		RegistryIf(chained, OnInterface[ConditionTestLock](DEFAULT_NAMESPACE), OnObject(DEFAULT_NAMESPACE, "redis_lock"))
+	c.Controller().Registry(consumer)
+
+	if err := c.LoadConfig(NewLoadConfigRequest()); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Autowire(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.InitIocObject(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "redis_lock.v1,go_cache_lock.v1 (disabled),feature.v1,missing_env.v1 (disabled),prod_only.v1 (disabled),dependent.v1 (disabled),chained.v1"
+	if got := strings.Join(c.Default().List(), ","); got != want {
+		t.Fatalf("unexpected list %s", got)
+	}
+	if !redis.inited || goCache.inited || prod.inited || dependent.inited {
+		t.Fatal("only enabled objects should be initialized")
+	}
+	if c.Default().Get("go_cache_lock") != nil {
+		t.Fatal("disabled object should not be found")
+	}
+	if consumer.Lock != redis {
+		t.Fatalf("should inject enabled implementation, got %v", consumer.Lock)
+	}
+
+	w := c.Namespace(DEFAULT_NAMESPACE).getItems()[1]
+	if w.State() != OBJECT_DISABLED || !strings.Contains(w.Err().Error(), "cond_config.provider=go_cache") {
+		t.Fatalf("unexpected state %s, %v", w.State(), w.Err())
+	}
+}
+
+func resetProfiles() {
+	profiles.mu.Lock()
+	defer profiles.mu.Unlock()
+	profiles.set, profiles.values = false, nil
+}
+
+func TestRegistryIfDuplicate(t *testing.T) {
+	c := NewContainer()
+	c.Config().Registry(&ConditionTestConfig{Provider: "go_cache"})
+	redis := &ConditionTestObject{name: "lock"}
+	goCache := &ConditionTestObject{name: "lock"}
+	c.Default().
+		RegistryIf(redis, OnConfig("cond_config.provider", "redis")).
+		RegistryIf(goCache, OnConfig("cond_config.provider", "go_cache"))
+
+	if err := c.LoadConfig(NewLoadConfigRequest()); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Default().Get("lock"); got != goCache {
+		t.Fatalf("should get enabled object, got %v", got)
+	}
+
+	// 条件同时满足时启用的对象重复
+	c.Default().RegistryIf(&ConditionTestObject{name: "lock"}, OnConfig("cond_config.provider", "go_cache"))
+	if err := c.InitIocObject(); err == nil || !strings.Contains(err.Error(), "already registered") {
+		t.Fatalf("duplicated enabled objects should be rejected, %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("duplicated unconditional object should panic")
+		}
+	}()
+	c.Default().Registry(&ConditionTestObject{name: "other"}).Registry(&ConditionTestObject{name: "other"})
+}
@@ -0,0 +1,120 @@
+package accesslog
+
+import (
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/infraboard/mcube/v2/http/middleware/accesslog"
+	"github.com/infraboard/mcube/v2/ioc"
+	"github.com/infraboard/mcube/v2/ioc/config/log"
+	ioc_tls "github.com/infraboard/mcube/v2/ioc/config/tls"
+)
+
+func init() {
+	ioc.Config().Registry(defaultConfig)
+}
+
+var defaultConfig = &AccessLog{
+	ExcludePaths:             []string{"/healthz", "/readyz", "/metrics"},
+	SuccessSampleRate:        1,
+	SlowThresholdMillisecond: 1000,
+}
+
+type AccessLog struct {
+	ioc.ObjectImpl
+
+	// 输出的字段, 为空时输出除 query 以外的所有字段
+	Fields []string `json:"fields" yaml:"fields" toml:"fields" env:"FIELDS" envSeparator:","`
+	// 不记录日志的路径, 以 * 结尾时按前缀匹配
+	ExcludePaths []string `json:"exclude_paths" yaml:"exclude_paths" toml:"exclude_paths" env:"EXCLUDE_PATHS" envSeparator:","`
+	// 2xx 响应的采样率, 取值 0~1, 错误响应以及慢请求总是记录
+	SuccessSampleRate float64 `json:"success_sample_rate" yaml:"success_sample_rate" toml:"success_sample_rate" env:"SUCCESS_SAMPLE_RATE"`
+	// 慢请求阈值(毫秒), 超过时日志级别提升为 warn, 0 表示不检查
+	SlowThresholdMillisecond int64 `json:"slow_threshold" yaml:"slow_threshold" toml:"slow_threshold" env:"SLOW_THRESHOLD"`
+
+	mu         sync.Mutex
+	structured *accesslog.Structured
+	// 通过 AddUserFunc 添加的函数, 重新初始化时添加到新的 Structured
+	userFuncs []accesslog.UserFunc
+}
+
+func (a *AccessLog) Name() string {
+	return AppName
+}
+
+// 在 gorestful(899) 以及 gin(898) 之前初始化
+func (a *AccessLog) Priority() int {
+	return 900
+}
+
+func (a *AccessLog) Init() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s, err := a.newStructured(a.Options())
+	if err != nil {
+		return err
+	}
+	a.structured = s
+	return nil
+}
+
+func (a *AccessLog) newStructured(opts *accesslog.Options) (*accesslog.Structured, error) {
+	s, err := accesslog.NewStructured(opts)
+	if err != nil {
+		return nil, err
+	}
+	s.SetLogger(log.Sub(AppName))
+	// mTLS 客户端证书的 CommonName
+	s.AddUserFunc(func(r *http.Request) string {
+		if id := ioc_tls.ClientIdentityFromContext(r.Context()); id != nil {
+			return id.CommonName
+		}
+		return ""
+	})
+	for _, fn := range a.userFuncs {
+		s.AddUserFunc(fn)
+	}
+	return s, nil
+}
+
+func (a *AccessLog) Options() *accesslog.Options {
+	return &accesslog.Options{
+		Fields:            a.Fields,
+		ExcludePaths:      a.ExcludePaths,
+		SuccessSampleRate: a.SuccessSampleRate,
+		SlowThreshold:     time.Duration(a.SlowThresholdMillisecond) * time.Millisecond,
+	}
+}
+
+// Structured 结构化访问日志, 未初始化时使用默认参数
+func (a *AccessLog) Structured() *accesslog.Structured {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.structured == nil {
+		s, err := a.newStructured(a.Options())
+		if err != nil {
+			s, _ = a.newStructured(accesslog.DefaultOptions())
+		}
+		a.structured = s
+	}
+	return a.structured
+}
+
+// AddUserFunc 添加获取用户信息的函数, 比如认证中间件从上下文中读取用户
+// 同一个函数(按函数地址判断)只添加一次, 重复初始化时可以重复调用
+func (a *AccessLog) AddUserFunc(fn accesslog.UserFunc) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ptr := reflect.ValueOf(fn).Pointer()
+	for _, f := range a.userFuncs {
+		if reflect.ValueOf(f).Pointer() == ptr {
+			return
+		}
+	}
+	a.userFuncs = append(a.userFuncs, fn)
+	if a.structured != nil {
+		a.structured.AddUserFunc(fn)
+	}
+}
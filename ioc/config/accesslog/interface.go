@@ -0,0 +1,23 @@
+package accesslog
+
+import (
+	"github.com/infraboard/mcube/v2/http/middleware/accesslog"
+	"github.com/infraboard/mcube/v2/ioc"
+)
+
+const (
+	AppName = "access_log"
+)
+
+func Get() *AccessLog {
+	obj := ioc.Config().Get(AppName)
+	if obj == nil {
+		return defaultConfig
+	}
+	return obj.(*AccessLog)
+}
+
+// L 结构化访问日志, gin、go-restful 以及 httprouter 共用
+func L() *accesslog.Structured {
+	return Get().Structured()
+}
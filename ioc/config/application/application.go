@@ -15,6 +15,7 @@ import (
 
 func init() {
 	ioc.Config().Registry(defaultConfig)
+	ioc.SetConfigDecryptor(defaultConfig)
 }
 
 var defaultConfig = &Application{
@@ -185,6 +186,16 @@ func (i *Application) DecryptString(ciphertext string) (string, error) {
 	}
 }
 
+// IsCiphered 配置值是否为密文, 即以 CipherPrefix 开头
+func (i *Application) IsCiphered(value string) bool {
+	return i.CipherPrefix != "" && strings.HasPrefix(value, i.CipherPrefix)
+}
+
+// DecryptConfigValue 解密配置中的密文, 密文格式为: CipherPrefix + EncryptString 的结果
+func (i *Application) DecryptConfigValue(value string) (string, error) {
+	return i.DecryptString(strings.TrimPrefix(value, i.CipherPrefix))
+}
+
 func (i *Application) Host() string {
 	if i.appURL != nil {
 		return i.appURL.Host
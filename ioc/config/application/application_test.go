@@ -31,3 +31,28 @@ func init() {
 		panic(err)
 	}
 }
+
+func TestDecryptConfigValue(t *testing.T) {
+	app := &application.Application{
+		EncryptAlgorithm: application.ENCRYPT_ALGORITHM_AES_GCM,
+		KeyLength:        application.Get().KeyLength,
+		CipherPrefix:     "@ciphered@",
+	}
+	app.EncryptKey = app.GenerateRandomEncryptKey()
+
+	cipherText, err := app.EncryptString("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := app.CipherPrefix + cipherText
+	if !app.IsCiphered(value) || app.IsCiphered("123456") {
+		t.Fatal("unexpected IsCiphered result")
+	}
+	plain, err := app.DecryptConfigValue(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain != "123456" {
+		t.Fatalf("want 123456, got %s", plain)
+	}
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/infraboard/mcube/v2/exception"
+	"github.com/infraboard/mcube/v2/http/label"
+	"github.com/infraboard/mcube/v2/http/middleware/realip"
+)
+
+// AuditEvent 开启 Audit 标签的路由处理完成后的审计事件
+type AuditEvent struct {
+	Time time.Time `json:"time"`
+	// 未认证时为 nil
+	Principal *Principal `json:"principal"`
+	Method    string     `json:"method"`
+	Path      string     `json:"path"`
+	// 路由模板, 比如 /books/{id}
+	Route    string        `json:"route"`
+	Resource string        `json:"resource"`
+	Action   string        `json:"action"`
+	Status   int           `json:"status"`
+	RemoteIP string        `json:"remote_ip"`
+	Latency  time.Duration `json:"latency"`
+	Meta     label.Meta    `json:"meta"`
+}
+
+// NewAuditEvent 根据请求生成审计事件, 由各个框架的适配层补充路由以及响应信息
+func NewAuditEvent(r *http.Request, meta label.Meta) *AuditEvent {
+	return &AuditEvent{
+		Time:      time.Now(),
+		Principal: FromContext(r.Context()),
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Resource:  meta.Resource(),
+		Action:    meta.Action(),
+		RemoteIP:  realip.FromRequest(r),
+		Meta:      meta,
+	}
+}
+
+// NewFailedAuditEvent 认证或者鉴权失败时的审计事件, 状态码为异常的 HttpCode, 用户为 nil
+func NewFailedAuditEvent(r *http.Request, meta label.Meta, err error) *AuditEvent {
+	e := NewAuditEvent(r, meta)
+	e.Principal = nil
+	e.Status = http.StatusInternalServerError
+	var ae *exception.ApiException
+	if errors.As(err, &ae) {
+		e.Status = ae.GetHttpCode()
+	}
+	return e
+}
+
+// AuditHook 审计事件的处理函数, 比如写入数据库或者发送到消息队列
+type AuditHook func(ctx context.Context, e *AuditEvent)
@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/infraboard/mcube/v2/exception"
+	"github.com/infraboard/mcube/v2/http/label"
+	"github.com/infraboard/mcube/v2/ioc"
+	ioc_accesslog "github.com/infraboard/mcube/v2/ioc/config/accesslog"
+	"github.com/infraboard/mcube/v2/ioc/config/log"
+	"github.com/rs/zerolog"
+)
+
+func init() {
+	ioc.Config().Registry(defaultConfig)
+}
+
+var defaultConfig = &Auth{
+	Authenticators: []string{TYPE_BEARER, TYPE_API_KEY, TYPE_BASIC},
+	JWT: &JWT{
+		RolesClaim:       "roles",
+		PermissionsClaim: "permissions",
+		Leeway:           60,
+	},
+	APIKeyHeader: "X-API-Key",
+	Roles:        map[string][]string{},
+	AuditLog:     true,
+}
+
+// Auth 路由级别的认证与鉴权, 根据路由的 label.Meta 标签(Auth/Permission/Allow/Audit)处理请求
+type Auth struct {
+	ioc.ObjectImpl
+	log *zerolog.Logger
+
+	// 认证方式, 按顺序尝试, 使用第一个携带了凭证的认证方式
+	Authenticators []string `json:"authenticators" yaml:"authenticators" toml:"authenticators" env:"AUTHENTICATORS" envSeparator:","`
+	// Bearer Token(JWT) 认证
+	JWT *JWT `json:"jwt" yaml:"jwt" toml:"jwt" envPrefix:"JWT_"`
+	// API Key 所在的请求头
+	APIKeyHeader string `json:"api_key_header" yaml:"api_key_header" toml:"api_key_header" env:"API_KEY_HEADER"`
+	// 静态配置的 API Key
+	APIKeys []*APIKey `json:"api_keys" yaml:"api_keys" toml:"api_keys"`
+	// Basic 认证的用户
+	Users []*User `json:"users" yaml:"users" toml:"users"`
+	// 角色拥有的权限, 格式为 resource:action, 支持 * 通配
+	Roles map[string][]string `json:"roles" yaml:"roles" toml:"roles"`
+	// 审计事件输出到 audit 日志
+	AuditLog bool `json:"audit_log" yaml:"audit_log" toml:"audit_log" env:"AUDIT_LOG"`
+
+	mu             sync.RWMutex
+	authenticators map[string]Authenticator
+	// 通过 RegistryAuthenticator 注册的认证器, 重复初始化时保留
+	custom     map[string]bool
+	authorizer Authorizer
+	// 通过 SetAuthorizer 替换了鉴权器, 重复初始化时保留
+	customAuthorizer bool
+	hooks            []AuditHook
+	userFuncAdded    bool
+}
+
+func (a *Auth) Name() string {
+	return AppName
+}
+
+// 在 Web 框架的认证中间件(restful_auth, gin_auth)之前初始化
+func (a *Auth) Priority() int {
+	return 290
+}
+
+func (a *Auth) Init() error {
+	a.log = log.Sub(AppName)
+
+	if a.JWT == nil {
+		a.JWT = &JWT{}
+	}
+	if err := a.JWT.load(); err != nil {
+		return fmt.Errorf("load jwt public key error, %s", err)
+	}
+	builtin := map[string]Authenticator{
+		TYPE_BASIC:   NewBasicAuthenticator(a.Users),
+		TYPE_API_KEY: NewAPIKeyAuthenticator(a.APIKeyHeader, a.APIKeys),
+	}
+	if a.JWT.Enabled() {
+		builtin[TYPE_BEARER] = NewBearerAuthenticator(a.JWT)
+	} else if slices.Contains(a.Authenticators, TYPE_BEARER) {
+		a.log.Debug().Msg("jwt secret or public key not set, bearer authenticator disabled")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// 内置认证器根据当前配置重建, 未启用的移除, 同名的自定义认证器优先
+	for _, name := range []string{TYPE_BASIC, TYPE_API_KEY, TYPE_BEARER} {
+		if a.custom[name] {
+			continue
+		}
+		if at, ok := builtin[name]; ok {
+			a.setAuthenticator(name, at)
+		} else {
+			delete(a.authenticators, name)
+		}
+	}
+	if !a.customAuthorizer {
+		a.authorizer = NewRBACAuthorizer(a.Roles)
+	}
+
+	// 访问日志中记录认证的用户
+	ioc_accesslog.Get().AddUserFunc(principalSubject)
+	return nil
+}
+
+func principalSubject(r *http.Request) string {
+	if p := FromContext(r.Context()); p != nil {
+		return p.Subject
+	}
+	return ""
+}
+
+// RegistryAuthenticator 注册认证器, 同名时覆盖内置的认证器, 未出现在 authenticators 配置中时追加到最后
+func (a *Auth) RegistryAuthenticator(name string, at Authenticator) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.custom == nil {
+		a.custom = map[string]bool{}
+	}
+	a.custom[name] = true
+	a.setAuthenticator(name, at)
+}
+
+func (a *Auth) setAuthenticator(name string, at Authenticator) {
+	if a.authenticators == nil {
+		a.authenticators = map[string]Authenticator{}
+	}
+	a.authenticators[name] = at
+	if !slices.Contains(a.Authenticators, name) {
+		a.Authenticators = append(a.Authenticators, name)
+	}
+}
+
+// SetAuthorizer 替换默认的 RBAC 鉴权器
+func (a *Auth) SetAuthorizer(at Authorizer) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.customAuthorizer = true
+	a.authorizer = at
+}
+
+// AddAuditHook 添加审计事件的处理函数
+func (a *Auth) AddAuditHook(hook AuditHook) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.hooks = append(a.hooks, hook)
+}
+
+// Required 路由是否需要认证, 开启了 Auth、Permission 标签或者设置了 Allow 标签时需要认证
+func Required(meta label.Meta) bool {
+	return meta.AuthEnable() || meta.PermissionEnable() || len(meta.Allow()) > 0
+}
+
+// Authenticate 按顺序使用认证器认证请求
+func (a *Auth) Authenticate(r *http.Request) (*Principal, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, name := range a.Authenticators {
+		at, ok := a.authenticators[name]
+		if !ok {
+			continue
+		}
+		p, err := at.Authenticate(r)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			return p, nil
+		}
+	}
+	return nil, exception.NewUnauthorized("authentication required")
+}
+
+// Check 根据路由的标签认证以及鉴权, 返回携带用户身份的请求, 不需要认证时返回原请求
+func (a *Auth) Check(r *http.Request, meta label.Meta) (*http.Request, error) {
+	if !Required(meta) {
+		return r, nil
+	}
+
+	p, err := a.Authenticate(r)
+	if err != nil {
+		return r, err
+	}
+	if meta.PermissionEnable() || len(meta.Allow()) > 0 {
+		a.mu.RLock()
+		at := a.authorizer
+		a.mu.RUnlock()
+		if err := at.Authorize(r, p, meta); err != nil {
+			return r, err
+		}
+	}
+	return r.WithContext(WithPrincipal(r.Context(), p)), nil
+}
+
+// Audit 触发审计事件, 开启 audit_log 时先输出到 audit 日志
+func (a *Auth) Audit(ctx context.Context, e *AuditEvent) {
+	if a.AuditLog {
+		a.logAudit(ctx, e)
+	}
+	a.mu.RLock()
+	hooks := a.hooks
+	a.mu.RUnlock()
+	for _, hook := range hooks {
+		hook(ctx, e)
+	}
+}
+
+func (a *Auth) logAudit(ctx context.Context, e *AuditEvent) {
+	l := log.Sub("audit").Info().
+		Str("method", e.Method).
+		Str("route", e.Route).
+		Str("path", e.Path).
+		Str("resource", e.Resource).
+		Str("action", e.Action).
+		Int("status", e.Status).
+		Str("remote_ip", e.RemoteIP).
+		Dur("latency", e.Latency)
+	if e.Principal != nil {
+		l = l.Str("user", e.Principal.Subject).Str("auth_type", e.Principal.Type)
+	}
+	l.Msg("audit")
+}
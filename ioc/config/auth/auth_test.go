@@ -0,0 +1,243 @@
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/infraboard/mcube/v2/exception"
+	"github.com/infraboard/mcube/v2/http/label"
+	"github.com/infraboard/mcube/v2/ioc/config/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(t *testing.T, alg string, claims map[string]any, signer func(string) []byte) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signing := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signing + "." + base64.RawURLEncoding.EncodeToString(signer(signing))
+}
+
+func hs256(secret string) func(string) []byte {
+	return func(s string) []byte {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(s))
+		return mac.Sum(nil)
+	}
+}
+
+func newAuth(t *testing.T) *auth.Auth {
+	a := &auth.Auth{
+		Authenticators: []string{auth.TYPE_BEARER, auth.TYPE_API_KEY, auth.TYPE_BASIC},
+		JWT:            &auth.JWT{Secret: "secret", Issuer: "mcube", RolesClaim: "roles"},
+		APIKeyHeader:   "X-API-Key",
+		APIKeys:        []*auth.APIKey{{Key: "key1", Subject: "ci", Permissions: []string{"book:*"}}},
+		Users:          []*auth.User{{Username: "admin", Password: "123456", Roles: []string{"admin"}}},
+		Roles: map[string][]string{
+			"admin":  {"*"},
+			"viewer": {"book:get", "book:list"},
+		},
+	}
+	require.NoError(t, a.Init())
+	return a
+}
+
+func TestJWT(t *testing.T) {
+	should := require.New(t)
+	j := &auth.JWT{Secret: "secret", Issuer: "mcube", Audience: "api"}
+
+	claims, err := j.Parse(sign(t, "HS256", map[string]any{"sub": "alice", "iss": "mcube", "aud": []string{"api"}}, hs256("secret")))
+	should.NoError(err)
+	should.Equal("alice", claims["sub"])
+
+	_, err = j.Parse(sign(t, "HS256", map[string]any{"iss": "mcube", "aud": "api"}, hs256("other")))
+	should.ErrorContains(err, "signature")
+	_, err = j.Parse(sign(t, "HS256", map[string]any{"iss": "mcube", "aud": "api", "exp": time.Now().Add(-time.Hour).Unix()}, hs256("secret")))
+	should.ErrorContains(err, "expired")
+	_, err = j.Parse(sign(t, "HS256", map[string]any{"iss": "other", "aud": "api"}, hs256("secret")))
+	should.ErrorContains(err, "issuer")
+	_, err = j.Parse(sign(t, "none", map[string]any{"iss": "mcube", "aud": "api"}, func(string) []byte { return nil }))
+	should.ErrorContains(err, "not supported")
+}
+
+func TestJWTRS256(t *testing.T) {
+	should := require.New(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	should.NoError(err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	should.NoError(err)
+	file := filepath.Join(t.TempDir(), "pub.pem")
+	should.NoError(os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	a := &auth.Auth{
+		Authenticators: []string{auth.TYPE_BEARER},
+		JWT:            &auth.JWT{PublicKeyFile: file, RolesClaim: "roles"},
+	}
+	should.NoError(a.Init())
+
+	token := sign(t, "RS256", map[string]any{"sub": "bob", "roles": []string{"viewer"}}, func(s string) []byte {
+		h := sha256.Sum256([]byte(s))
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, h[:])
+		should.NoError(err)
+		return sig
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	p, err := a.Authenticate(req)
+	should.NoError(err)
+	should.Equal("bob", p.Subject)
+	should.Equal([]string{"viewer"}, p.Roles)
+
+	// 配置了公钥时不允许 HS 算法
+	req.Header.Set("Authorization", "Bearer "+sign(t, "HS256", map[string]any{"sub": "bob"}, hs256("")))
+	_, err = a.Authenticate(req)
+	should.Error(err)
+}
+
+func TestCheck(t *testing.T) {
+	a := newAuth(t)
+	bookGet := label.Meta{label.Auth: true, label.Permission: true, label.Resource: "book", label.Action: "get"}
+	userDelete := label.Meta{label.Auth: true, label.Permission: true, label.Resource: "user", label.Action: "delete"}
+	adminOnly := label.Meta{label.Auth: true, label.Allow: []string{"admin"}}
+
+	viewer := sign(t, "HS256", map[string]any{"sub": "alice", "iss": "mcube", "roles": []string{"viewer"}}, hs256("secret"))
+	cases := []struct {
+		name   string
+		meta   label.Meta
+		header map[string]string
+		basic  []string
+		code   int
+		user   string
+	}{
+		{name: "public", meta: label.Meta{}},
+		{name: "no credential", meta: bookGet, code: exception.CODE_UNAUTHORIZED},
+		{name: "bearer role permission", meta: bookGet, header: map[string]string{"Authorization": "Bearer " + viewer}, user: "alice"},
+		{name: "bearer denied", meta: userDelete, header: map[string]string{"Authorization": "Bearer " + viewer}, code: exception.CODE_FORBIDDEN},
+		{name: "bearer role not allowed", meta: adminOnly, header: map[string]string{"Authorization": "Bearer " + viewer}, code: exception.CODE_FORBIDDEN},
+		{name: "bearer invalid", meta: bookGet, header: map[string]string{"Authorization": "Bearer x.y.z"}, code: exception.CODE_ACCESS_TOKEN_ILLEGAL},
+		{name: "api key permission", meta: bookGet, header: map[string]string{"X-API-Key": "key1"}, user: "ci"},
+		{name: "api key invalid", meta: bookGet, header: map[string]string{"X-API-Key": "key2"}, code: exception.CODE_UNAUTHORIZED},
+		{name: "basic admin", meta: adminOnly, basic: []string{"admin", "123456"}, user: "admin"},
+		{name: "basic wrong password", meta: adminOnly, basic: []string{"admin", "x"}, code: exception.CODE_UNAUTHORIZED},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			should := require.New(t)
+			req := httptest.NewRequest("GET", "/", nil)
+			for k, v := range c.header {
+				req.Header.Set(k, v)
+			}
+			if c.basic != nil {
+				req.SetBasicAuth(c.basic[0], c.basic[1])
+			}
+
+			r, err := a.Check(req, c.meta)
+			if c.code != 0 {
+				should.Error(err)
+				should.Equal(c.code, err.(*exception.ApiException).ErrorCode())
+				return
+			}
+			should.NoError(err)
+			p := auth.FromContext(r.Context())
+			if c.user == "" {
+				should.Nil(p)
+				return
+			}
+			should.Equal(c.user, p.Subject)
+		})
+	}
+}
+
+func TestCustomAuthenticatorAndAudit(t *testing.T) {
+	should := require.New(t)
+	a := newAuth(t)
+	a.RegistryAuthenticator("header", auth.AuthenticatorFunc(func(r *http.Request) (*auth.Principal, error) {
+		if v := r.Header.Get("X-User"); v != "" {
+			return &auth.Principal{Type: "header", Subject: v}, nil
+		}
+		return nil, nil
+	}))
+	var events []*auth.AuditEvent
+	a.AddAuditHook(func(ctx context.Context, e *auth.AuditEvent) { events = append(events, e) })
+
+	req := httptest.NewRequest("DELETE", "/books/1", nil)
+	req.Header.Set("X-User", "bob")
+	meta := label.Meta{label.Auth: true, label.Audit: true, label.Resource: "book", label.Action: "delete"}
+	r, err := a.Check(req, meta)
+	should.NoError(err)
+
+	a.Audit(r.Context(), auth.NewAuditEvent(r, meta))
+	should.Len(events, 1)
+	should.Equal("bob", events[0].Principal.Subject)
+	should.Equal("book", events[0].Resource)
+	should.Equal("delete", events[0].Action)
+}
+
+func TestReInit(t *testing.T) {
+	should := require.New(t)
+	a := newAuth(t)
+	a.RegistryAuthenticator(auth.TYPE_API_KEY, auth.AuthenticatorFunc(func(r *http.Request) (*auth.Principal, error) {
+		if v := r.Header.Get("X-User"); v != "" {
+			return &auth.Principal{Type: "header", Subject: v}, nil
+		}
+		return nil, nil
+	}))
+
+	// 轮换密码, 关闭 JWT 后重新初始化
+	a.Users = []*auth.User{{Username: "admin", Password: "654321", Roles: []string{"admin"}}}
+	a.JWT = &auth.JWT{}
+	should.NoError(a.Init())
+
+	meta := label.Meta{label.Auth: true}
+	check := func(set func(r *http.Request)) error {
+		req := httptest.NewRequest("GET", "/books", nil)
+		set(req)
+		_, err := a.Check(req, meta)
+		return err
+	}
+	should.Error(check(func(r *http.Request) { r.SetBasicAuth("admin", "123456") }))
+	should.NoError(check(func(r *http.Request) { r.SetBasicAuth("admin", "654321") }))
+	token := sign(t, "HS256", map[string]any{"sub": "alice", "iss": "mcube", "exp": time.Now().Add(time.Hour).Unix()}, hs256("secret"))
+	should.Error(check(func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }))
+	// 自定义的同名认证器保留
+	should.NoError(check(func(r *http.Request) { r.Header.Set("X-User", "bob") }))
+}
+
+func TestMatchPermission(t *testing.T) {
+	should := require.New(t)
+	should.True(auth.MatchPermission("*", "book", "get"))
+	should.True(auth.MatchPermission("book:*", "book", "get"))
+	should.True(auth.MatchPermission("*:get", "book", "get"))
+	should.False(auth.MatchPermission("book:get", "book", "delete"))
+	should.False(auth.MatchPermission("user:*", "book", "get"))
+}
+
+func TestFailedAuditEvent(t *testing.T) {
+	should := require.New(t)
+	a := newAuth(t)
+
+	meta := label.Meta{label.Auth: true, label.Audit: true, label.Resource: "book", label.Action: "delete"}
+	req := httptest.NewRequest("DELETE", "/books/1", nil)
+	_, err := a.Check(req, meta)
+	should.Error(err)
+
+	e := auth.NewFailedAuditEvent(req, meta, err)
+	should.Nil(e.Principal)
+	should.Equal(http.StatusUnauthorized, e.Status)
+	should.Equal("book", e.Resource)
+}
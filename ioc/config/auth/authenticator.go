@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/infraboard/mcube/v2/exception"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Authenticator 认证器, 请求中没有该认证方式的凭证时返回 nil, nil, 交给下一个认证器处理
+// 凭证无效时返回错误
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// AuthenticatorFunc 函数形式的认证器
+type AuthenticatorFunc func(r *http.Request) (*Principal, error)
+
+func (f AuthenticatorFunc) Authenticate(r *http.Request) (*Principal, error) {
+	return f(r)
+}
+
+// NewBearerAuthenticator 校验 Authorization: Bearer <jwt>
+func NewBearerAuthenticator(j *JWT) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) (*Principal, error) {
+		token, ok := cutPrefixFold(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			return nil, nil
+		}
+		claims, err := j.Parse(strings.TrimSpace(token))
+		if err != nil {
+			return nil, exception.NewAccessTokenIllegal("%s", err)
+		}
+		return j.NewPrincipal(claims), nil
+	})
+}
+
+// User 静态配置的用户, 用于 Basic 认证
+type User struct {
+	Username string `json:"username" yaml:"username" toml:"username"`
+	// 明文或者 bcrypt 的哈希($2a$ 开头)
+	Password string   `json:"password" yaml:"password" toml:"password"`
+	Roles    []string `json:"roles" yaml:"roles" toml:"roles"`
+}
+
+// NewBasicAuthenticator 校验 Authorization: Basic <base64(username:password)>
+func NewBasicAuthenticator(users []*User) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) (*Principal, error) {
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			return nil, nil
+		}
+		for _, u := range users {
+			if u.Username == username && checkPassword(u.Password, password) {
+				return &Principal{Type: TYPE_BASIC, Subject: u.Username, Roles: u.Roles}, nil
+			}
+		}
+		return nil, exception.NewUnauthorized("username or password error")
+	})
+}
+
+func checkPassword(hashed, password string) bool {
+	if strings.HasPrefix(hashed, "$2") {
+		return bcrypt.CompareHashAndPassword([]byte(hashed), []byte(password)) == nil
+	}
+	return subtle.ConstantTimeCompare([]byte(hashed), []byte(password)) == 1
+}
+
+// APIKey 静态配置的 API Key
+type APIKey struct {
+	Key         string   `json:"key" yaml:"key" toml:"key"`
+	Subject     string   `json:"subject" yaml:"subject" toml:"subject"`
+	Roles       []string `json:"roles" yaml:"roles" toml:"roles"`
+	Permissions []string `json:"permissions" yaml:"permissions" toml:"permissions"`
+}
+
+// NewAPIKeyAuthenticator 校验请求头中的 API Key
+func NewAPIKeyAuthenticator(header string, keys []*APIKey) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) (*Principal, error) {
+		v := r.Header.Get(header)
+		if v == "" {
+			return nil, nil
+		}
+		for _, k := range keys {
+			if subtle.ConstantTimeCompare([]byte(k.Key), []byte(v)) == 1 {
+				return &Principal{Type: TYPE_API_KEY, Subject: k.Subject, Roles: k.Roles, Permissions: k.Permissions}, nil
+			}
+		}
+		return nil, exception.NewUnauthorized("api key invalid")
+	})
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return "", false
+	}
+	return s[len(prefix):], true
+}
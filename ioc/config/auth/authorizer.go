@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/infraboard/mcube/v2/exception"
+	"github.com/infraboard/mcube/v2/http/label"
+)
+
+// Authorizer 鉴权器, 根据路由的标签判断用户是否有权限访问
+type Authorizer interface {
+	Authorize(r *http.Request, p *Principal, meta label.Meta) error
+}
+
+// AuthorizerFunc 函数形式的鉴权器
+type AuthorizerFunc func(r *http.Request, p *Principal, meta label.Meta) error
+
+func (f AuthorizerFunc) Authorize(r *http.Request, p *Principal, meta label.Meta) error {
+	return f(r, p, meta)
+}
+
+// NewRBACAuthorizer 基于角色的鉴权器, roles 为角色拥有的权限(resource:action)
+//   - Allow 标签: 用户需要拥有其中一个角色
+//   - Permission 标签: 用户或者用户的角色需要拥有 Resource 标签的资源以及 Action 标签的操作的权限
+func NewRBACAuthorizer(roles map[string][]string) Authorizer {
+	return AuthorizerFunc(func(r *http.Request, p *Principal, meta label.Meta) error {
+		if allow := meta.Allow(); len(allow) > 0 && !p.HasRole(allow...) {
+			return exception.NewPermissionDeny("role %v not allowed, allowed roles: %v", p.Roles, allow)
+		}
+		if !meta.PermissionEnable() {
+			return nil
+		}
+
+		resource, action := meta.Resource(), meta.Action()
+		for _, perm := range p.Permissions {
+			if MatchPermission(perm, resource, action) {
+				return nil
+			}
+		}
+		for _, role := range p.Roles {
+			for _, perm := range roles[role] {
+				if MatchPermission(perm, resource, action) {
+					return nil
+				}
+			}
+		}
+		return exception.NewPermissionDeny("no permission to %s %s", action, resource)
+	})
+}
@@ -0,0 +1,63 @@
+package gin
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/infraboard/mcube/v2/http/gin/response"
+	"github.com/infraboard/mcube/v2/http/label"
+	ioc_auth "github.com/infraboard/mcube/v2/ioc/config/auth"
+)
+
+const (
+	// 路由标签在 gin.Context 中的 key
+	META_KEY = "route_meta"
+)
+
+// Meta gin 没有路由元数据, 通过路由选项的方式设置标签并进行认证、鉴权以及审计
+//
+//	r.GET("/:id", auth.Meta(label.Meta{
+//		label.Auth:       label.Enable,
+//		label.Permission: label.Enable,
+//		label.Resource:   "book",
+//		label.Action:     label.Get.Value(),
+//	}), h.Get)
+func Meta(meta label.Meta) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(META_KEY, meta)
+
+		a := ioc_auth.Get()
+		r, err := a.Check(c.Request, meta)
+		if err != nil {
+			response.Failed(c, err)
+			if meta.AuditEnable() {
+				e := ioc_auth.NewFailedAuditEvent(c.Request, meta, err)
+				e.Route = c.FullPath()
+				a.Audit(c.Request.Context(), e)
+			}
+			return
+		}
+		c.Request = r
+
+		if !meta.AuditEnable() {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+		e := ioc_auth.NewAuditEvent(c.Request, meta)
+		e.Route = c.FullPath()
+		e.Status = c.Writer.Status()
+		e.Latency = time.Since(start)
+		a.Audit(c.Request.Context(), e)
+	}
+}
+
+// GetMeta 获取当前路由的标签
+func GetMeta(c *gin.Context) label.Meta {
+	if v, ok := c.Get(META_KEY); ok {
+		return v.(label.Meta)
+	}
+	return label.Meta{}
+}
@@ -0,0 +1,87 @@
+package gorestful
+
+import (
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/infraboard/mcube/v2/http/label"
+	"github.com/infraboard/mcube/v2/http/restful/response"
+	"github.com/infraboard/mcube/v2/ioc"
+	ioc_auth "github.com/infraboard/mcube/v2/ioc/config/auth"
+	"github.com/infraboard/mcube/v2/ioc/config/gorestful"
+	"github.com/infraboard/mcube/v2/ioc/config/log"
+	"github.com/rs/zerolog"
+)
+
+func init() {
+	ioc.Config().Registry(&Auth{
+		Enabled: true,
+	})
+}
+
+// Auth 读取 go-restful 路由的 Metadata 进行认证、鉴权以及审计
+//
+//	ws.Route(ws.GET("/{id}").To(h.Get).
+//		Metadata(label.Auth, label.Enable).
+//		Metadata(label.Permission, label.Enable).
+//		Metadata(label.Resource, "book").
+//		Metadata(label.Action, label.Get.Value()))
+type Auth struct {
+	ioc.ObjectImpl
+	log *zerolog.Logger
+
+	Enabled bool `toml:"enabled" json:"enabled" yaml:"enabled" env:"ENABLED"`
+}
+
+func (a *Auth) Name() string {
+	return AppName
+}
+
+func (a *Auth) Priority() int {
+	return 287
+}
+
+func (a *Auth) Init() error {
+	a.log = log.Sub(AppName)
+	if a.Enabled {
+		gorestful.RootRouter().Filter(Filter)
+		a.log.Info().Msg("auth enabled")
+	}
+	return nil
+}
+
+// Filter 认证鉴权的过滤器, 没有匹配路由或者路由没有设置标签时直接放行
+func Filter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+	route := req.SelectedRoute()
+	if route == nil {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+
+	meta := label.Meta(route.Metadata())
+	a := ioc_auth.Get()
+	r, err := a.Check(req.Request, meta)
+	if err != nil {
+		response.Failed(resp, err)
+		if meta.AuditEnable() {
+			e := ioc_auth.NewFailedAuditEvent(req.Request, meta, err)
+			e.Route = req.SelectedRoutePath()
+			a.Audit(req.Request.Context(), e)
+		}
+		return
+	}
+	req.Request = r
+
+	if !meta.AuditEnable() {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+
+	start := time.Now()
+	chain.ProcessFilter(req, resp)
+	e := ioc_auth.NewAuditEvent(req.Request, meta)
+	e.Route = req.SelectedRoutePath()
+	e.Status = resp.StatusCode()
+	e.Latency = time.Since(start)
+	a.Audit(req.Request.Context(), e)
+}
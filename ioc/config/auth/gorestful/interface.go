@@ -0,0 +1,5 @@
+package gorestful
+
+const (
+	AppName = "restful_auth"
+)
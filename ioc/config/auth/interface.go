@@ -0,0 +1,17 @@
+package auth
+
+import (
+	"github.com/infraboard/mcube/v2/ioc"
+)
+
+const (
+	AppName = "auth"
+)
+
+func Get() *Auth {
+	obj := ioc.Config().Get(AppName)
+	if obj == nil {
+		return defaultConfig
+	}
+	return obj.(*Auth)
+}
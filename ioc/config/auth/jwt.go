@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// JWT 校验 Bearer Token 的参数
+type JWT struct {
+	// HS256/HS384/HS512 使用的密钥
+	Secret string `json:"secret" yaml:"secret" toml:"secret" env:"SECRET"`
+	// RS*/ES* 使用的公钥, PEM 格式的文件路径
+	PublicKeyFile string `json:"public_key_file" yaml:"public_key_file" toml:"public_key_file" env:"PUBLIC_KEY_FILE"`
+	// 签发者, 为空时不校验
+	Issuer string `json:"issuer" yaml:"issuer" toml:"issuer" env:"ISSUER"`
+	// 受众, 为空时不校验
+	Audience string `json:"audience" yaml:"audience" toml:"audience" env:"AUDIENCE"`
+	// 角色所在的 claim
+	RolesClaim string `json:"roles_claim" yaml:"roles_claim" toml:"roles_claim" env:"ROLES_CLAIM"`
+	// 权限所在的 claim
+	PermissionsClaim string `json:"permissions_claim" yaml:"permissions_claim" toml:"permissions_claim" env:"PERMISSIONS_CLAIM"`
+	// 允许的时钟偏差(秒)
+	Leeway int64 `json:"leeway" yaml:"leeway" toml:"leeway" env:"LEEWAY"`
+
+	publicKey crypto.PublicKey
+}
+
+// Enabled 是否配置了密钥
+func (j *JWT) Enabled() bool {
+	return j.Secret != "" || j.PublicKeyFile != ""
+}
+
+func (j *JWT) load() error {
+	if j.PublicKeyFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(j.PublicKeyFile)
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("no pem data in %s", j.PublicKeyFile)
+	}
+	if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+		j.publicKey = cert.PublicKey
+		return nil
+	}
+	j.publicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	return err
+}
+
+// Parse 校验 token 的签名以及有效期, 返回 token 的 claims
+func (j *JWT) Parse(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token format error")
+	}
+
+	header := struct {
+		Alg string `json:"alg"`
+	}{}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("decode token header error, %s", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decode token signature error, %s", err)
+	}
+	if err := j.verify(header.Alg, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	claims := map[string]any{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("decode token claims error, %s", err)
+	}
+	if err := j.validate(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (j *JWT) verify(alg, signing string, sig []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("token alg %s not supported", alg)
+	}
+	var h crypto.Hash
+	switch alg[2:] {
+	case "256":
+		h = crypto.SHA256
+	case "384":
+		h = crypto.SHA384
+	case "512":
+		h = crypto.SHA512
+	default:
+		return fmt.Errorf("token alg %s not supported", alg)
+	}
+
+	switch alg[:2] {
+	case "HS":
+		if j.Secret == "" {
+			return fmt.Errorf("token alg %s not allowed", alg)
+		}
+		mac := hmac.New(h.New, []byte(j.Secret))
+		mac.Write([]byte(signing))
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return errors.New("token signature invalid")
+		}
+		return nil
+	case "RS", "ES":
+	default:
+		return fmt.Errorf("token alg %s not supported", alg)
+	}
+
+	hasher := h.New()
+	hasher.Write([]byte(signing))
+	digest := hasher.Sum(nil)
+	switch key := j.publicKey.(type) {
+	case *rsa.PublicKey:
+		if alg[:2] == "RS" && rsa.VerifyPKCS1v15(key, h, digest, sig) == nil {
+			return nil
+		}
+	case *ecdsa.PublicKey:
+		// ES* 的签名为定长的 r||s
+		if n := len(sig) / 2; alg[:2] == "ES" && len(sig)%2 == 0 &&
+			ecdsa.Verify(key, digest, new(big.Int).SetBytes(sig[:n]), new(big.Int).SetBytes(sig[n:])) {
+			return nil
+		}
+	default:
+		return fmt.Errorf("token alg %s not allowed", alg)
+	}
+	return errors.New("token signature invalid")
+}
+
+func (j *JWT) validate(claims map[string]any) error {
+	now := time.Now().Unix()
+	if exp, ok := claims["exp"].(float64); ok && now > int64(exp)+j.Leeway {
+		return errors.New("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now < int64(nbf)-j.Leeway {
+		return errors.New("token not valid yet")
+	}
+	if j.Issuer != "" && claims["iss"] != j.Issuer {
+		return fmt.Errorf("token issuer %v not allowed", claims["iss"])
+	}
+	if j.Audience != "" && !slices.Contains(stringSlice(claims["aud"]), j.Audience) {
+		return fmt.Errorf("token audience %v not allowed", claims["aud"])
+	}
+	return nil
+}
+
+// NewPrincipal 根据 claims 生成用户身份
+func (j *JWT) NewPrincipal(claims map[string]any) *Principal {
+	p := &Principal{
+		Type:       TYPE_BEARER,
+		Attributes: claims,
+	}
+	p.Subject, _ = claims["sub"].(string)
+	if j.RolesClaim != "" {
+		p.Roles = stringSlice(claims[j.RolesClaim])
+	}
+	if j.PermissionsClaim != "" {
+		p.Permissions = stringSlice(claims[j.PermissionsClaim])
+	}
+	return p
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// stringSlice claim 的值可以是字符串数组或者以空格分隔的字符串(比如 scope)
+func stringSlice(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		s := make([]string, 0, len(v))
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				s = append(s, str)
+			}
+		}
+		return s
+	}
+	return nil
+}
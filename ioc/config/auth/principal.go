@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// 认证方式
+const (
+	TYPE_BEARER  = "bearer"
+	TYPE_BASIC   = "basic"
+	TYPE_API_KEY = "api_key"
+)
+
+// Principal 认证通过后的用户身份
+type Principal struct {
+	// 认证方式, 比如 bearer, basic, api_key
+	Type string `json:"type"`
+	// 用户标识, 比如用户名, JWT 的 sub
+	Subject string `json:"subject"`
+	// 用户的角色
+	Roles []string `json:"roles"`
+	// 用户直接拥有的权限, 格式为 resource:action, 支持 * 通配
+	Permissions []string `json:"permissions"`
+	// 其他信息, 比如 JWT 的 claims
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+func (p *Principal) String() string {
+	return p.Subject
+}
+
+// HasRole 是否拥有其中一个角色, * 表示任意角色
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, r := range roles {
+		if r == "*" || slices.Contains(p.Roles, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPermission 权限是否匹配资源以及操作, 支持 *、resource:*、*:action
+func MatchPermission(perm, resource, action string) bool {
+	if perm == "*" {
+		return true
+	}
+	r, a, _ := strings.Cut(perm, ":")
+	return (r == "*" || r == resource) && (a == "*" || a == action)
+}
+
+type principalKey struct{}
+
+// WithPrincipal 把用户身份放到上下文中
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext 获取上下文中的用户身份, 未认证时返回 nil
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
@@ -2,6 +2,7 @@ package bus
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/infraboard/mcube/v2/ioc"
 )
@@ -14,6 +15,20 @@ const (
 	APP_PRIORITY = 595
 )
 
+const (
+	PROVIDER_KAFKA    = "kafka"
+	PROVIDER_NATS     = "nats"
+	PROVIDER_RABBITMQ = "rabbitmq"
+)
+
+// OnProvider 配置 bus.provider 等于 provider 或者未配置时启用, 同时引入多个实现时需要配置 bus.provider
+func OnProvider(provider string) ioc.Condition {
+	return ioc.NewCondition(fmt.Sprintf("config bus.provider=%s", provider), func(ctx *ioc.ConditionContext) bool {
+		v, ok := ctx.ConfigValue(APP_NAME + ".provider")
+		return !ok || v == "" || v == provider
+	})
+}
+
 func GetService() Service {
 	return ioc.Config().Get(APP_NAME).(Service)
 }
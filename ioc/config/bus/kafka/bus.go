@@ -15,10 +15,7 @@ import (
 )
 
 func init() {
-	ioc.Config().Registry(&BusServiceImpl{
-		producer: map[string]*kafka.Writer{},
-		consumer: map[string]*kafka.Reader{},
-	})
+	ioc.Config().RegistryIf(&BusServiceImpl{}, bus.OnProvider(bus.PROVIDER_KAFKA))
 }
 
 var _ bus.Service = (*BusServiceImpl)(nil)
@@ -27,6 +24,8 @@ type BusServiceImpl struct {
 	ioc.ObjectImpl
 	log *zerolog.Logger
 
+	// 使用的实现, 同时引入多个实现时通过该配置选择
+	Provider string `toml:"provider" json:"provider" yaml:"provider" env:"PROVIDER"`
 	// group 队列模式下的 队列名称或者消费组名称，一个组里面的实例消费一个队列
 	Group string `toml:"group" json:"group" yaml:"group"  env:"GROUP"`
 	// nodename 广播模式下的节点名称，默认hostname, 每个节点独立一个 节点队列: group.nodename
@@ -52,6 +51,14 @@ func (b *BusServiceImpl) Init() error {
 	}
 
 	b.log = log.Sub(b.Name())
+	b.Lock()
+	if b.producer == nil {
+		b.producer = map[string]*kafka.Writer{}
+	}
+	if b.consumer == nil {
+		b.consumer = map[string]*kafka.Reader{}
+	}
+	b.Unlock()
 
 	if b.NodeName == "" {
 		hostname, err := os.Hostname()
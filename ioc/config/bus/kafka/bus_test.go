@@ -0,0 +1,27 @@
+package kafka_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/infraboard/mcube/v2/ioc"
+	"github.com/infraboard/mcube/v2/ioc/config/bus"
+	"github.com/infraboard/mcube/v2/ioc/config/bus/kafka"
+	"github.com/infraboard/mcube/v2/ioc/ioctest"
+)
+
+// 克隆的容器中的对象由 Init 创建生产者以及消费者的缓存, 没有 kafka 服务时返回错误而不是 panic
+func TestPublishCloned(t *testing.T) {
+	c := ioctest.New(t).Load()
+	b := c.Config().Get(bus.APP_NAME).(*kafka.BusServiceImpl)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := b.Publish(ctx, &bus.Event{Subject: "test", Data: []byte("hello")}); err == nil {
+		t.Fatal("publish without kafka server should fail")
+	}
+	if b == ioc.Config().Get(bus.APP_NAME) {
+		t.Fatal("cloned container should not share the bus object")
+	}
+}
@@ -16,10 +16,7 @@ import (
 )
 
 func init() {
-	ioc.Config().Registry(&BusServiceImpl{
-		publishers: map[string]*rabbitmq.Publisher{},
-		consumers:  map[string]*rabbitmq.Consumer{},
-	})
+	ioc.Config().RegistryIf(&BusServiceImpl{}, bus.OnProvider(bus.PROVIDER_RABBITMQ))
 }
 
 var _ bus.Service = (*BusServiceImpl)(nil)
@@ -28,6 +25,8 @@ type BusServiceImpl struct {
 	ioc.ObjectImpl
 	log *zerolog.Logger
 
+	// 使用的实现, 同时引入多个实现时通过该配置选择
+	Provider string `toml:"provider" json:"provider" yaml:"provider" env:"PROVIDER"`
 	// group 队列模式下的 队列名称或者消费组名称，一个组里面的实例消费一个队列
 	Group string `toml:"group" json:"group" yaml:"group"  env:"GROUP"`
 	// nodename 广播模式下的节点名称，默认hostname, 每个节点独立一个 节点队列: group.nodename
@@ -62,6 +61,14 @@ func (b *BusServiceImpl) Init() error {
 	}
 
 	b.log = log.Sub(b.Name())
+	b.mu.Lock()
+	if b.publishers == nil {
+		b.publishers = map[string]*rabbitmq.Publisher{}
+	}
+	if b.consumers == nil {
+		b.consumers = map[string]*rabbitmq.Consumer{}
+	}
+	b.mu.Unlock()
 	return nil
 }
 
@@ -1,6 +1,8 @@
 package cache
 
 import (
+	"fmt"
+
 	"github.com/infraboard/mcube/v2/ioc"
 	"github.com/rs/zerolog"
 
@@ -48,11 +50,13 @@ func (m *cache) Init() error {
 			redis: ioc_redis.Client(),
 			ttl:   m.TTL,
 		}
-	default:
+	case PROVIDER_GO_CACHE:
 		m.c = &goCache{
 			gc:  gocache.C(),
 			ttl: m.TTL,
 		}
+	default:
+		return fmt.Errorf("cache provider %s not supported, supported providers: %s, %s", m.PROVIDER, PROVIDER_GO_CACHE, PROVIDER_REDIS)
 	}
 	return nil
 }
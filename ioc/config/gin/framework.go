@@ -1,8 +1,12 @@
 package gin
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/infraboard/mcube/v2/http/middleware/accesslog"
 	"github.com/infraboard/mcube/v2/ioc"
+	ioc_accesslog "github.com/infraboard/mcube/v2/ioc/config/accesslog"
 	"github.com/infraboard/mcube/v2/ioc/config/application"
 	"github.com/infraboard/mcube/v2/ioc/config/http"
 	"github.com/infraboard/mcube/v2/ioc/config/log"
@@ -13,9 +17,10 @@ import (
 
 func init() {
 	ioc.Config().Registry(&GinFramework{
-		Recovery: true,
-		Mode:     gin.DebugMode,
-		Trace:    true,
+		Recovery:  true,
+		Mode:      gin.DebugMode,
+		Trace:     true,
+		AccessLog: false,
 	})
 }
 
@@ -30,6 +35,8 @@ type GinFramework struct {
 	Mode string `toml:"mode" json:"mode" yaml:"mode" env:"Mode"`
 	// 开启Trace
 	Trace bool `toml:"trace" json:"trace" yaml:"trace" env:"TRACE"`
+	// 开启结构化访问日志, 参数见 access_log 配置, 默认关闭
+	AccessLog bool `toml:"access_log" json:"access_log" yaml:"access_log" env:"ACCESS_LOG"`
 }
 
 func (g *GinFramework) Init() error {
@@ -47,11 +54,35 @@ func (g *GinFramework) Init() error {
 		g.Engine.Use(otelgin.Middleware(application.Get().GetAppName()))
 	}
 
+	if g.AccessLog {
+		g.Engine.Use(g.access_log())
+	}
+
 	// 注册给Http服务器
 	http.Get().SetRouter(g.Engine)
 	return nil
 }
 
+func (g *GinFramework) access_log() gin.HandlerFunc {
+	al := ioc_accesslog.L()
+	return func(c *gin.Context) {
+		if al.Skip(c.Request) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+		al.Log(&accesslog.Record{
+			Request:  c.Request,
+			Route:    c.FullPath(),
+			Status:   c.Writer.Status(),
+			BytesOut: int64(c.Writer.Size()),
+			Latency:  time.Since(start),
+		})
+	}
+}
+
 func (g *GinFramework) Priority() int {
 	return 898
 }
@@ -4,7 +4,9 @@ import (
 	"time"
 
 	"github.com/emicklei/go-restful/v3"
+	"github.com/infraboard/mcube/v2/http/middleware/accesslog"
 	"github.com/infraboard/mcube/v2/ioc"
+	ioc_accesslog "github.com/infraboard/mcube/v2/ioc/config/accesslog"
 	"github.com/infraboard/mcube/v2/ioc/config/application"
 	"github.com/infraboard/mcube/v2/ioc/config/http"
 	"github.com/infraboard/mcube/v2/ioc/config/log"
@@ -27,7 +29,7 @@ type GoRestfulFramework struct {
 
 	// 开启Trace
 	Trace bool `toml:"trace" json:"trace" yaml:"trace" env:"TRACE"`
-	//
+	// 开启结构化访问日志, 参数见 access_log 配置
 	AccessLog bool `toml:"access_log" json:"access_log" yaml:"access_log" env:"ACCESS_LOG"`
 }
 
@@ -61,22 +63,24 @@ func (g *GoRestfulFramework) Init() error {
 }
 
 func (g *GoRestfulFramework) access_log() restful.FilterFunction {
-	logger := log.Sub("access_log")
+	al := ioc_accesslog.L()
 	return func(req *restful.Request, resp *restful.Response, fc *restful.FilterChain) {
-		start := time.Now()
-
 		// 更新请求的 context, 携带日志上下文
 		ctx := req.Request.Context()
 		req.Request = req.Request.WithContext(log.FromCtx(ctx).WithContext(ctx))
+		if al.Skip(req.Request) {
+			fc.ProcessFilter(req, resp)
+			return
+		}
 
+		start := time.Now()
 		fc.ProcessFilter(req, resp)
-		// generate an access log message
-		logger.Info().Msgf("%-10s | %-15s | %-5d | %-10s | %s",
-			time.Since(start),
-			req.Request.RemoteAddr,
-			resp.StatusCode(),
-			req.Request.Method,
-			req.Request.URL.Path)
-
+		al.Log(&accesslog.Record{
+			Request:  req.Request,
+			Route:    req.SelectedRoutePath(),
+			Status:   resp.StatusCode(),
+			BytesOut: int64(resp.ContentLength()),
+			Latency:  time.Since(start),
+		})
 	}
 }
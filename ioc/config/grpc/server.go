@@ -4,14 +4,18 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sync/atomic"
 
 	"github.com/infraboard/mcube/v2/grpc/middleware/recovery"
 	"github.com/infraboard/mcube/v2/ioc"
+	ioc_http "github.com/infraboard/mcube/v2/ioc/config/http"
 	"github.com/infraboard/mcube/v2/ioc/config/log"
+	ioc_tls "github.com/infraboard/mcube/v2/ioc/config/tls"
 	"github.com/infraboard/mcube/v2/ioc/config/trace"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 func init() {
@@ -33,6 +37,7 @@ type Grpc struct {
 	Host   string `json:"host" yaml:"host" toml:"host" env:"HOST"`
 	Port   int    `json:"port" yaml:"port" toml:"port" env:"PORT"`
 
+	// 单独开启 TLS, 证书为空时使用共享的 [tls] 配置
 	EnableSSL bool   `json:"enable_ssl" yaml:"enable_ssl" toml:"enable_ssl" env:"ENABLE_SSL"`
 	CertFile  string `json:"cert_file" yaml:"cert_file" toml:"cert_file" env:"CERT_FILE"`
 	KeyFile   string `json:"key_file" yaml:"key_file" toml:"key_file" env:"KEY_FILE"`
@@ -45,7 +50,11 @@ type Grpc struct {
 	// 解析后的数据
 	interceptors []grpc.UnaryServerInterceptor
 	svr          *grpc.Server
+	listener     net.Listener
+	inflight     atomic.Int64
 	log          *zerolog.Logger
+	// 单端口模式下由 HTTP 服务处理请求, Stop 后关闭
+	done chan struct{}
 
 	// 启动后执行
 	PostStart func(context.Context) error `json:"-" yaml:"-" toml:"-" env:"-"`
@@ -70,6 +79,9 @@ func (i *Grpc) Priority() int {
 }
 
 func (g *Grpc) Addr() string {
+	if g.isSinglePort() {
+		return ioc_http.Get().Addr()
+	}
 	return fmt.Sprintf("%s:%d", g.Host, g.Port)
 }
 
@@ -82,10 +94,40 @@ func (g *Grpc) Server() *grpc.Server {
 
 func (g *Grpc) Init() error {
 	g.log = log.Sub("grpc")
-	g.svr = grpc.NewServer(g.ServerOpts()...)
+	opts := g.ServerOpts()
+
+	tlsConf, err := ioc_tls.Get().ServerConfig(&ioc_tls.ServerOptions{
+		EnableSSL: g.EnableSSL,
+		CertFile:  g.CertFile,
+		KeyFile:   g.KeyFile,
+	})
+	if err != nil {
+		return fmt.Errorf("grpc %w", err)
+	}
+	if tlsConf != nil {
+		g.log.Info().Msg("enable grpc tls")
+		opts = append(opts,
+			grpc.Creds(credentials.NewTLS(tlsConf)),
+			// 客户端身份放到上下文中, 通过 ioc_tls.ClientIdentityFromContext 获取
+			grpc.ChainUnaryInterceptor(ioc_tls.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(ioc_tls.StreamServerInterceptor()),
+		)
+	}
+	g.svr = grpc.NewServer(opts...)
+
+	// 单端口模式下请求由 HTTP 服务转交, 不再单独监听端口
+	if g.isSinglePort() {
+		g.done = make(chan struct{})
+		ioc_http.Get().SetGrpcHandler(g.svr)
+	}
 	return nil
 }
 
+// isSinglePort 是否与 HTTP 服务共用端口
+func (g *Grpc) isSinglePort() bool {
+	return ioc_http.Get().SinglePort
+}
+
 func (g *Grpc) AddInterceptors(interceptors ...grpc.UnaryServerInterceptor) {
 	g.interceptors = append(g.interceptors, interceptors...)
 }
@@ -111,34 +153,80 @@ func (g *Grpc) ServerOpts() []grpc.ServerOption {
 		otelgrpc.NewServerHandler()
 		opts = append(opts, grpc.StatsHandler(otelgrpc.NewServerHandler()))
 	}
-	// 补充中间件
-	opts = append(opts, grpc.ChainUnaryInterceptor(g.Interceptors()...))
+	// 补充中间件, 最外层统计正在处理的请求数
+	interceptors := append([]grpc.UnaryServerInterceptor{g.unaryInFlight}, g.Interceptors()...)
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.ChainStreamInterceptor(g.streamInFlight),
+	)
 	return opts
 }
 
-func (g *Grpc) Start(ctx context.Context) {
-	// 启动GRPC服务
+func (g *Grpc) unaryInFlight(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	g.inflight.Add(1)
+	defer g.inflight.Add(-1)
+	return handler(ctx, req)
+}
+
+func (g *Grpc) streamInFlight(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	g.inflight.Add(1)
+	defer g.inflight.Add(-1)
+	return handler(srv, ss)
+}
+
+// InFlight 正在处理的请求数, 包括流式请求
+func (g *Grpc) InFlight() int64 {
+	return g.inflight.Load()
+}
+
+// Listen 同步绑定监听地址, 地址被占用等错误直接返回
+func (g *Grpc) Listen() error {
+	if g.listener != nil || g.isSinglePort() {
+		return nil
+	}
 	lis, err := net.Listen("tcp", g.Addr())
 	if err != nil {
+		return fmt.Errorf("listen grpc tcp conn error, %w", err)
+	}
+	g.listener = lis
+	return nil
+}
 
-		g.log.Error().Msgf("listen grpc tcp conn error, %s", err)
-		return
+// Start 启动服务, 未绑定监听时先绑定, 执行启动后勾子后阻塞直到服务关闭
+// 错误同时输出到日志, 兼容 go Start(ctx) 不处理返回值的用法
+func (g *Grpc) Start(ctx context.Context) (err error) {
+	defer func() {
+		if err != nil {
+			g.log.Error().Msgf("GRPC服务异常退出, %s", err)
+		}
+	}()
+
+	if err := g.Listen(); err != nil {
+		return err
 	}
 
 	// 启动后勾子
 	ctx = context.WithValue(ctx, ServiceInfoCtxKey{}, g.svr.GetServiceInfo())
 	if g.PostStart != nil {
 		if err := g.PostStart(ctx); err != nil {
-			g.log.Error().Msg(err.Error())
-			return
+			return err
 		}
 	}
 
+	if g.isSinglePort() {
+		g.log.Info().Msgf("GRPC 服务共用 HTTP 服务端口: %s", g.Addr())
+		<-g.done
+		return nil
+	}
 	g.log.Info().Msgf("GRPC 服务监听地址: %s", g.Addr())
+	return g.svr.Serve(g.listener)
+}
 
-	if err := g.svr.Serve(lis); err != nil {
-		g.log.Error().Msg(err.Error())
-	}
+// Serve 与 Start 相同
+//
+// Deprecated: 使用 Start, Start 现在阻塞运行并返回错误
+func (g *Grpc) Serve(ctx context.Context) error {
+	return g.Start(ctx)
 }
 
 func (g *Grpc) Stop(ctx context.Context) error {
@@ -149,6 +237,25 @@ func (g *Grpc) Stop(ctx context.Context) error {
 		}
 	}
 
-	g.svr.GracefulStop()
-	return nil
+	// 已绑定但未开始服务的监听需要单独关闭
+	if g.listener != nil {
+		defer g.listener.Close()
+	}
+	if g.done != nil {
+		defer close(g.done)
+	}
+
+	// 优雅关闭超时后强制关闭
+	done := make(chan struct{})
+	go func() {
+		g.svr.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		g.svr.Stop()
+		return fmt.Errorf("grpc graceful shutdown timeout, force exit")
+	}
 }
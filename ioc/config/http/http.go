@@ -2,11 +2,15 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	humanize "github.com/dustin/go-humanize"
@@ -15,6 +19,7 @@ import (
 	"github.com/infraboard/mcube/v2/ioc"
 	"github.com/infraboard/mcube/v2/ioc/config/application"
 	"github.com/infraboard/mcube/v2/ioc/config/log"
+	ioc_tls "github.com/infraboard/mcube/v2/ioc/config/tls"
 	"github.com/rs/zerolog"
 )
 
@@ -57,11 +62,33 @@ type Http struct {
 	// header最大大小
 	MaxHeaderSize string `json:"max_header_size" yaml:"max_header_size" toml:"max_header_size" env:"MAX_HEADER_SIZE"`
 
+	// 单独开启 TLS, 证书为空时使用共享的 [tls] 配置
+	EnableSSL bool   `json:"enable_ssl" yaml:"enable_ssl" toml:"enable_ssl" env:"ENABLE_SSL"`
+	CertFile  string `json:"cert_file" yaml:"cert_file" toml:"cert_file" env:"CERT_FILE"`
+	KeyFile   string `json:"key_file" yaml:"key_file" toml:"key_file" env:"KEY_FILE"`
+
+	// 开启 h2c, 不使用 TLS 时也支持 HTTP/2(prior knowledge)
+	H2C bool `json:"h2c" yaml:"h2c" toml:"h2c" env:"H2C"`
+	// 单端口模式, GRPC 和 JSON RPC 共用 HTTP 服务的端口, 按请求类型分发, 未开启 TLS 时自动开启 h2c
+	SinglePort bool `json:"single_port" yaml:"single_port" toml:"single_port" env:"SINGLE_PORT"`
+
 	// 解析后的数据
 	maxHeaderBytes uint64
 	log            *zerolog.Logger
 	router         http.Handler
 	server         *http.Server
+	listener       net.Listener
+	inflight       atomic.Int64
+
+	// 单端口模式下挂载的服务
+	mu          sync.RWMutex
+	grpcHandler http.Handler
+	mounts      []mount
+}
+
+type mount struct {
+	prefix  string
+	handler http.Handler
 }
 
 func (h *Http) HTTPPrefix() string {
@@ -91,7 +118,24 @@ func (h *Http) ApiObjectPathPrefix(obj ioc.Object) string {
 }
 
 func (h *Http) ApiObjectAddr(obj ioc.Object) string {
-	return fmt.Sprintf("http://%s%s", h.Addr(), h.ApiObjectPathPrefix(obj))
+	return fmt.Sprintf("%s://%s%s", h.Scheme(), h.Addr(), h.ApiObjectPathPrefix(obj))
+}
+
+// Scheme 开启 TLS 时为 https
+func (h *Http) Scheme() string {
+	if ioc_tls.Get().IsEnable(h.tlsOptions()) {
+		return "https"
+	}
+	return "http"
+}
+
+func (h *Http) tlsOptions() *ioc_tls.ServerOptions {
+	return &ioc_tls.ServerOptions{
+		EnableSSL:  h.EnableSSL,
+		CertFile:   h.CertFile,
+		KeyFile:    h.KeyFile,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
 }
 
 func (h *Http) Name() string {
@@ -119,11 +163,92 @@ func (h *Http) Init() error {
 		IdleTimeout:       time.Duration(h.IdleTimeoutSecond) * time.Second,
 		MaxHeaderBytes:    int(h.maxHeaderBytes),
 		Addr:              h.Addr(),
-		Handler:           h.router,
+	}
+	handler := h.router
+	if h.SinglePort {
+		handler = h.dispatch(h.router)
+	}
+	if handler != nil {
+		h.server.Handler = h.trackInFlight(handler)
+	}
+
+	tlsConf, err := ioc_tls.Get().ServerConfig(h.tlsOptions())
+	if err != nil {
+		return fmt.Errorf("http %w", err)
+	}
+	if tlsConf != nil {
+		h.server.TLSConfig = tlsConf
+		if h.server.Handler != nil {
+			h.server.Handler = ioc_tls.IdentityHandler(h.server.Handler)
+		}
+	}
+	if h.H2C || (h.SinglePort && tlsConf == nil) {
+		h.server.Protocols = new(http.Protocols)
+		h.server.Protocols.SetHTTP1(true)
+		h.server.Protocols.SetHTTP2(true)
+		h.server.Protocols.SetUnencryptedHTTP2(true)
 	}
 	return nil
 }
 
+// SetGrpcHandler 单端口模式下处理 GRPC 请求(HTTP/2 且 Content-Type 为 application/grpc)的服务
+func (h *Http) SetGrpcHandler(handler http.Handler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.grpcHandler = handler
+}
+
+// Mount 单端口模式下挂载路径前缀的服务, 比如 JSON RPC, 匹配的请求不再经过路由
+func (h *Http) Mount(prefix string, handler http.Handler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.mounts = append(h.mounts, mount{prefix: prefix, handler: handler})
+}
+
+// dispatch 单端口模式下按请求类型分发: GRPC 请求、挂载的服务, 其他请求交给路由
+func (h *Http) dispatch(router http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.mu.RLock()
+		grpcHandler, mounts := h.grpcHandler, h.mounts
+		h.mu.RUnlock()
+
+		if grpcHandler != nil && IsGrpcRequest(r) {
+			grpcHandler.ServeHTTP(w, r)
+			return
+		}
+		for _, m := range mounts {
+			if strings.HasPrefix(r.URL.Path, m.prefix) {
+				m.handler.ServeHTTP(w, r)
+				return
+			}
+		}
+		if router == nil {
+			http.NotFound(w, r)
+			return
+		}
+		router.ServeHTTP(w, r)
+	})
+}
+
+// IsGrpcRequest 是否为 GRPC 请求
+func IsGrpcRequest(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// trackInFlight 统计正在处理的请求数, 用于优雅关闭时观察排空进度
+func (h *Http) trackInFlight(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.inflight.Add(1)
+		defer h.inflight.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// InFlight 正在处理的请求数
+func (h *Http) InFlight() int64 {
+	return h.inflight.Load()
+}
+
 func (h *Http) Addr() string {
 	return fmt.Sprintf("%s:%d", h.Host, h.Port)
 }
@@ -134,25 +259,74 @@ func (h *Http) SetRouter(r http.Handler) {
 
 func (h *Http) IsEnable() bool {
 	if h.Enable == nil {
+		if h.SinglePort {
+			h.mu.RLock()
+			defer h.mu.RUnlock()
+			return h.router != nil || h.grpcHandler != nil || len(h.mounts) > 0
+		}
 		return h.router != nil
 	}
 
 	return *h.Enable
 }
 
-// Start 启动服务
-func (h *Http) Start(ctx context.Context) {
-	h.log.Info().Msgf("HTTP服务启动成功, 监听地址: %s", h.Addr())
-	if err := h.server.ListenAndServe(); err != nil {
-		h.log.Error().Msg(err.Error())
+// Listen 同步绑定监听地址, 地址被占用等错误直接返回
+func (h *Http) Listen() error {
+	if h.listener != nil {
+		return nil
+	}
+	lis, err := net.Listen("tcp", h.Addr())
+	if err != nil {
+		return fmt.Errorf("listen http %s error, %w", h.Addr(), err)
+	}
+	h.listener = lis
+	return nil
+}
+
+// Start 启动服务, 未绑定监听时先绑定, 阻塞直到服务关闭, 正常关闭时返回 nil
+// 错误同时输出到日志, 兼容 go Start(ctx) 不处理返回值的用法
+func (h *Http) Start(ctx context.Context) (err error) {
+	defer func() {
+		if err != nil {
+			h.log.Error().Msgf("HTTP服务异常退出, %s", err)
+		}
+	}()
+
+	if err := h.Listen(); err != nil {
+		return err
+	}
+	h.log.Info().Msgf("HTTP服务启动成功, 监听地址: %s://%s", h.Scheme(), h.Addr())
+	if h.SinglePort {
+		h.log.Info().Msg("单端口模式, GRPC 和 JSON RPC 共用 HTTP 服务端口")
+	}
+	serve := h.server.Serve
+	if h.server.TLSConfig != nil {
+		// 证书由 TLSConfig 提供
+		serve = func(l net.Listener) error { return h.server.ServeTLS(l, "", "") }
 	}
+	if err := serve(h.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Serve 与 Start 相同
+//
+// Deprecated: 使用 Start, Start 现在阻塞运行并返回错误
+func (h *Http) Serve(ctx context.Context) error {
+	return h.Start(ctx)
 }
 
 // Stop 停止server
 func (h *Http) Stop(ctx context.Context) error {
 	h.log.Info().Msg("start graceful shutdown")
 	// 优雅关闭HTTP服务
-	if err := h.server.Shutdown(ctx); err != nil {
+	err := h.server.Shutdown(ctx)
+	// 已绑定但未开始服务的监听需要单独关闭
+	if h.listener != nil {
+		h.listener.Close()
+	}
+	if err != nil {
 		return fmt.Errorf("http graceful shutdown timeout, force exit")
 	}
 	return nil
@@ -0,0 +1,113 @@
+package http_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	ioc_http "github.com/infraboard/mcube/v2/ioc/config/http"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func text(body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	})
+}
+
+func get(t *testing.T, client *http.Client, url string) (string, int) {
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body), resp.ProtoMajor
+}
+
+func TestSinglePort(t *testing.T) {
+	h := &ioc_http.Http{
+		Host:          "127.0.0.1",
+		Port:          freePort(t),
+		SinglePort:    true,
+		MaxHeaderSize: "16kb",
+	}
+	h.SetRouter(text("router"))
+
+	svr := grpc.NewServer()
+	healthpb.RegisterHealthServer(svr, health.NewServer())
+	h.SetGrpcHandler(svr)
+	if err := h.Init(); err != nil {
+		t.Fatal(err)
+	}
+	// JSON RPC 在 HTTP 服务初始化之后挂载
+	h.Mount("/jsonrpc/", text("jsonrpc"))
+	if !h.IsEnable() {
+		t.Fatal("single port http should be enabled")
+	}
+
+	if err := h.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	go h.Start(context.Background())
+	defer h.Stop(context.Background())
+
+	// GRPC 请求
+	conn, err := grpc.NewClient(h.Addr(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("want SERVING, got %s", resp.Status)
+	}
+
+	// HTTP/1.1 请求
+	base := "http://" + h.Addr()
+	if body, proto := get(t, http.DefaultClient, base+"/api/v1/x"); body != "router" || proto != 1 {
+		t.Fatalf("want router over HTTP/1, got %s over HTTP/%d", body, proto)
+	}
+	if body, _ := get(t, http.DefaultClient, base+"/jsonrpc/app/v1"); body != "jsonrpc" {
+		t.Fatalf("want jsonrpc, got %s", body)
+	}
+
+	// h2c 请求
+	tr := &http.Transport{Protocols: new(http.Protocols)}
+	tr.Protocols.SetUnencryptedHTTP2(true)
+	h2c := &http.Client{Transport: tr}
+	if body, proto := get(t, h2c, base+"/api/v1/x"); body != "router" || proto != 2 {
+		t.Fatalf("want router over HTTP/2, got %s over HTTP/%d", body, proto)
+	}
+}
+
+func TestSinglePortDisabled(t *testing.T) {
+	h := &ioc_http.Http{Host: "127.0.0.1", Port: freePort(t), MaxHeaderSize: "16kb"}
+	h.SetGrpcHandler(grpc.NewServer())
+	if err := h.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if h.IsEnable() {
+		t.Fatal("http without router should be disabled")
+	}
+}
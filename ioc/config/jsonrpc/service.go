@@ -2,16 +2,21 @@ package jsonrpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"reflect"
 	"sync"
+	"sync/atomic"
 
 	"github.com/emicklei/go-restful/v3"
 	"github.com/infraboard/mcube/v2/ioc"
 	"github.com/infraboard/mcube/v2/ioc/config/application"
+	ioc_http "github.com/infraboard/mcube/v2/ioc/config/http"
 	"github.com/infraboard/mcube/v2/ioc/config/log"
+	ioc_tls "github.com/infraboard/mcube/v2/ioc/config/tls"
 	"github.com/infraboard/mcube/v2/ioc/config/trace"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/emicklei/go-restful/otelrestful"
@@ -46,11 +51,14 @@ type JsonRpc struct {
 	ClientId     string `json:"client_id" yaml:"client_id" toml:"client_id" env:"CLIENT_ID"`
 	ClientSecret string `json:"client_secret" yaml:"client_secret" toml:"client_secret" env:"CLIENT_SECRET"`
 
+	// 单独开启 TLS, 证书为空时使用共享的 [tls] 配置
 	EnableSSL bool   `json:"enable_ssl" yaml:"enable_ssl" toml:"enable_ssl" env:"ENABLE_SSL"`
 	CertFile  string `json:"cert_file" yaml:"cert_file" toml:"cert_file" env:"CERT_FILE"`
 	KeyFile   string `json:"key_file" yaml:"key_file" toml:"key_file" env:"KEY_FILE"`
 
 	server    *http.Server
+	listener  net.Listener
+	inflight  atomic.Int64
 	Container *restful.Container
 	mu        sync.RWMutex
 	log       *zerolog.Logger
@@ -59,6 +67,9 @@ type JsonRpc struct {
 }
 
 func (h *JsonRpc) Addr() string {
+	if h.isSinglePort() {
+		return ioc_http.Get().Addr()
+	}
 	return fmt.Sprintf("%s:%d", h.Host, h.Port)
 }
 
@@ -79,27 +90,94 @@ func (h *JsonRpc) HTTPPrefix() string {
 }
 
 func (h *JsonRpc) RPCURL() string {
-	return fmt.Sprintf("http://%s%s", h.Addr(), h.HTTPPrefix())
+	if h.isSinglePort() {
+		return fmt.Sprintf("%s://%s%s", ioc_http.Get().Scheme(), h.Addr(), h.HTTPPrefix())
+	}
+	scheme := "http"
+	if ioc_tls.Get().IsEnable(h.tlsOptions()) {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, h.Addr(), h.HTTPPrefix())
+}
+
+func (h *JsonRpc) tlsOptions() *ioc_tls.ServerOptions {
+	return &ioc_tls.ServerOptions{
+		EnableSSL:  h.EnableSSL,
+		CertFile:   h.CertFile,
+		KeyFile:    h.KeyFile,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+}
+
+// Listen 同步绑定监听地址, 地址被占用等错误直接返回
+func (h *JsonRpc) Listen() error {
+	if h.listener != nil {
+		return nil
+	}
+	lis, err := net.Listen("tcp", h.Addr())
+	if err != nil {
+		return fmt.Errorf("listen jsonrpc %s error, %w", h.Addr(), err)
+	}
+	h.listener = lis
+	return nil
 }
 
-func (h *JsonRpc) Start(ctx context.Context) {
+// Start 启动服务, 未绑定监听时先绑定, 阻塞直到服务关闭, 正常关闭时返回 nil
+// 错误同时输出到日志, 兼容 go Start(ctx) 不处理返回值的用法
+func (h *JsonRpc) Start(ctx context.Context) (err error) {
+	defer func() {
+		if err != nil {
+			h.log.Error().Msgf("JSON RPC服务异常退出, %s", err)
+		}
+	}()
+
+	if err := h.Listen(); err != nil {
+		return err
+	}
 	h.log.Info().Msgf("JSON RPC服务启动成功, 监听地址: %s", h.RPCURL())
-	if err := h.server.ListenAndServe(); err != nil {
-		h.log.Error().Msg(err.Error())
+	serve := h.server.Serve
+	if h.server.TLSConfig != nil {
+		// 证书由 TLSConfig 提供
+		serve = func(l net.Listener) error { return h.server.ServeTLS(l, "", "") }
+	}
+	if err := serve(h.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
 	}
+	return nil
+}
+
+// Serve 与 Start 相同
+//
+// Deprecated: 使用 Start, Start 现在阻塞运行并返回错误
+func (h *JsonRpc) Serve(ctx context.Context) error {
+	return h.Start(ctx)
 }
 
 // Stop 停止server
 func (h *JsonRpc) Stop(ctx context.Context) error {
 	h.log.Info().Msg("start graceful shutdown")
 	// 优雅关闭HTTP服务
-	if err := h.server.Shutdown(ctx); err != nil {
+	err := h.server.Shutdown(ctx)
+	// 已绑定但未开始服务的监听需要单独关闭
+	if h.listener != nil {
+		h.listener.Close()
+	}
+	if err != nil {
 		return fmt.Errorf("http graceful shutdown timeout, force exit")
 	}
 	return nil
 }
 
+// isSinglePort 是否与 HTTP 服务共用端口
+func (h *JsonRpc) isSinglePort() bool {
+	return ioc_http.Get().SinglePort
+}
+
+// IsEnable 单端口模式下由 HTTP 服务处理请求, 不再单独启动服务
 func (h *JsonRpc) IsEnable() bool {
+	if h.isSinglePort() {
+		return false
+	}
 	if h.Enable == nil {
 		return len(h.methods) > 0
 	}
@@ -140,13 +218,42 @@ func (j *JsonRpc) Init() error {
 	// 添加到Root Container
 	RootRouter().Add(ws)
 
+	// 单端口模式下挂载到 HTTP 服务, TLS 以及访问统计由 HTTP 服务处理
+	if j.isSinglePort() {
+		ioc_http.Get().Mount(j.HTTPPrefix(), j.Container)
+		return nil
+	}
+
 	j.server = &http.Server{
 		Addr:    j.Addr(),
-		Handler: j.Container,
+		Handler: j.trackInFlight(j.Container),
+	}
+
+	tlsConf, err := ioc_tls.Get().ServerConfig(j.tlsOptions())
+	if err != nil {
+		return fmt.Errorf("jsonrpc %w", err)
+	}
+	if tlsConf != nil {
+		j.server.TLSConfig = tlsConf
+		j.server.Handler = ioc_tls.IdentityHandler(j.server.Handler)
 	}
 	return nil
 }
 
+// trackInFlight 统计正在处理的请求数, 用于优雅关闭时观察排空进度
+func (j *JsonRpc) trackInFlight(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		j.inflight.Add(1)
+		defer j.inflight.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// InFlight 正在处理的请求数
+func (j *JsonRpc) InFlight() int64 {
+	return j.inflight.Load()
+}
+
 // 打印所有注册的方法信息
 func (j *JsonRpc) PrintMethods() {
 	j.mu.RLock()
@@ -1,6 +1,8 @@
 package lock
 
 import (
+	"fmt"
+
 	"github.com/infraboard/mcube/v2/ioc"
 )
 
@@ -36,6 +38,8 @@ func (c *config) Init() error {
 		c.lf = NewRedisLockProvider()
 	case PROVIDER_GO_CACHE:
 		c.lf = NewGoCacheLockProvider()
+	default:
+		return fmt.Errorf("lock provider %s not supported, supported providers: %s, %s", c.PROVIDER, PROVIDER_GO_CACHE, PROVIDER_REDIS)
 	}
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/infraboard/mcube/v2/ioc"
@@ -127,6 +128,7 @@ type Config struct {
 
 	ioc.ObjectImpl
 	root    *zerolog.Logger
+	level   atomic.Int32
 	lock    sync.Mutex
 	loggers map[string]*zerolog.Logger
 }
@@ -184,7 +186,8 @@ func (m *Config) Init() error {
 	for k, v := range m.ExtraFileds {
 		root = root.Str(k, v)
 	}
-	m.SetRoot(root.Logger().Level(level))
+	m.level.Store(int32(level))
+	m.SetRoot(root.Logger())
 	return nil
 }
 
@@ -209,14 +212,34 @@ func (m *Config) CallerMarshalFunc(pc uintptr, file string, line int) string {
 	return file + ":" + strconv.Itoa(line)
 }
 
+// SetRoot 设置 root logger, 日志级别由 Level 配置控制, 热更新时同时作用于已经创建的子 logger
 func (m *Config) SetRoot(r zerolog.Logger) {
+	r = r.Hook(levelHook{level: &m.level})
 	m.root = &r
 }
 
+// levelHook 按照 root logger 的级别过滤日志, 级别可以原子地调整, 不影响进程内其他的 zerolog logger
+type levelHook struct {
+	level *atomic.Int32
+}
+
+func (h levelHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if level < zerolog.Level(h.level.Load()) {
+		e.Discard()
+	}
+}
+
 func (m *Config) Logger(name string) *zerolog.Logger {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
+	// 克隆到其他容器的对象没有注册时设置的内部状态
+	if m.root == nil {
+		m.root = &log.Logger
+	}
+	if m.loggers == nil {
+		m.loggers = map[string]*zerolog.Logger{}
+	}
 	if _, ok := m.loggers[name]; !ok {
 		l := m.root.With().Str(SUB_LOGGER_KEY, name).Logger()
 		m.loggers[name] = &l
@@ -244,3 +267,25 @@ func (m *Config) IsMyLogger(logger *zerolog.Logger) bool {
 
 	return false
 }
+
+// OnConfigChange 配置热更新, 目前只支持动态调整日志级别
+func (m *Config) OnConfigChange(old, new ioc.Object) error {
+	conf, ok := new.(*Config)
+	if !ok {
+		return nil
+	}
+
+	level, err := zerolog.ParseLevel(conf.Level)
+	if err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if conf.Level == m.Level {
+		return nil
+	}
+	m.Level = conf.Level
+	m.level.Store(int32(level))
+	return nil
+}
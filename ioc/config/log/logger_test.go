@@ -1,14 +1,17 @@
 package log_test
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"runtime/debug"
+	"strings"
 	"testing"
 
 	"github.com/BurntSushi/toml"
 	"github.com/infraboard/mcube/v2/ioc"
 	"github.com/infraboard/mcube/v2/ioc/config/log"
+	"github.com/rs/zerolog"
 )
 
 func TestGetClientGetter(t *testing.T) {
@@ -41,6 +44,28 @@ func TestAppEnv(t *testing.T) {
 	t.Log(log.Get().File.DirPath)
 }
 
+func TestOnConfigChange(t *testing.T) {
+	buf := &bytes.Buffer{}
+	conf := &log.Config{Level: "debug"}
+	conf.SetRoot(zerolog.New(buf))
+	l := conf.Logger("test")
+
+	if err := conf.OnConfigChange(nil, &log.Config{Level: "warn"}); err != nil {
+		t.Fatal(err)
+	}
+	l.Info().Msg("info")
+	l.Warn().Msg("warn")
+	if strings.Contains(buf.String(), "info") || !strings.Contains(buf.String(), "warn") {
+		t.Fatalf("expect warn level, got %s", buf)
+	}
+	if zerolog.GlobalLevel() != zerolog.TraceLevel {
+		t.Fatalf("global level should not be changed, got %s", zerolog.GlobalLevel())
+	}
+	if err := conf.OnConfigChange(nil, &log.Config{Level: "unknown"}); err == nil {
+		t.Fatal("expect error for invalid level")
+	}
+}
+
 func init() {
 	os.Setenv("LOG_LEVEL", "info")
 	os.Setenv("LOG_FILE_PATH", "/test")
@@ -12,12 +12,16 @@ func init() {
 }
 
 var defaultConfig = &config{
-	cron: cron.New(cron.WithChain(
+	cron: newCron(),
+}
+
+func newCron() *cron.Cron {
+	return cron.New(cron.WithChain(
 		cron.Recover(&LogWrapper{}),
 		cron.SkipIfStillRunning(&LogWrapper{}),
 	),
 		cron.WithLogger(&LogWrapper{}),
-	),
+	)
 }
 
 type config struct {
@@ -36,6 +40,10 @@ func (c *config) Priority() int {
 
 func (c *config) Init() error {
 	c.log = log.Sub(c.Name())
+	// 克隆到其他容器的对象没有注册时创建的 cron
+	if c.cron == nil {
+		c.cron = newCron()
+	}
 	c.cron.Start()
 	return nil
 }
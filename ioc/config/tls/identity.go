@@ -0,0 +1,110 @@
+package tls
+
+import (
+	"context"
+	cryptotls "crypto/tls"
+	"crypto/x509"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// ClientIdentity 双向认证时客户端证书中的身份信息
+type ClientIdentity struct {
+	CommonName     string   `json:"common_name"`
+	Organization   []string `json:"organization"`
+	DNSNames       []string `json:"dns_names"`
+	EmailAddresses []string `json:"email_addresses"`
+	// 比如 SPIFFE ID: spiffe://example.org/ns/default/sa/api
+	URIs         []string `json:"uris"`
+	SerialNumber string   `json:"serial_number"`
+	// 证书是否通过 CA 校验, client_auth 为 request/require 时不校验
+	Verified bool `json:"verified"`
+	// 客户端证书
+	Certificate *x509.Certificate `json:"-"`
+}
+
+// NewClientIdentity 从 TLS 连接状态中获取客户端身份, 客户端未提供证书时返回 nil
+func NewClientIdentity(state *cryptotls.ConnectionState) *ClientIdentity {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	cert := state.PeerCertificates[0]
+	id := &ClientIdentity{
+		CommonName:     cert.Subject.CommonName,
+		Organization:   cert.Subject.Organization,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+		SerialNumber:   cert.SerialNumber.String(),
+		Verified:       len(state.VerifiedChains) > 0,
+		Certificate:    cert,
+	}
+	for _, u := range cert.URIs {
+		id.URIs = append(id.URIs, u.String())
+	}
+	return id
+}
+
+type clientIdentityKey struct{}
+
+// WithClientIdentity 把客户端身份放到上下文中
+func WithClientIdentity(ctx context.Context, id *ClientIdentity) context.Context {
+	return context.WithValue(ctx, clientIdentityKey{}, id)
+}
+
+// ClientIdentityFromContext 获取客户端身份, 未开启双向认证或者客户端未提供证书时返回 nil
+func ClientIdentityFromContext(ctx context.Context) *ClientIdentity {
+	id, _ := ctx.Value(clientIdentityKey{}).(*ClientIdentity)
+	return id
+}
+
+// IdentityHandler 把 HTTP 请求的客户端身份放到请求上下文中
+func IdentityHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := NewClientIdentity(r.TLS); id != nil {
+			r = r.WithContext(WithClientIdentity(r.Context(), id))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// grpcIdentity 把 GRPC 请求的客户端身份放到上下文中
+func grpcIdentity(ctx context.Context) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return ctx
+	}
+	if id := NewClientIdentity(&info.State); id != nil {
+		return WithClientIdentity(ctx, id)
+	}
+	return ctx
+}
+
+// UnaryServerInterceptor 把客户端身份放到 GRPC 请求上下文中
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(grpcIdentity(ctx), req)
+	}
+}
+
+// StreamServerInterceptor 把客户端身份放到 GRPC 流式请求上下文中
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &identityStream{ServerStream: ss, ctx: grpcIdentity(ss.Context())})
+	}
+}
+
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,29 @@
+package tls
+
+import "github.com/infraboard/mcube/v2/ioc"
+
+const (
+	AppName = "tls"
+)
+
+// 客户端证书认证模式
+const (
+	// 不要求客户端证书
+	CLIENT_AUTH_NONE = "none"
+	// 请求客户端证书, 不要求必须提供, 不校验
+	CLIENT_AUTH_REQUEST = "request"
+	// 要求客户端提供证书, 不校验
+	CLIENT_AUTH_REQUIRE = "require"
+	// 客户端提供证书时校验
+	CLIENT_AUTH_VERIFY_IF_GIVEN = "verify_if_given"
+	// 要求客户端提供证书并校验(双向认证)
+	CLIENT_AUTH_REQUIRE_AND_VERIFY = "require_and_verify"
+)
+
+func Get() *TLS {
+	obj := ioc.Config().Get(AppName)
+	if obj == nil {
+		return defaultConfig
+	}
+	return obj.(*TLS)
+}
@@ -0,0 +1,105 @@
+package tls
+
+import (
+	cryptotls "crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// reloader 证书文件变化时重新加载证书
+type reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	interval time.Duration
+	log      *zerolog.Logger
+
+	mu      sync.RWMutex
+	cert    *cryptotls.Certificate
+	pool    *x509.CertPool
+	stamp   string
+	checked time.Time
+}
+
+// load 加载证书以及 CA 证书
+func (r *reloader) load() error {
+	stamp := r.fileStamp()
+	cert, err := cryptotls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load tls cert %s error, %w", r.certFile, err)
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("read tls ca %s error, %w", r.caFile, err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate found in tls ca %s", r.caFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert, r.pool, r.stamp, r.checked = &cert, pool, stamp, time.Now()
+	return nil
+}
+
+func (r *reloader) current() (*cryptotls.Certificate, *x509.CertPool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, r.pool
+}
+
+// reloadIfChanged 距离上次检查超过间隔时检查证书文件是否变化, 重新加载失败时继续使用之前的证书
+func (r *reloader) reloadIfChanged() {
+	if r.interval <= 0 {
+		return
+	}
+	r.mu.Lock()
+	if time.Since(r.checked) < r.interval {
+		r.mu.Unlock()
+		return
+	}
+	r.checked = time.Now()
+	changed := r.fileStamp() != r.stamp
+	r.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if err := r.load(); err != nil {
+		if r.log != nil {
+			r.log.Error().Msgf("reload tls cert error, keep using the previous one, %s", err)
+		}
+		return
+	}
+	if r.log != nil {
+		r.log.Info().Msgf("tls cert %s reloaded", r.certFile)
+	}
+}
+
+// fileStamp 证书文件的修改时间与大小
+func (r *reloader) fileStamp() string {
+	stamps := []string{}
+	for _, path := range []string{r.certFile, r.keyFile, r.caFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			stamps = append(stamps, "")
+			continue
+		}
+		stamps = append(stamps, fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size()))
+	}
+	return strings.Join(stamps, ",")
+}
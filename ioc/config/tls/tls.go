@@ -0,0 +1,218 @@
+package tls
+
+import (
+	cryptotls "crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/infraboard/mcube/v2/ioc"
+	"github.com/infraboard/mcube/v2/ioc/config/log"
+	"github.com/rs/zerolog"
+)
+
+func init() {
+	ioc.Config().Registry(defaultConfig)
+}
+
+var defaultConfig = &TLS{
+	ClientAuth:           CLIENT_AUTH_NONE,
+	MinVersion:           "1.2",
+	ReloadIntervalSecond: 10,
+}
+
+// TLS HTTP、GRPC、JSON RPC 服务共享的 TLS 配置
+type TLS struct {
+	ioc.ObjectImpl
+
+	// 所有服务开启 TLS, 也可以通过服务自己的 enable_ssl 单独开启
+	Enable bool `json:"enable" yaml:"enable" toml:"enable" env:"ENABLE"`
+	// 服务端证书, 服务配置了自己的证书时使用服务自己的证书
+	CertFile string `json:"cert_file" yaml:"cert_file" toml:"cert_file" env:"CERT_FILE"`
+	KeyFile  string `json:"key_file" yaml:"key_file" toml:"key_file" env:"KEY_FILE"`
+	// 校验客户端证书的 CA 证书, 开启双向认证时使用
+	CAFile string `json:"ca_file" yaml:"ca_file" toml:"ca_file" env:"CA_FILE"`
+	// 客户端证书认证模式: none/request/require/verify_if_given/require_and_verify
+	ClientAuth string `json:"client_auth" yaml:"client_auth" toml:"client_auth" env:"CLIENT_AUTH"`
+	// 最低 TLS 版本: 1.0/1.1/1.2/1.3
+	MinVersion string `json:"min_version" yaml:"min_version" toml:"min_version" env:"MIN_VERSION"`
+	// 加密套件, 比如: TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, 为空时使用 Go 默认的加密套件
+	CipherSuites []string `json:"cipher_suites" yaml:"cipher_suites" toml:"cipher_suites" env:"CIPHER_SUITES"`
+	// 证书文件变化检查间隔, 证书变化后新的连接使用新的证书, 小于等于0时不检查
+	ReloadIntervalSecond int `json:"reload_interval" yaml:"reload_interval" toml:"reload_interval" env:"RELOAD_INTERVAL"`
+
+	clientAuth   cryptotls.ClientAuthType
+	minVersion   uint16
+	cipherSuites []uint16
+	log          *zerolog.Logger
+}
+
+func (t *TLS) Name() string {
+	return AppName
+}
+
+// 在 HTTP、GRPC 等服务之前初始化
+func (t *TLS) Priority() int {
+	return 200
+}
+
+func (t *TLS) Init() error {
+	t.log = log.Sub(AppName)
+
+	clientAuth, err := ParseClientAuth(t.ClientAuth)
+	if err != nil {
+		return err
+	}
+	t.clientAuth = clientAuth
+
+	minVersion, err := ParseVersion(t.MinVersion)
+	if err != nil {
+		return err
+	}
+	t.minVersion = minVersion
+
+	t.cipherSuites, err = ParseCipherSuites(t.CipherSuites)
+	if err != nil {
+		return err
+	}
+
+	// 提前检查共享证书, 避免服务启动后才发现证书错误
+	if t.Enable {
+		if _, err := t.newReloader(t.CertFile, t.KeyFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServerOptions 服务自己的 TLS 配置
+type ServerOptions struct {
+	// 服务单独开启 TLS
+	EnableSSL bool
+	// 服务自己的证书, 为空时使用共享证书
+	CertFile string
+	KeyFile  string
+	// ALPN 协议, 比如 HTTP 服务为 h2, http/1.1
+	NextProtos []string
+}
+
+// IsEnable 服务是否开启 TLS
+func (t *TLS) IsEnable(opts *ServerOptions) bool {
+	return t.Enable || (opts != nil && opts.EnableSSL)
+}
+
+// ServerConfig 服务使用的 TLS 配置, 未开启 TLS 时返回 nil
+// 每次握手时检查证书以及 CA 证书是否变化, 变化后重新加载, 加载失败时继续使用之前的证书
+func (t *TLS) ServerConfig(opts *ServerOptions) (*cryptotls.Config, error) {
+	if !t.IsEnable(opts) {
+		return nil, nil
+	}
+	if opts == nil {
+		opts = &ServerOptions{}
+	}
+
+	certFile, keyFile := t.CertFile, t.KeyFile
+	if opts.CertFile != "" {
+		certFile, keyFile = opts.CertFile, opts.KeyFile
+	}
+	r, err := t.newReloader(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	build := func() *cryptotls.Config {
+		cert, pool := r.current()
+		return &cryptotls.Config{
+			Certificates: []cryptotls.Certificate{*cert},
+			ClientAuth:   t.clientAuth,
+			ClientCAs:    pool,
+			MinVersion:   t.minVersion,
+			CipherSuites: t.cipherSuites,
+			NextProtos:   opts.NextProtos,
+		}
+	}
+	conf := build()
+	conf.Certificates = nil
+	conf.GetCertificate = func(*cryptotls.ClientHelloInfo) (*cryptotls.Certificate, error) {
+		r.reloadIfChanged()
+		cert, _ := r.current()
+		return cert, nil
+	}
+	conf.GetConfigForClient = func(*cryptotls.ClientHelloInfo) (*cryptotls.Config, error) {
+		r.reloadIfChanged()
+		return build(), nil
+	}
+	return conf, nil
+}
+
+func (t *TLS) newReloader(certFile, keyFile string) (*reloader, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("tls enabled but cert_file or key_file not set")
+	}
+	if t.clientAuth >= cryptotls.VerifyClientCertIfGiven && t.CAFile == "" {
+		return nil, fmt.Errorf("client_auth %s requires ca_file", t.ClientAuth)
+	}
+	r := &reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   t.CAFile,
+		interval: time.Duration(t.ReloadIntervalSecond) * time.Second,
+		log:      t.log,
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ParseClientAuth 解析客户端证书认证模式, 为空时不要求客户端证书
+func ParseClientAuth(mode string) (cryptotls.ClientAuthType, error) {
+	switch mode {
+	case "", CLIENT_AUTH_NONE:
+		return cryptotls.NoClientCert, nil
+	case CLIENT_AUTH_REQUEST:
+		return cryptotls.RequestClientCert, nil
+	case CLIENT_AUTH_REQUIRE:
+		return cryptotls.RequireAnyClientCert, nil
+	case CLIENT_AUTH_VERIFY_IF_GIVEN:
+		return cryptotls.VerifyClientCertIfGiven, nil
+	case CLIENT_AUTH_REQUIRE_AND_VERIFY:
+		return cryptotls.RequireAndVerifyClientCert, nil
+	}
+	return 0, fmt.Errorf("unknown client_auth %q", mode)
+}
+
+// ParseVersion 解析 TLS 版本, 为空时为 1.2
+func ParseVersion(version string) (uint16, error) {
+	switch version {
+	case "1.0":
+		return cryptotls.VersionTLS10, nil
+	case "1.1":
+		return cryptotls.VersionTLS11, nil
+	case "", "1.2":
+		return cryptotls.VersionTLS12, nil
+	case "1.3":
+		return cryptotls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unknown tls version %q", version)
+}
+
+// ParseCipherSuites 根据名称解析加密套件
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	suites := map[string]uint16{}
+	for _, s := range append(cryptotls.CipherSuites(), cryptotls.InsecureCipherSuites()...) {
+		suites[s.Name] = s.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := suites[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
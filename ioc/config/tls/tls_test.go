@@ -0,0 +1,173 @@
+package tls_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	cryptotls "crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ioc_tls "github.com/infraboard/mcube/v2/ioc/config/tls"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// issue 签发证书, parent 为空时签发自签名的 CA 证书
+func issue(t *testing.T, cn string, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"mcube"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCert{cert: cert, key: key}
+}
+
+func (c *testCert) write(t *testing.T, certFile, keyFile string) {
+	t.Helper()
+	keyDer, _ := x509.MarshalECPrivateKey(c.key)
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if keyFile != "" {
+		if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func (c *testCert) tlsCert() cryptotls.Certificate {
+	return cryptotls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.pem")
+
+	ca := issue(t, "ca", nil)
+	ca.write(t, caFile, "")
+	issue(t, "server-1", ca).write(t, certFile, keyFile)
+	client := issue(t, "client-a", ca)
+
+	conf := &ioc_tls.TLS{
+		Enable:               true,
+		CertFile:             certFile,
+		KeyFile:              keyFile,
+		CAFile:               caFile,
+		ClientAuth:           ioc_tls.CLIENT_AUTH_REQUIRE_AND_VERIFY,
+		MinVersion:           "1.2",
+		ReloadIntervalSecond: 1,
+	}
+	if err := conf.Init(); err != nil {
+		t.Fatal(err)
+	}
+	tlsConf, err := conf.ServerConfig(&ioc_tls.ServerOptions{NextProtos: []string{"http/1.1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{
+		TLSConfig: tlsConf,
+		Handler: ioc_tls.IdentityHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := ioc_tls.ClientIdentityFromContext(r.Context())
+			if id == nil {
+				http.Error(w, "no identity", http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, "%s %v", id.CommonName, id.Verified)
+		})),
+	}
+	go srv.ServeTLS(lis, "", "")
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	get := func(certs ...cryptotls.Certificate) (string, string, error) {
+		c := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &cryptotls.Config{RootCAs: pool, Certificates: certs},
+		}}
+		resp, err := c.Get("https://" + lis.Addr().String())
+		if err != nil {
+			return "", "", err
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body), resp.TLS.PeerCertificates[0].Subject.CommonName, nil
+	}
+
+	body, serverCN, err := get(client.tlsCert())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "client-a true" || serverCN != "server-1" {
+		t.Fatalf("unexpected response %s from %s", body, serverCN)
+	}
+
+	if _, _, err := get(); err == nil {
+		t.Fatal("client without certificate should be rejected")
+	}
+
+	// 轮换证书后新的连接使用新的证书
+	time.Sleep(1100 * time.Millisecond)
+	issue(t, "server-2", ca).write(t, certFile, keyFile)
+	_, serverCN, err = get(client.tlsCert())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if serverCN != "server-2" {
+		t.Fatalf("cert should be reloaded, got %s", serverCN)
+	}
+}
+
+func TestParse(t *testing.T) {
+	if _, err := ioc_tls.ParseClientAuth("bad"); err == nil {
+		t.Fatal("should reject unknown client auth")
+	}
+	if _, err := ioc_tls.ParseVersion("1.4"); err == nil {
+		t.Fatal("should reject unknown version")
+	}
+	ids, err := ioc_tls.ParseCipherSuites([]string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"})
+	if err != nil || len(ids) != 1 || ids[0] != cryptotls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256 {
+		t.Fatalf("unexpected cipher suites %v, %v", ids, err)
+	}
+}
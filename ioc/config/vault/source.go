@@ -0,0 +1,129 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault-client-go"
+	"github.com/infraboard/mcube/v2/ioc"
+)
+
+// NewKVSource 从 Vault KV v2 秘密加载配置
+// 秘密的 key 为对象名称, 或者使用 "对象名称.字段" 的形式, 比如:
+//
+//	{"datasource.password": "xxx", "redis": {"password": "xxx"}}
+//
+// 使用示例:
+//
+//	ioc.LoadConfig().
+//	    FromFile("etc/application.toml").
+//	    FromSource(vault.NewKVSource("myapp/config")).
+//	    Load()
+func NewKVSource(path string) *KVSource {
+	return &KVSource{
+		Path: path,
+	}
+}
+
+// KVSource Vault KV v2 配置源
+// Vault 客户端使用 vault 配置(配置文件中的 [vault] 或者环境变量), 也可以通过 Client 指定
+type KVSource struct {
+	// 秘密路径(不含挂载点)
+	Path string
+	// KV 引擎挂载点, 为空时使用 kv_mount_path
+	MountPath string
+	// 不为空时, 整个秘密作为该对象的配置
+	Section string
+	// 检查配置变化的间隔, 为0时不监听配置变化
+	WatchInterval time.Duration
+	// 自定义 Vault 客户端
+	Client *vault.Client
+}
+
+func (s *KVSource) Name() string {
+	return fmt.Sprintf("vault://%s", s.Path)
+}
+
+func (s *KVSource) Read(ctx context.Context) ([]byte, string, error) {
+	client, err := s.client()
+	if err != nil {
+		return nil, "", err
+	}
+
+	mountPath := s.MountPath
+	if mountPath == "" {
+		mountPath = KvMountPath()
+	}
+	resp, err := client.Secrets.KvV2Read(ctx, s.Path, vault.WithMountPath(mountPath))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var data map[string]any
+	if s.Section != "" {
+		data = map[string]any{s.Section: resp.Data.Data}
+	} else {
+		data = expandKeys(resp.Data.Data)
+	}
+	content, err := json.Marshal(data)
+	if err != nil {
+		return nil, "", err
+	}
+	return content, ".json", nil
+}
+
+// Watch 按照 WatchInterval 轮询秘密变化
+func (s *KVSource) Watch(ctx context.Context, notify func()) error {
+	if s.WatchInterval <= 0 {
+		return nil
+	}
+	return ioc.PollConfigSource(ctx, s, s.WatchInterval, notify)
+}
+
+// client 配置源在对象初始化之前读取, 没有可用的客户端时提前初始化 vault
+func (s *KVSource) client() (*vault.Client, error) {
+	if s.Client != nil {
+		return s.Client, nil
+	}
+	v := Get()
+	if v.client == nil {
+		if v.Address == "" {
+			return nil, fmt.Errorf("vault address is empty")
+		}
+		if err := v.Init(); err != nil {
+			return nil, err
+		}
+	}
+	return v.client, nil
+}
+
+// expandKeys 将 "对象名称.字段" 形式的 key 展开为嵌套的配置
+func expandKeys(data map[string]any) map[string]any {
+	result := map[string]any{}
+	for k, v := range data {
+		parts := strings.Split(k, ".")
+		current := result
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := current[part].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				current[part] = next
+			}
+			current = next
+		}
+		last := parts[len(parts)-1]
+		if exist, ok := current[last].(map[string]any); ok {
+			if m, ok := v.(map[string]any); ok {
+				for mk, mv := range m {
+					exist[mk] = mv
+				}
+				continue
+			}
+		}
+		current[last] = v
+	}
+	return result
+}
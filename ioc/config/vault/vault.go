@@ -109,6 +109,11 @@ func (v *Vault) Priority() int {
 func (v *Vault) Init() error {
 	v.log = log.Sub(v.Name())
 
+	// 作为配置源(KVSource)使用时已经提前初始化
+	if v.client != nil {
+		return nil
+	}
+
 	if v.Address == "" {
 		v.log.Warn().Msg("vault address is empty, skipping initialization")
 		return nil
@@ -0,0 +1,98 @@
+package ioc
+
+// Container 独立的对象容器, 与 DefaultStore 互不影响
+// 可用于并行执行的测试, 或者在同一个进程中运行多个相互独立的应用实例
+// 容器中对象的自动注入、依赖查找以及初始化顺序都在该容器中进行
+// 注意: 对象内部通过包级函数(如 ioc.Config().Get)获取的依赖仍然来自 DefaultStore
+type Container = defaultStore
+
+// NewContainer 创建一个空的容器, 包含 Config/Controller/Default/Api 命名空间
+// 使用示例:
+//
+//	c := ioc.NewContainer()
+//	c.Controller().Registry(&UserService{})
+//	err := c.ConfigIocObject(ioc.NewLoadConfigRequest())
+//	defer c.Stop(ctx)
+func NewContainer() *Container {
+	c := &Container{}
+	c.Namespace(CONFIG_NAMESPACE).SetPriority(99)
+	c.Namespace(CONTROLLER_NAMESPACE).SetPriority(0)
+	c.Namespace(DEFAULT_NAMESPACE).SetPriority(9)
+	c.Namespace(API_NAMESPACE).SetPriority(-99).SetSequentialInit(true)
+	return c
+}
+
+// Clone 创建一个包含当前容器所有注册对象的新容器
+// 新容器中的对象为未加载配置的新对象: 配置字段为注册时的默认值, 未导出的内部状态为零值,
+// 非单例对象通过工厂创建新的模板, 由新容器加载配置并初始化, 不会与原容器中的对象共享状态
+// 因此对象需要在 Init 中(或者使用时)创建内部状态, 不能依赖注册时设置的未导出字段
+// 通过 Adapt 注册的值无法复制, 新容器与原容器共享该值, 新容器关闭时不会关闭该值
+// 使用示例:
+//
+//	c := ioc.DefaultStore.Clone()
+func (s *defaultStore) Clone() *Container {
+	c := &Container{
+		strictAutowire:  s.strictAutowire,
+		initParallelism: s.initParallelism,
+	}
+	for _, ns := range s.store {
+		target := c.Namespace(ns.Namespace).SetPriority(ns.Priority).SetSequentialInit(ns.sequentialInit)
+		ns.ForEach(func(w *ObjectWrapper) {
+			name, version, priority := w.Name, w.Version, w.Priority
+			scope, factory, conditions := w.Scope, w.factory, w.conditions
+			target.registry(w.newCloneObject(), func(cw *ObjectWrapper) {
+				cw.Name = name
+				cw.Version = version
+				cw.Priority = priority
+				cw.Scope = scope
+				cw.factory = factory
+				cw.conditions = conditions
+			})
+		})
+	}
+	return c
+}
+
+// objectCloner 无法通过反射创建新对象的对象(如构造函数、适配的值), 自己创建克隆到新容器的对象
+type objectCloner interface {
+	cloneObject() Object
+}
+
+// newCloneObject 创建克隆到新容器的对象
+func (w *ObjectWrapper) newCloneObject() Object {
+	if c, ok := w.Value.(objectCloner); ok {
+		return c.cloneObject()
+	}
+	if w.factory != nil {
+		return w.factory()
+	}
+	if obj := w.newDefaultObject(); obj != nil {
+		return obj
+	}
+	return w.Value
+}
+
+// Config 配置对象命名空间
+func (s *defaultStore) Config() StoreUser {
+	return s.Namespace(CONFIG_NAMESPACE)
+}
+
+// Controller 控制器对象命名空间
+func (s *defaultStore) Controller() StoreUser {
+	return s.Namespace(CONTROLLER_NAMESPACE)
+}
+
+// Default 默认对象命名空间
+func (s *defaultStore) Default() StoreUser {
+	return s.Namespace(DEFAULT_NAMESPACE)
+}
+
+// Api API 对象命名空间
+func (s *defaultStore) Api() StoreUser {
+	return s.Namespace(API_NAMESPACE)
+}
+
+// namespaceBinder 需要知道注册的命名空间的对象, 注册时调用
+type namespaceBinder interface {
+	bindNamespace(ns *NamespaceStore)
+}
@@ -82,6 +82,7 @@ type DependencyInfo struct {
 	Name      string // 依赖对象名称
 	Namespace string // 依赖所在命名空间
 	FieldName string // 字段名
+	Version   string // 依赖对象的版本选择器, 为空时表示最新版本
 }
 
 // extractDependencies 提取对象的依赖信息
@@ -122,6 +123,7 @@ func (s *NamespaceStore) extractDependencies(obj Object) []DependencyInfo {
 			depInfo := DependencyInfo{
 				FieldName: field.Name,
 				Namespace: tagInfo.Namespace,
+				Version:   tagInfo.VersionSelector(),
 			}
 
 			// 确定依赖对象的名称
@@ -142,12 +144,12 @@ func (s *NamespaceStore) extractDependencies(obj Object) []DependencyInfo {
 func (s *NamespaceStore) findDependencyObject(depInfo DependencyInfo) Object {
 	// 如果指定了namespace，从对应的namespace查找
 	if depInfo.Namespace != "" && depInfo.Namespace != s.Namespace {
-		targetNs := DefaultStore.Namespace(depInfo.Namespace)
-		return targetNs.Get(depInfo.Name)
+		targetNs := s.owner().Namespace(depInfo.Namespace)
+		return targetNs.Get(depInfo.Name, WithVersion(depInfo.Version))
 	}
 
 	// 否则在当前namespace查找
-	return s.Get(depInfo.Name)
+	return s.Get(depInfo.Name, WithVersion(depInfo.Version))
 }
 
 // PrintAllDependencies 打印所有命名空间的依赖关系
@@ -0,0 +1,145 @@
+package ioc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ObjectState 对象的生命周期状态
+type ObjectState string
+
+const (
+	// 已注册
+	OBJECT_REGISTERED ObjectState = "registered"
+	// 已加载配置
+	OBJECT_CONFIGURED ObjectState = "configured"
+	// 已初始化, PostInit 钩子失败时也处于该状态, 错误记录在状态变化中
+	OBJECT_INITIALIZED ObjectState = "initialized"
+	// 初始化失败(PreInit 或者 Init 返回错误)
+	OBJECT_INIT_FAILED ObjectState = "init_failed"
+	// 正在关闭
+	OBJECT_STOPPING ObjectState = "stopping"
+	// 已关闭, PreStop/PostStop 钩子失败或者超时时错误记录在状态变化中
+	OBJECT_STOPPED ObjectState = "stopped"
+	// 注册条件不满足, 错误记录不满足的条件
+	OBJECT_DISABLED ObjectState = "disabled"
+)
+
+// ObjectTransition 对象的一次状态变化
+type ObjectTransition struct {
+	State ObjectState
+	Time  time.Time
+	// 进入该状态时发生的错误
+	Err error
+}
+
+// ObjectEvent 对象状态变化事件
+type ObjectEvent struct {
+	Namespace string
+	Name      string
+	Version   string
+	Object    Object
+	// 变化前的状态, 注册事件为空
+	From  ObjectState
+	State ObjectState
+	Time  time.Time
+	// 上一个状态持续的时间, 比如 Initialized 事件为初始化耗时
+	Duration time.Duration
+	Err      error
+}
+
+// Id 对象标识, 格式为 namespace:name@version
+func (e ObjectEvent) Id() string {
+	return fmt.Sprintf("%s:%s@%s", e.Namespace, e.Name, e.Version)
+}
+
+// ObjectEventListener 对象状态变化监听函数
+// 并发初始化时会在多个 goroutine 中同时调用, 监听函数需要自己保证并发安全
+type ObjectEventListener func(ObjectEvent)
+
+// OnObjectEvent 监听默认容器中对象的状态变化
+// 监听注册之前已经发生的状态变化不会通知, 可以通过 ObjectWrapper.Transitions 查询
+func OnObjectEvent(fn ObjectEventListener) {
+	DefaultStore.OnObjectEvent(fn)
+}
+
+// OnObjectEvent 监听容器中对象的状态变化, 监听函数在状态变化的 goroutine 中同步执行
+func (s *defaultStore) OnObjectEvent(fn ObjectEventListener) {
+	s.listenerMu.Lock()
+	defer s.listenerMu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+func (s *defaultStore) emitObjectEvent(e ObjectEvent) {
+	s.listenerMu.RLock()
+	listeners := make([]ObjectEventListener, len(s.listeners))
+	copy(listeners, s.listeners)
+	s.listenerMu.RUnlock()
+
+	for _, fn := range listeners {
+		fn(e)
+	}
+}
+
+// objectState 对象的状态记录
+type objectState struct {
+	mu          sync.RWMutex
+	state       ObjectState
+	transitions []ObjectTransition
+}
+
+// State 对象当前的生命周期状态
+func (obj *ObjectWrapper) State() ObjectState {
+	obj.lifecycle.mu.RLock()
+	defer obj.lifecycle.mu.RUnlock()
+	return obj.lifecycle.state
+}
+
+// Transitions 对象所有的状态变化记录
+func (obj *ObjectWrapper) Transitions() []ObjectTransition {
+	obj.lifecycle.mu.RLock()
+	defer obj.lifecycle.mu.RUnlock()
+	transitions := make([]ObjectTransition, len(obj.lifecycle.transitions))
+	copy(transitions, obj.lifecycle.transitions)
+	return transitions
+}
+
+// Err 最近一次状态变化时发生的错误
+func (obj *ObjectWrapper) Err() error {
+	obj.lifecycle.mu.RLock()
+	defer obj.lifecycle.mu.RUnlock()
+	if n := len(obj.lifecycle.transitions); n > 0 {
+		return obj.lifecycle.transitions[n-1].Err
+	}
+	return nil
+}
+
+// setState 记录状态变化, 并通知对象所在容器的监听函数
+func (obj *ObjectWrapper) setState(state ObjectState, err error) {
+	now := time.Now()
+	obj.lifecycle.mu.Lock()
+	from := obj.lifecycle.state
+	var duration time.Duration
+	if n := len(obj.lifecycle.transitions); n > 0 {
+		duration = now.Sub(obj.lifecycle.transitions[n-1].Time)
+	}
+	obj.lifecycle.state = state
+	obj.lifecycle.transitions = append(obj.lifecycle.transitions, ObjectTransition{State: state, Time: now, Err: err})
+	obj.lifecycle.mu.Unlock()
+
+	if obj.ns == nil {
+		return
+	}
+	obj.ns.owner().emitObjectEvent(ObjectEvent{
+		Namespace: obj.ns.Namespace,
+		Name:      obj.Name,
+		Version:   obj.Version,
+		Object:    obj.Value,
+		From:      from,
+		State:     state,
+		Time:      now,
+		Duration:  duration,
+		Err:       err,
+	})
+}
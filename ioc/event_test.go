@@ -0,0 +1,97 @@
+package ioc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// EventTestObject 用于测试状态变化的对象
+type EventTestObject struct {
+	ObjectImpl
+	name        string
+	initErr     error
+	postInitErr error
+}
+
+func (o *EventTestObject) Name() string { return o.name }
+
+func (o *EventTestObject) Init() error { return o.initErr }
+
+func (o *EventTestObject) OnPostInit() error { return o.postInitErr }
+
+func TestObjectEvent(t *testing.T) {
+	c := NewContainer()
+
+	var (
+		mu     sync.Mutex
+		events = []string{}
+	)
+	c.OnObjectEvent(func(e ObjectEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e.Name+":"+string(e.From)+"->"+string(e.State))
+	})
+
+	ok := &EventTestObject{name: "ok"}
+	postInitFailed := &EventTestObject{name: "post_init_failed", postInitErr: errors.New("post init")}
+	c.Default().Registry(ok).Registry(postInitFailed)
+	if err := c.LoadConfig(NewLoadConfigRequest()); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.InitIocObject(); err != nil {
+		t.Fatal(err)
+	}
+	c.Stop(context.Background())
+
+	want := []string{
+		"ok:->registered",
+		"post_init_failed:->registered",
+		"ok:registered->configured",
+		"post_init_failed:registered->configured",
+		"ok:configured->initialized",
+		"post_init_failed:configured->initialized",
+		"post_init_failed:initialized->stopping",
+		"post_init_failed:stopping->stopped",
+		"ok:initialized->stopping",
+		"ok:stopping->stopped",
+	}
+	if strings.Join(events, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected events %v", events)
+	}
+
+	w := c.Namespace(DEFAULT_NAMESPACE).versions("post_init_failed")[0]
+	transitions := w.Transitions()
+	if len(transitions) != 5 {
+		t.Fatalf("unexpected transitions %v", transitions)
+	}
+	if transitions[2].State != OBJECT_INITIALIZED || transitions[2].Err == nil {
+		t.Fatalf("PostInit error should be recorded, %v", transitions[2])
+	}
+	if w.State() != OBJECT_STOPPED {
+		t.Fatalf("unexpected state %s", w.State())
+	}
+}
+
+func TestObjectEventInitFailed(t *testing.T) {
+	c := NewContainer()
+	failed := []ObjectEvent{}
+	c.OnObjectEvent(func(e ObjectEvent) {
+		if e.State == OBJECT_INIT_FAILED {
+			failed = append(failed, e)
+		}
+	})
+
+	c.Default().Registry(&EventTestObject{name: "failed", initErr: errors.New("boom")})
+	if err := c.InitIocObject(); err == nil {
+		t.Fatal("init should fail")
+	}
+	if len(failed) != 1 || failed[0].Id() != "default:failed@v1" || failed[0].Err == nil {
+		t.Fatalf("unexpected events %v", failed)
+	}
+	if objects := c.Objects(); objects[0].State != string(OBJECT_INIT_FAILED) || objects[0].Error == "" {
+		t.Fatalf("unexpected object info %v", objects[0])
+	}
+}
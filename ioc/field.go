@@ -0,0 +1,112 @@
+package ioc
+
+import (
+	"reflect"
+	"strings"
+)
+
+// isConfigField 是否为配置字段, 与加载配置以及生成配置结构(configFields)的规则一致:
+// 忽略未导出字段、注入的依赖(ioc 标签)、函数、通道、接口类型的字段以及配置标签为 "-" 的字段
+// 嵌入的结构体由调用方展开
+func isConfigField(sf reflect.StructField) bool {
+	if !sf.IsExported() {
+		return false
+	}
+	if _, ok := sf.Tag.Lookup("ioc"); ok {
+		return false
+	}
+	switch sf.Type.Kind() {
+	case reflect.Func, reflect.Chan, reflect.Interface, reflect.UnsafePointer:
+		return false
+	}
+	for _, tagName := range []string{"toml", "yaml", "json"} {
+		if strings.Split(sf.Tag.Get(tagName), ",")[0] == "-" {
+			return false
+		}
+	}
+	return true
+}
+
+// newConfigObject 创建与 obj 类型相同的新对象, 只深拷贝配置字段,
+// 新对象不与 obj 共享 map、切片以及指针, 也不会复制锁等内部状态
+// obj 不是结构体指针时返回 nil
+func newConfigObject(obj Object) Object {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	c := reflect.New(v.Elem().Type())
+	copyConfig(c.Elem(), v.Elem())
+	return c.Interface().(Object)
+}
+
+// copyConfig 深拷贝结构体 src 的配置字段到 dst
+func copyConfig(dst, src reflect.Value) {
+	t := src.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			copyConfig(dst.Field(i), src.Field(i))
+			continue
+		}
+		if !isConfigField(sf) || !dst.Field(i).CanSet() {
+			continue
+		}
+		dst.Field(i).Set(copyValue(src.Field(i)))
+	}
+}
+
+// copyValue 深拷贝配置的值, 结构体只复制配置字段
+func copyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(copyValue(v.Elem()))
+		return c
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		copyConfig(c, v)
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i)))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), copyValue(iter.Value()))
+		}
+		return c
+	case reflect.Interface:
+		// map[string]any 等集合中的值
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(copyValue(v.Elem()))
+		return c
+	default:
+		return v
+	}
+}
@@ -0,0 +1,226 @@
+package ioc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// graphNode 依赖图中的节点
+type graphNode struct {
+	ns *NamespaceStore
+	w  *ObjectWrapper
+	// 节点的注册顺序, 作为最后的排序依据, 保证排序结果稳定
+	order int
+	// 当前节点依赖的节点
+	deps []*graphNode
+}
+
+func (n *graphNode) String() string {
+	return fmt.Sprintf("%s:%s@%s", n.ns.Namespace, n.w.Name, n.w.Version)
+}
+
+// before 两个节点都没有未满足的依赖时, 决定谁先初始化
+// 依次比较: 命名空间优先级 -> 对象优先级 -> 注册顺序
+func (n *graphNode) before(o *graphNode) bool {
+	if n.ns.Priority != o.ns.Priority {
+		return n.ns.Priority > o.ns.Priority
+	}
+	if n.w.Priority != o.w.Priority {
+		return n.w.Priority > o.w.Priority
+	}
+	return n.order < o.order
+}
+
+// CircularDependencyError 循环依赖错误, Path 为完整的依赖环路径
+type CircularDependencyError struct {
+	Path []string
+}
+
+func (e *CircularDependencyError) Error() string {
+	return fmt.Sprintf("circular dependency detected: %s", strings.Join(e.Path, " -> "))
+}
+
+// dependencyGraph 根据 ioc 标签与 DependencyDeclarer 构建的依赖图
+type dependencyGraph struct {
+	nodes []*graphNode
+	index map[*ObjectWrapper]*graphNode
+}
+
+// newDependencyGraph 构建多个命名空间的依赖图
+// 只有单例对象参与排序, 不在 namespaces 中的依赖对象不会产生依赖边
+func newDependencyGraph(store *defaultStore, namespaces ...*NamespaceStore) *dependencyGraph {
+	g := &dependencyGraph{
+		index: map[*ObjectWrapper]*graphNode{},
+	}
+	for _, ns := range namespaces {
+		for _, w := range ns.activeItems() {
+			if !w.IsSingleton() {
+				continue
+			}
+			node := &graphNode{ns: ns, w: w, order: len(g.nodes)}
+			g.nodes = append(g.nodes, node)
+			g.index[w] = node
+		}
+	}
+
+	for _, node := range g.nodes {
+		for _, dep := range node.ns.dependencyWrappers(store, node.w) {
+			target, ok := g.index[dep]
+			if !ok || target == node {
+				continue
+			}
+			node.deps = append(node.deps, target)
+		}
+	}
+	return g
+}
+
+// Sort 拓扑排序, 被依赖的对象排在前面, 没有依赖关系的对象按优先级排序
+func (g *dependencyGraph) Sort() ([]*graphNode, error) {
+	pending := map[*graphNode]int{}
+	dependents := map[*graphNode][]*graphNode{}
+	for _, node := range g.nodes {
+		pending[node] = len(node.deps)
+		for _, dep := range node.deps {
+			dependents[dep] = append(dependents[dep], node)
+		}
+	}
+
+	ready := []*graphNode{}
+	for _, node := range g.nodes {
+		if pending[node] == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	sorted := make([]*graphNode, 0, len(g.nodes))
+	for len(ready) > 0 {
+		// 选出优先级最高的就绪节点
+		best := 0
+		for i := range ready {
+			if ready[i].before(ready[best]) {
+				best = i
+			}
+		}
+		node := ready[best]
+		ready = append(ready[:best], ready[best+1:]...)
+		sorted = append(sorted, node)
+
+		for _, dependent := range dependents[node] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(sorted) < len(g.nodes) {
+		return nil, g.findCycle(pending)
+	}
+	return sorted, nil
+}
+
+// findCycle 在未能排序的节点中查找依赖环
+func (g *dependencyGraph) findCycle(pending map[*graphNode]int) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[*graphNode]int{}
+	stack := []*graphNode{}
+
+	var visit func(node *graphNode) []*graphNode
+	visit = func(node *graphNode) []*graphNode {
+		state[node] = visiting
+		stack = append(stack, node)
+		for _, dep := range node.deps {
+			switch state[dep] {
+			case visiting:
+				for i := range stack {
+					if stack[i] == dep {
+						return append(append([]*graphNode{}, stack[i:]...), dep)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[node] = visited
+		return nil
+	}
+
+	for _, node := range g.nodes {
+		if pending[node] == 0 || state[node] != unvisited {
+			continue
+		}
+		if cycle := visit(node); cycle != nil {
+			path := make([]string, 0, len(cycle))
+			for _, n := range cycle {
+				path = append(path, n.String())
+			}
+			return &CircularDependencyError{Path: path}
+		}
+	}
+	return &CircularDependencyError{}
+}
+
+// dependencyWrappers 解析对象依赖的其他对象
+// 与 Autowire 使用相同的规则查找 ioc 标签声明的依赖, 同时包含 DependencyDeclarer 声明的依赖
+func (s *NamespaceStore) dependencyWrappers(store *defaultStore, w *ObjectWrapper) []*ObjectWrapper {
+	var deps []*ObjectWrapper
+
+	if declarer, ok := w.Value.(DependencyDeclarer); ok {
+		for _, info := range declarer.DeclareDependencies() {
+			ns := s
+			if info.Namespace != "" {
+				ns = store.Namespace(info.Namespace)
+			}
+			if dep, _ := ResolveVersion(ns.versions(info.Name), info.Version); dep != nil {
+				deps = append(deps, dep)
+			}
+		}
+	}
+
+	v := reflect.ValueOf(w.Value)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return deps
+	}
+	t := v.Elem().Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldTag := field.Tag.Get("ioc")
+		if fieldTag == "" {
+			continue
+		}
+		tag, err := ParseInjectTagWithError(fieldTag)
+		if err != nil || !tag.Autowire {
+			continue
+		}
+
+		ns := store.Namespace(tag.Namespace)
+		name := tag.Name
+		if field.Type.Kind() == reflect.Slice || field.Type.Kind() == reflect.Map {
+			deps = append(deps, ns.collectionWrappers(field.Type.Elem(), tag)...)
+			continue
+		}
+		if field.Type.Kind() == reflect.Interface && name == "" {
+			items := ns.implementWrappers(field.Type, tag.VersionSelector())
+			if len(items) > 0 {
+				deps = append(deps, items[0])
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Type.String()
+		}
+		if dep, _ := ResolveVersion(ns.versions(name), tag.VersionSelector()); dep != nil {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
@@ -0,0 +1,136 @@
+package ioc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// GraphTestObject 用于依赖图测试的对象
+type GraphTestObject struct {
+	ObjectImpl
+	name     string
+	priority int
+	deps     []DependencyInfo
+	inited   *[]string
+}
+
+func (o *GraphTestObject) Name() string  { return o.name }
+func (o *GraphTestObject) Priority() int { return o.priority }
+
+func (o *GraphTestObject) DeclareDependencies() []DependencyInfo {
+	return o.deps
+}
+
+func (o *GraphTestObject) Init() error {
+	*o.inited = append(*o.inited, o.name)
+	return nil
+}
+
+// GraphTagObject 通过 ioc 标签声明依赖的对象
+type GraphTagObject struct {
+	ObjectImpl
+	Repo   *GraphTestObject `ioc:"autowire=true;namespace=graph_default;name=repo"`
+	inited *[]string
+}
+
+func (o *GraphTagObject) Name() string { return "tag-consumer" }
+
+func (o *GraphTagObject) Init() error {
+	*o.inited = append(*o.inited, o.Name())
+	return nil
+}
+
+func newGraphTestStore() *defaultStore {
+	return &defaultStore{
+		store: []*NamespaceStore{
+			newNamespaceStore("graph_config").SetPriority(99),
+			newNamespaceStore("graph_default").SetPriority(9),
+			newNamespaceStore("graph_api").SetPriority(-99),
+		},
+	}
+}
+
+func TestInitOrderFollowsDependencies(t *testing.T) {
+	inited := []string{}
+	store := newGraphTestStore()
+
+	// config 命名空间优先级最高, 但依赖了 default 命名空间中的对象
+	store.Namespace("graph_config").Registry(&GraphTestObject{
+		name:     "config",
+		priority: 100,
+		deps:     []DependencyInfo{{Name: "repo", Namespace: "graph_default"}},
+		inited:   &inited,
+	})
+	store.Namespace("graph_default").Registry(&GraphTestObject{name: "repo", priority: 1, inited: &inited})
+	store.Namespace("graph_default").Registry(&GraphTestObject{name: "cache", priority: 50, inited: &inited})
+	store.Namespace("graph_api").Registry(&GraphTestObject{name: "api", inited: &inited})
+
+	if err := store.InitIocObject(); err != nil {
+		t.Fatal(err)
+	}
+
+	// repo 先于 config 初始化; 其余对象之间没有依赖关系, 仍然按优先级排序
+	want := []string{"cache", "repo", "config", "api"}
+	if strings.Join(inited, ",") != strings.Join(want, ",") {
+		t.Fatalf("init order: got %v, want %v", inited, want)
+	}
+
+	order := store.InitOrder()
+	if order[1] != "graph_default:repo@v1" {
+		t.Fatalf("unexpected init order %v", order)
+	}
+}
+
+func TestInitOrderFromTags(t *testing.T) {
+	inited := []string{}
+	store := newGraphTestStore()
+
+	// 使用 DefaultStore 之外的 store 时, 标签中的命名空间从该 store 中查找
+	store.Namespace("graph_default").Registry(&GraphTagObject{inited: &inited})
+	store.Namespace("graph_default").Registry(&GraphTestObject{name: "repo", priority: -10, inited: &inited})
+
+	if err := store.InitIocObject(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"repo", "tag-consumer"}
+	if strings.Join(inited, ",") != strings.Join(want, ",") {
+		t.Fatalf("init order: got %v, want %v", inited, want)
+	}
+}
+
+func TestInitCircularDependency(t *testing.T) {
+	inited := []string{}
+	store := newGraphTestStore()
+
+	store.Namespace("graph_config").Registry(&GraphTestObject{
+		name:   "a",
+		deps:   []DependencyInfo{{Name: "b", Namespace: "graph_default"}},
+		inited: &inited,
+	})
+	store.Namespace("graph_default").Registry(&GraphTestObject{
+		name:   "b",
+		deps:   []DependencyInfo{{Name: "c", Namespace: "graph_api"}},
+		inited: &inited,
+	})
+	store.Namespace("graph_api").Registry(&GraphTestObject{
+		name:   "c",
+		deps:   []DependencyInfo{{Name: "a", Namespace: "graph_config"}},
+		inited: &inited,
+	})
+
+	err := store.InitIocObject()
+	var cycleErr *CircularDependencyError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("expect CircularDependencyError, got %v", err)
+	}
+
+	want := "graph_config:a@v1 -> graph_default:b@v1 -> graph_api:c@v1 -> graph_config:a@v1"
+	if strings.Join(cycleErr.Path, " -> ") != want {
+		t.Fatalf("cycle path: got %s, want %s", strings.Join(cycleErr.Path, " -> "), want)
+	}
+	if len(inited) != 0 {
+		t.Fatalf("no object should be initialized when a cycle exists, got %v", inited)
+	}
+}
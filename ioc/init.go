@@ -0,0 +1,163 @@
+package ioc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// 优先级不低于该值的对象(application/trace/log 等基础组件)总是按顺序初始化,
+	// 低于该值的对象并发初始化时按 INIT_PRIORITY_BAND 分层
+	SEQUENTIAL_INIT_PRIORITY = 900
+	// 并发初始化时优先级的分层宽度, 优先级向下取整到该值的倍数后相同的对象属于同一层,
+	// 如 699 与 600 同层, -1 与 -100 同层, 不同层之间按优先级顺序初始化
+	INIT_PRIORITY_BAND = 100
+)
+
+// InitStat 对象初始化耗时
+type InitStat struct {
+	// 对象, 格式为 namespace:name@version
+	Object string
+	// PreInit + Init + PostInit 的耗时
+	Duration time.Duration
+}
+
+func (s InitStat) String() string {
+	return fmt.Sprintf("%s %s", s.Object, s.Duration.Round(time.Microsecond))
+}
+
+// SetInitParallelism 设置初始化对象的并发数, 小于等于1时按顺序初始化
+// 并发初始化时, 没有依赖关系(ioc 标签以及 DependencyDeclarer 声明)的对象同时初始化,
+// 优先级按 INIT_PRIORITY_BAND 分层(如 699 与 600 同层), 上一层的对象全部初始化完成后才开始初始化下一层
+func (s *defaultStore) SetInitParallelism(n int) *defaultStore {
+	s.initParallelism = n
+	return s
+}
+
+// InitParallelism 初始化对象的并发数
+func (s *defaultStore) InitParallelism() int {
+	return s.initParallelism
+}
+
+// InitStats 返回对象的初始化耗时, 按初始化完成的顺序排列
+func (s *defaultStore) InitStats() []InitStat {
+	stats := make([]InitStat, 0, len(s.initOrder))
+	for _, node := range s.initOrder {
+		stats = append(stats, InitStat{Object: node.String(), Duration: node.w.initDuration})
+	}
+	return stats
+}
+
+// initStageKey 并发初始化的分层依据, 相同分层且没有依赖关系的对象可以并发初始化
+type initStageKey struct {
+	ns   *NamespaceStore
+	band int
+	// 按顺序初始化的对象单独分层
+	node *graphNode
+}
+
+func (n *graphNode) initStageKey() initStageKey {
+	if n.ns.sequentialInit || n.w.Priority >= SEQUENTIAL_INIT_PRIORITY {
+		return initStageKey{node: n}
+	}
+	// 向下取整, 负数优先级不与 0 同层
+	band := n.w.Priority / INIT_PRIORITY_BAND
+	if n.w.Priority < 0 && n.w.Priority%INIT_PRIORITY_BAND != 0 {
+		band--
+	}
+	return initStageKey{ns: n.ns, band: band}
+}
+
+// initStages 按排序结果切分初始化分层, 分层的键变化时开始新的一层
+func initStages(order []*graphNode) [][]*graphNode {
+	stages := [][]*graphNode{}
+	for i, node := range order {
+		if i == 0 || node.initStageKey() != order[i-1].initStageKey() {
+			stages = append(stages, []*graphNode{})
+		}
+		stages[len(stages)-1] = append(stages[len(stages)-1], node)
+	}
+	return stages
+}
+
+// initNode 初始化节点对象并记录耗时
+func (s *defaultStore) initNode(node *graphNode) error {
+	err := node.w.init()
+	if err != nil {
+		return fmt.Errorf("[%s] %s", node.ns.Namespace, err)
+	}
+	debug("[IOC] init %s cost %s", node, node.w.initDuration)
+	return nil
+}
+
+// initSequential 按排序结果依次初始化
+func (s *defaultStore) initSequential(order []*graphNode) error {
+	for _, node := range order {
+		if err := s.initNode(node); err != nil {
+			return err
+		}
+		s.initOrder = append(s.initOrder, node)
+	}
+	return nil
+}
+
+// initConcurrent 逐层初始化, 同一层中依赖已就绪的对象并发初始化, 所有错误合并后返回
+func (s *defaultStore) initConcurrent(order []*graphNode) error {
+	sem := make(chan struct{}, s.initParallelism)
+	for _, stage := range initStages(order) {
+		var (
+			wg   sync.WaitGroup
+			mu   sync.Mutex
+			errs []error
+			done = make(map[*graphNode]chan struct{}, len(stage))
+			ok   = make(map[*graphNode]bool, len(stage))
+		)
+		for _, node := range stage {
+			done[node] = make(chan struct{})
+		}
+
+		for _, node := range stage {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer close(done[node])
+
+				// 等待同一层中的依赖初始化完成, 依赖初始化失败时跳过
+				for _, dep := range node.deps {
+					ch, inStage := done[dep]
+					if !inStage {
+						continue
+					}
+					<-ch
+					mu.Lock()
+					depOK := ok[dep]
+					mu.Unlock()
+					if !depOK {
+						return
+					}
+				}
+
+				sem <- struct{}{}
+				err := s.initNode(node)
+				<-sem
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					errs = append(errs, err)
+					return
+				}
+				ok[node] = true
+				s.initOrder = append(s.initOrder, node)
+			}()
+		}
+		wg.Wait()
+
+		if len(errs) > 0 {
+			return errors.Join(errs...)
+		}
+	}
+	return nil
+}
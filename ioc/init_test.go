@@ -0,0 +1,132 @@
+package ioc
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// InitTestObject 初始化时阻塞指定时间的对象
+type InitTestObject struct {
+	ObjectImpl
+	name     string
+	priority int
+	deps     []DependencyInfo
+	err      error
+
+	mu     *sync.Mutex
+	events *[]string
+}
+
+func (o *InitTestObject) Name() string  { return o.name }
+func (o *InitTestObject) Priority() int { return o.priority }
+
+func (o *InitTestObject) DeclareDependencies() []DependencyInfo {
+	return o.deps
+}
+
+func (o *InitTestObject) Init() error {
+	o.record("start " + o.name)
+	time.Sleep(50 * time.Millisecond)
+	o.record("end " + o.name)
+	return o.err
+}
+
+func (o *InitTestObject) record(e string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	*o.events = append(*o.events, e)
+}
+
+func newInitTestStore(parallelism int) (*defaultStore, func(name string, priority int, deps ...string) *InitTestObject, *[]string) {
+	store := &defaultStore{
+		store: []*NamespaceStore{
+			newNamespaceStore("init_config").SetPriority(99),
+			newNamespaceStore("init_api").SetPriority(-99).SetSequentialInit(true),
+		},
+	}
+	store.SetInitParallelism(parallelism)
+
+	mu, events := &sync.Mutex{}, []string{}
+	newObj := func(name string, priority int, deps ...string) *InitTestObject {
+		obj := &InitTestObject{name: name, priority: priority, mu: mu, events: &events}
+		for _, dep := range deps {
+			obj.deps = append(obj.deps, DependencyInfo{Name: dep})
+		}
+		return obj
+	}
+	return store, newObj, &events
+}
+
+func indexOf(events []string, e string) int {
+	for i := range events {
+		if events[i] == e {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestInitConcurrent(t *testing.T) {
+	store, newObj, events := newInitTestStore(4)
+	store.Namespace("init_config").
+		Registry(newObj("log", 997)).
+		Registry(newObj("datasource", 699)).
+		Registry(newObj("mongo", 698)).
+		Registry(newObj("redis", 697)).
+		Registry(newObj("cache", 696, "redis"))
+	store.Namespace("init_api").
+		Registry(newObj("api_a", 0)).
+		Registry(newObj("api_b", 0))
+
+	start := time.Now()
+	if err := store.InitIocObject(); err != nil {
+		t.Fatal(err)
+	}
+	cost := time.Since(start)
+
+	// log -> (datasource, mongo, redis) -> cache -> api_a -> api_b
+	if cost > 400*time.Millisecond {
+		t.Fatalf("independent objects should init concurrently, cost %s, events %v", cost, *events)
+	}
+	if indexOf(*events, "end log") > indexOf(*events, "start datasource") {
+		t.Fatalf("objects with priority >= %d should init first, got %v", SEQUENTIAL_INIT_PRIORITY, *events)
+	}
+	if indexOf(*events, "end redis") > indexOf(*events, "start cache") {
+		t.Fatalf("cache should init after redis, got %v", *events)
+	}
+	if indexOf(*events, "end api_a") > indexOf(*events, "start api_b") {
+		t.Fatalf("api namespace should init sequentially, got %v", *events)
+	}
+
+	stats := store.InitStats()
+	if len(stats) != 7 || stats[0].Object != "init_config:log@v1" || stats[0].Duration < 50*time.Millisecond {
+		t.Fatalf("unexpected init stats %v", stats)
+	}
+}
+
+func TestInitConcurrentErrors(t *testing.T) {
+	store, newObj, events := newInitTestStore(2)
+	a := newObj("a", 0)
+	a.err = errors.New("connect a failed")
+	b := newObj("b", 0)
+	b.err = errors.New("connect b failed")
+	store.Namespace("init_config").
+		Registry(a).
+		Registry(b).
+		Registry(newObj("c", 0, "a")).
+		Registry(newObj("d", -1))
+
+	err := store.InitIocObject()
+	if err == nil || !strings.Contains(err.Error(), "connect a failed") || !strings.Contains(err.Error(), "connect b failed") {
+		t.Fatalf("errors should be aggregated, got %v", err)
+	}
+	if indexOf(*events, "start c") >= 0 {
+		t.Fatalf("object should be skipped when dependency failed, got %v", *events)
+	}
+	if indexOf(*events, "start d") >= 0 {
+		t.Fatalf("next stage should not start after errors, got %v", *events)
+	}
+}
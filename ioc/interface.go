@@ -12,6 +12,12 @@ type StoreUser interface {
 	Registry(obj Object) StoreUser
 	// 批量注册对象
 	RegistryAll(objs ...Object) StoreUser
+	// 通过工厂注册对象, 并指定对象的生命周期范围
+	RegistryFactory(factory ObjectFactory, scope Scope) StoreUser
+	// 替换同名对象的所有版本, 不存在时直接注册
+	Replace(obj Object) StoreUser
+	// 条件注册, 所有条件都满足时对象才会启用
+	RegistryIf(obj Object, conditions ...Condition) StoreUser
 	// 对象获取
 	Get(name string, opts ...GetOption) Object
 	// 根据对象类型, 直接加载对象
@@ -94,6 +100,16 @@ type PostStopHook interface {
 	OnPostStop(ctx context.Context) error
 }
 
+// ConfigChangeHook 配置热更新钩子
+// 适用场景：日志级别、限流速率、缓存过期时间等不需要重启即可生效的配置
+type ConfigChangeHook interface {
+	Object
+	// OnConfigChange 对象的配置发生变化时调用
+	// old 为当前配置的副本, new 为加载了新配置的副本, 由对象自己决定如何应用新配置
+	// 返回error会记录到重新加载的结果中
+	OnConfigChange(old, new Object) error
+}
+
 // DependencyDeclarer 依赖声明接口（可选）
 // 适用场景：手动通过Get()获取依赖时，仍需要在依赖图中展示这些关系
 // 注意：声明式依赖（ioc标签）会自动检测，无需实现此接口
@@ -109,6 +125,8 @@ type DependencyDeclarer interface {
 	DeclareDependencies() []DependencyInfo
 }
 
+// WithVersion 指定获取对象的版本选择器
+// 支持精确版本(v1)、语义化版本范围(>=v1.2 <v2) 以及 latest, 不指定时默认获取最新版本
 func WithVersion(v string) GetOption {
 	return func(o *option) {
 		o.version = v
@@ -119,12 +137,13 @@ type GetOption func(*option)
 
 func defaultOption() *option {
 	return &option{
-		version: DEFAULT_VERSION,
+		version: LATEST_VERSION,
 	}
 }
 
 type option struct {
 	version string
+	ctx     context.Context
 }
 
 func (o *option) Apply(opts ...GetOption) *option {
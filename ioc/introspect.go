@@ -0,0 +1,154 @@
+package ioc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// NamespaceInfo 命名空间信息
+type NamespaceInfo struct {
+	Name     string `json:"name"`
+	Priority int    `json:"priority"`
+	Objects  int    `json:"objects"`
+}
+
+// ObjectInfo 对象信息
+type ObjectInfo struct {
+	// 对象, 格式为 namespace:name@version
+	Id        string `json:"id"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Priority  int    `json:"priority"`
+	// Go 类型
+	Type  string `json:"type"`
+	Scope Scope  `json:"scope"`
+	// 生命周期状态
+	State string `json:"state"`
+	// 初始化耗时
+	InitDuration time.Duration `json:"init_duration"`
+	// 最近一次状态变化时发生的错误
+	Error string `json:"error,omitempty"`
+}
+
+// DependencyGraph 对象依赖图
+type DependencyGraph struct {
+	Nodes []string         `json:"nodes"`
+	Edges []DependencyEdge `json:"edges"`
+}
+
+// DependencyEdge From 依赖 To
+type DependencyEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Namespaces 返回所有命名空间, 按优先级排序
+func (s *defaultStore) Namespaces() (namespaces []NamespaceInfo) {
+	for _, ns := range s.namespacesByPriority() {
+		namespaces = append(namespaces, NamespaceInfo{
+			Name:     ns.Namespace,
+			Priority: ns.Priority,
+			Objects:  ns.Len(),
+		})
+	}
+	return
+}
+
+// Objects 返回所有对象的信息, 按命名空间优先级以及注册顺序排序
+func (s *defaultStore) Objects() (objects []ObjectInfo) {
+	for _, ns := range s.namespacesByPriority() {
+		ns.ForEach(func(w *ObjectWrapper) {
+			state := string(w.State())
+			if !w.IsSingleton() && !w.Disabled() {
+				state = "on_demand"
+			}
+			errMsg := ""
+			if err := w.Err(); err != nil {
+				errMsg = err.Error()
+			}
+			objects = append(objects, ObjectInfo{
+				Id:           fmt.Sprintf("%s:%s@%s", ns.Namespace, w.Name, w.Version),
+				Namespace:    ns.Namespace,
+				Name:         w.Name,
+				Version:      w.Version,
+				Priority:     w.Priority,
+				Type:         fmt.Sprintf("%T", w.Value),
+				Scope:        w.Scope,
+				State:        state,
+				InitDuration: w.initDuration,
+				Error:        errMsg,
+			})
+		})
+	}
+	return
+}
+
+// DependencyGraph 返回所有单例对象的依赖图, 与初始化排序使用的依赖关系一致
+func (s *defaultStore) DependencyGraph() *DependencyGraph {
+	g := newDependencyGraph(s, s.namespacesByPriority()...)
+	graph := &DependencyGraph{Nodes: []string{}, Edges: []DependencyEdge{}}
+	for _, node := range g.nodes {
+		graph.Nodes = append(graph.Nodes, node.String())
+		for _, dep := range node.deps {
+			graph.Edges = append(graph.Edges, DependencyEdge{From: node.String(), To: dep.String()})
+		}
+	}
+	return graph
+}
+
+// DOT 导出为 Graphviz DOT 格式, 按命名空间分组
+func (g *DependencyGraph) DOT() string {
+	b := &strings.Builder{}
+	b.WriteString("digraph ioc {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	groups := []string{}
+	members := map[string][]string{}
+	for _, node := range g.Nodes {
+		ns := strings.SplitN(node, ":", 2)[0]
+		if _, ok := members[ns]; !ok {
+			groups = append(groups, ns)
+		}
+		members[ns] = append(members[ns], node)
+	}
+	for _, ns := range groups {
+		fmt.Fprintf(b, "  subgraph %q {\n", "cluster_"+ns)
+		fmt.Fprintf(b, "    label=%q;\n", ns)
+		for _, node := range members[ns] {
+			fmt.Fprintf(b, "    %q;\n", node)
+		}
+		b.WriteString("  }\n")
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(b, "  %q -> %q;\n", edge.From, edge.To)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// ConfigValues 返回对象当前的配置, 配置键与配置文件(toml)一致, 嵌套结构体为嵌套的 map
+// 只包含可以通过配置文件加载的字段, 注入的依赖(ioc 标签)不会包含在内
+func ConfigValues(obj Object) map[string]any {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return map[string]any{}
+	}
+	fields := configFields(v.Elem().Type(), v.Elem(), "", map[reflect.Type]bool{})
+	return configFieldValues(fields)
+}
+
+func configFieldValues(fields []*ConfigField) map[string]any {
+	values := make(map[string]any, len(fields))
+	for _, f := range fields {
+		if f.Fields != nil {
+			values[f.Key("toml")] = configFieldValues(f.Fields)
+			continue
+		}
+		values[f.Key("toml")] = f.Default
+	}
+	return values
+}
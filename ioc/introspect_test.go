@@ -0,0 +1,49 @@
+package ioc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIntrospect(t *testing.T) {
+	inited := []string{}
+	store := newGraphTestStore()
+	store.Namespace("graph_default").Registry(&GraphTestObject{name: "repo", inited: &inited})
+	store.Namespace("graph_api").Registry(&GraphTagObject{inited: &inited})
+	store.Namespace("graph_config").Registry(&SchemaTestConfig{Host: "127.0.0.1"})
+	if err := store.InitIocObject(); err != nil {
+		t.Fatal(err)
+	}
+
+	namespaces := store.Namespaces()
+	if namespaces[0].Name != "graph_config" || namespaces[0].Objects != 1 {
+		t.Fatalf("unexpected namespaces %+v", namespaces)
+	}
+
+	objects := store.Objects()
+	if len(objects) != 3 {
+		t.Fatalf("unexpected objects %+v", objects)
+	}
+	repo := objects[1]
+	if repo.Id != "graph_default:repo@v1" || repo.Type != "*ioc.GraphTestObject" || repo.State != "initialized" {
+		t.Fatalf("unexpected object %+v", repo)
+	}
+
+	graph := store.DependencyGraph()
+	if len(graph.Edges) != 1 || graph.Edges[0].From != "graph_api:tag-consumer@v1" || graph.Edges[0].To != "graph_default:repo@v1" {
+		t.Fatalf("unexpected graph %+v", graph)
+	}
+	dot := graph.DOT()
+	if !strings.Contains(dot, `"graph_api:tag-consumer@v1" -> "graph_default:repo@v1";`) ||
+		!strings.Contains(dot, `subgraph "cluster_graph_default"`) {
+		t.Fatalf("unexpected dot:\n%s", dot)
+	}
+
+	values := ConfigValues(store.Namespace("graph_config").Get("schema_test"))
+	if values["host"] != "127.0.0.1" || values["tls"].(map[string]any)["enable"] != false {
+		t.Fatalf("unexpected config values %v", values)
+	}
+	if _, ok := values["Dep"]; ok {
+		t.Fatal("injected dependency should not be included")
+	}
+}
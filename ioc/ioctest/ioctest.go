@@ -0,0 +1,67 @@
+// Package ioctest 提供测试使用的隔离容器
+package ioctest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/infraboard/mcube/v2/ioc"
+)
+
+// Container 测试使用的隔离容器, 测试结束时自动调用 Stop 关闭所有对象
+type Container struct {
+	*ioc.Container
+	t testing.TB
+}
+
+// New 创建测试容器, 克隆 DefaultStore 中注册的所有对象, 克隆的对象为未加载配置的新对象, 由 Load 加载配置并初始化
+// 使用示例:
+//
+//	c := ioctest.New(t).
+//	    Override(ioc.CONTROLLER_NAMESPACE, &FakeUserService{}).
+//	    Load()
+//	svc := c.Controller().Get("user").(*UserService)
+func New(t testing.TB) *Container {
+	return newContainer(t, ioc.DefaultStore.Clone())
+}
+
+// Empty 创建不包含任何对象的测试容器
+func Empty(t testing.TB) *Container {
+	return newContainer(t, ioc.NewContainer())
+}
+
+func newContainer(t testing.TB, c *ioc.Container) *Container {
+	t.Helper()
+	t.Cleanup(func() {
+		c.Stop(context.Background())
+	})
+	return &Container{Container: c, t: t}
+}
+
+// Fake 注册假对象到指定命名空间
+func (c *Container) Fake(namespace string, objs ...ioc.Object) *Container {
+	c.t.Helper()
+	c.Namespace(namespace).RegistryAll(objs...)
+	return c
+}
+
+// Override 使用 obj 替换命名空间中的同名对象(所有版本), 同名对象不存在时直接注册
+func (c *Container) Override(namespace string, obj ioc.Object) *Container {
+	c.t.Helper()
+	c.Namespace(namespace).Replace(obj)
+	return c
+}
+
+// Load 加载配置、注入依赖并初始化所有对象, 失败时测试立即失败
+// 默认不加载配置文件, 可以通过 ioc.WithConfigFile 等选项指定
+func (c *Container) Load(opts ...func(*ioc.LoadConfigRequest)) *Container {
+	c.t.Helper()
+	req := ioc.NewLoadConfigRequest()
+	for _, opt := range opts {
+		opt(req)
+	}
+	if err := c.ConfigIocObject(req); err != nil {
+		c.t.Fatalf("load ioc container error, %s", err)
+	}
+	return c
+}
@@ -0,0 +1,131 @@
+package ioctest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/infraboard/mcube/v2/ioc"
+	"github.com/infraboard/mcube/v2/ioc/ioctest"
+)
+
+type Repository interface {
+	Find(id string) string
+}
+
+// MysqlRepo 真实的存储实现
+type MysqlRepo struct {
+	ioc.ObjectImpl
+	DSN    string `toml:"dsn" env:"DSN"`
+	inited bool
+	closed bool
+}
+
+func (r *MysqlRepo) Name() string              { return "repo" }
+func (r *MysqlRepo) Init() error               { r.inited = true; return nil }
+func (r *MysqlRepo) Close(ctx context.Context) { r.closed = true }
+func (r *MysqlRepo) Find(id string) string     { return "mysql:" + id }
+
+// FakeRepo 测试使用的假对象
+type FakeRepo struct {
+	ioc.ObjectImpl
+	closed bool
+}
+
+func (r *FakeRepo) Name() string              { return "repo" }
+func (r *FakeRepo) Close(ctx context.Context) { r.closed = true }
+func (r *FakeRepo) Find(id string) string     { return "fake:" + id }
+
+// UserService 依赖 Repository 接口
+type UserService struct {
+	ioc.ObjectImpl
+	Repo Repository `ioc:"autowire=true;namespace=ioctest_repo;name=repo"`
+}
+
+func (s *UserService) Name() string { return "user" }
+
+// CacheConfig 包含 map 配置的对象
+type CacheConfig struct {
+	ioc.ObjectImpl
+	Secret string            `toml:"secret" env:"SECRET"`
+	Labels map[string]string `toml:"labels"`
+}
+
+func (c *CacheConfig) Name() string { return "cache" }
+
+// 与业务包一样在 init 中向 DefaultStore 注册对象
+func init() {
+	ioc.DefaultStore.Namespace("ioctest_repo").Registry(&MysqlRepo{})
+	ioc.DefaultStore.Namespace("ioctest_clone").Registry(&MysqlRepo{DSN: "root@tcp(127.0.0.1)"})
+	ioc.DefaultStore.Namespace("ioctest_clone_state").Registry(&CacheConfig{Labels: map[string]string{"env": "dev"}})
+}
+
+func TestEmptyContainer(t *testing.T) {
+	t.Parallel()
+
+	// DefaultStore 中注册的对象不会被注入到隔离容器中
+	fake := &FakeRepo{}
+	c := ioctest.Empty(t).
+		Fake("ioctest_repo", fake).
+		Fake(ioc.CONTROLLER_NAMESPACE, &UserService{}).
+		Load()
+
+	svc := c.Controller().Get("user").(*UserService)
+	if svc.Repo.Find("1") != "fake:1" {
+		t.Fatalf("service should use fake repo in container, got %s", svc.Repo.Find("1"))
+	}
+}
+
+func TestCloneAndOverride(t *testing.T) {
+	t.Parallel()
+
+	ns := ioc.DefaultStore.Namespace("ioctest_clone")
+	original := ns.Get("repo").(*MysqlRepo)
+
+	var cloned *MysqlRepo
+	t.Run("clone", func(t *testing.T) {
+		c := ioctest.New(t).Load()
+		cloned = c.Namespace("ioctest_clone").Get("repo").(*MysqlRepo)
+		if cloned == original || cloned.DSN != original.DSN {
+			t.Fatal("object should be cloned from DefaultStore with the same config")
+		}
+		if !cloned.inited || original.inited {
+			t.Fatal("only the object in the container should be initialized")
+		}
+	})
+	if !cloned.closed || original.closed {
+		t.Fatal("container should be stopped when the test finishes")
+	}
+
+	fake := &FakeRepo{}
+	t.Run("override", func(t *testing.T) {
+		c := ioctest.New(t).Override("ioctest_clone", fake).Load()
+		if c.Namespace("ioctest_clone").Get("repo") != fake {
+			t.Fatal("object should be overridden by fake")
+		}
+	})
+	if !fake.closed {
+		t.Fatal("fake should be closed when the test finishes")
+	}
+	if ns.Get("repo") != original {
+		t.Fatal("DefaultStore should not be affected")
+	}
+}
+
+func TestCloneUnconfigured(t *testing.T) {
+	t.Parallel()
+
+	// 模拟 DefaultStore 中的对象已经加载了配置
+	original := ioc.DefaultStore.Namespace("ioctest_clone_state").Get("cache").(*CacheConfig)
+	original.Secret = "loaded"
+	original.Labels["token"] = "loaded"
+
+	c := ioctest.New(t)
+	cloned := c.Namespace("ioctest_clone_state").Get("cache").(*CacheConfig)
+	if cloned == original || cloned.Secret != "" || len(cloned.Labels) != 1 || cloned.Labels["env"] != "dev" {
+		t.Fatalf("cloned object should start from registration defaults, got %+v", cloned)
+	}
+	cloned.Labels["env"] = "test"
+	if original.Labels["env"] != "dev" {
+		t.Fatal("cloned object should not share state with DefaultStore")
+	}
+}
@@ -1,22 +1,25 @@
 package ioc
 
+import "time"
+
 var (
 	DefaultStore = &defaultStore{
 		store: []*NamespaceStore{
 			newNamespaceStore(CONFIG_NAMESPACE).SetPriority(99),
 			newNamespaceStore(CONTROLLER_NAMESPACE).SetPriority(0),
 			newNamespaceStore(DEFAULT_NAMESPACE).SetPriority(9),
-			newNamespaceStore(API_NAMESPACE).SetPriority(-99),
+			newNamespaceStore(API_NAMESPACE).SetPriority(-99).SetSequentialInit(true),
 		},
 	}
 )
 
 func DevelopmentSetup() {
 	req := NewLoadConfigRequest()
-	// 使用默认配置文件 etc/application.toml
+	// 使用默认配置文件 etc/application.toml, 并按激活的环境叠加配置文件
 	req.ConfigFile.Enabled = true
 	req.ConfigFile.Paths = []string{"etc/application.toml"}
 	req.ConfigFile.SkipIFNotExist = true
+	req.ConfigFile.Profile = true
 	err := ConfigIocObject(req)
 	if err != nil {
 		panic(err)
@@ -44,40 +47,58 @@ func DevelopmentSetupWithPaths(paths ...string) {
 	}
 }
 
-var (
-	isLoaded bool
-)
-
+// ConfigIocObject 加载 DefaultStore 中对象的配置并初始化对象
 func ConfigIocObject(req *LoadConfigRequest) error {
-	if isLoaded && !req.ForceLoad {
+	return DefaultStore.ConfigIocObject(req)
+}
+
+// ConfigIocObject 加载容器中对象的配置并初始化对象
+func (s *defaultStore) ConfigIocObject(req *LoadConfigRequest) error {
+	if s.loaded && !req.ForceLoad {
 		return nil
 	}
 
+	// 0. 加载插件, 插件注册的对象与其他对象一起加载配置并初始化
+	if dir := req.pluginDir(); dir != "" {
+		if err := s.LoadPlugins(dir); err != nil {
+			return err
+		}
+	}
+
 	// 1. 加载对象的配置
-	err := DefaultStore.LoadConfig(req)
+	err := s.LoadConfig(req)
 	if err != nil {
 		return err
 	}
 
 	// 2. 调用 PostConfig 钩子（配置验证）
-	err = DefaultStore.CallPostConfigHooks()
+	err = s.CallPostConfigHooks()
 	if err != nil {
 		return err
 	}
 
-	// 3. 依赖自动注入
-	err = DefaultStore.Autowire()
+	// 3. 依赖自动注入, 请求开启的严格注入模式只作用于本次加载的配置
+	err = s.Autowire()
 	if err != nil {
 		return err
 	}
 
 	// 4. 初始化对象（包含 PreInit 和 PostInit 钩子）
-	err = DefaultStore.InitIocObject()
+	if req.InitParallelism > 0 {
+		s.SetInitParallelism(req.InitParallelism)
+	}
+	if req.BeforeInit != nil {
+		req.BeforeInit()
+	}
+	err = s.InitIocObject()
 	if err != nil {
 		return err
 	}
 
-	isLoaded = true
+	// 5. 监听配置文件以及配置源的变化
+	s.startWatch(req)
+
+	s.loaded = true
 	return nil
 }
 
@@ -96,10 +117,20 @@ func NewLoadConfigRequest() *LoadConfigRequest {
 type LoadConfigRequest struct {
 	// 默认加载后, 不允许重复加载, 这是为了避免多次初始化可能引发的问题
 	ForceLoad bool
+	// 严格注入模式, 依赖不存在或者接口存在多个实现且未指定 name 时返回错误
+	StrictAutowire bool
 	// 环境变量配置
 	ConfigEnv *configEnv
 	// 文件配置方式
 	ConfigFile *configFile
+	// 远程配置源, 按顺序加载, 覆盖文件配置, 环境变量配置优先级最高
+	ConfigSources []ConfigSource
+	// 初始化对象的并发数, 大于1时没有依赖关系的对象并发初始化, 默认按顺序初始化
+	InitParallelism int
+	// 配置加载以及依赖注入完成后, 初始化对象之前执行
+	BeforeInit func()
+	// 插件目录, 加载配置之前加载目录中所有的 Go 插件(.so), 为空时读取环境变量 MCUBE_PLUGIN_DIR
+	PluginDir string
 }
 
 type configFile struct {
@@ -108,6 +139,39 @@ type configFile struct {
 	Paths []string
 	// 如果找不到是否忽略
 	SkipIFNotExist bool
+	// 是否按激活的环境叠加配置文件, 参考 ProfilePaths, 叠加的配置文件不存在时跳过
+	Profile bool
+	// 是否监听配置文件变化, 变化时热更新实现了 ConfigChangeHook 的对象
+	Watch bool
+	// 配置文件检查间隔, 默认5秒
+	WatchInterval time.Duration
+}
+
+// layer 按顺序加载的配置文件
+type layer struct {
+	path string
+	// 按环境叠加的配置文件, 不存在时跳过
+	optional bool
+}
+
+// layers 配置文件的加载顺序, 开启 Profile 时每个配置文件后依次叠加环境配置文件
+func (c *configFile) layers() []layer {
+	layers := []layer{}
+	for _, path := range c.Paths {
+		if !c.Profile {
+			layers = append(layers, layer{path: path})
+			continue
+		}
+		for i, p := range ProfilePaths(path, Profiles()...) {
+			layers = append(layers, layer{path: p, optional: i > 0})
+		}
+	}
+	return layers
+}
+
+// skip 配置文件不存在时是否跳过
+func (c *configFile) skip(l layer) bool {
+	return l.optional || c.SkipIFNotExist
 }
 
 // Path 获取第一个配置文件路径（向后兼容）
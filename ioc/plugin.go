@@ -0,0 +1,188 @@
+package ioc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"slices"
+	"sort"
+)
+
+const (
+	// 插件 API 版本, 插件导出的 IocApiVersion 需要与宿主主版本一致且不高于宿主版本
+	PLUGIN_API_VERSION = "v1.0.0"
+	// 插件目录, LoadConfigRequest.PluginDir 为空时读取该环境变量
+	PLUGIN_DIR_ENV = "MCUBE_PLUGIN_DIR"
+	// 插件导出的注册函数, 类型为 func(*ioc.Container) error
+	PLUGIN_SYMBOL_REGISTRY = "IocRegistry"
+	// 插件导出的 API 版本, 类型为 string, 一般为 ioc.PLUGIN_API_VERSION
+	PLUGIN_SYMBOL_API_VERSION = "IocApiVersion"
+	// 插件文件扩展名
+	PLUGIN_EXT = ".so"
+)
+
+// PluginRegistry 插件的注册函数, 通过容器向 Api、Controller 等命名空间注册对象
+// 注册的对象与编译进来的对象一样经过配置加载、依赖注入以及初始化
+type PluginRegistry func(c *Container) error
+
+// PluginInfo 已加载的插件
+type PluginInfo struct {
+	Path       string `json:"path"`
+	ApiVersion string `json:"api_version"`
+	// 插件注册的对象, 格式为 namespace:name@version
+	Objects []string `json:"objects"`
+}
+
+// pluginSymbols 插件的导出符号, 与 *plugin.Plugin 一致, 方便测试
+type pluginSymbols interface {
+	Lookup(symName string) (plugin.Symbol, error)
+}
+
+var openPlugin = func(path string) (pluginSymbols, error) {
+	return plugin.Open(path)
+}
+
+// LoadPlugins 加载目录中所有的 Go 插件(.so)到默认容器
+func LoadPlugins(dir string) error {
+	return DefaultStore.LoadPlugins(dir)
+}
+
+// LoadPlugins 按文件名顺序加载目录中所有的 Go 插件(.so), 已加载的插件不会重复加载
+// 所有插件的加载错误会合并为一个错误返回
+func (s *defaultStore) LoadPlugins(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+PLUGIN_EXT))
+	if err != nil {
+		return fmt.Errorf("scan plugin dir %s: %w", dir, err)
+	}
+	sort.Strings(paths)
+
+	var errs []error
+	for _, path := range paths {
+		if slices.ContainsFunc(s.plugins, func(p PluginInfo) bool { return p.Path == path }) {
+			continue
+		}
+		info, err := s.loadPlugin(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", path, err))
+			continue
+		}
+		s.plugins = append(s.plugins, *info)
+		debug("[IOC] load plugin %s (api %s), objects: %v", path, info.ApiVersion, info.Objects)
+	}
+	return errors.Join(errs...)
+}
+
+// Plugins 已加载的插件
+func (s *defaultStore) Plugins() []PluginInfo {
+	return slices.Clone(s.plugins)
+}
+
+func (s *defaultStore) loadPlugin(path string) (info *PluginInfo, err error) {
+	p, err := openPlugin(path)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+
+	info = &PluginInfo{Path: path}
+	sym, err := p.Lookup(PLUGIN_SYMBOL_API_VERSION)
+	if err != nil {
+		return nil, fmt.Errorf("missing symbol %s", PLUGIN_SYMBOL_API_VERSION)
+	}
+	switch v := sym.(type) {
+	case *string:
+		info.ApiVersion = *v
+	case string:
+		info.ApiVersion = v
+	default:
+		return nil, fmt.Errorf("symbol %s has type %T, want string", PLUGIN_SYMBOL_API_VERSION, sym)
+	}
+	if err := CheckPluginApiVersion(info.ApiVersion); err != nil {
+		return nil, err
+	}
+
+	sym, err = p.Lookup(PLUGIN_SYMBOL_REGISTRY)
+	if err != nil {
+		return nil, fmt.Errorf("missing symbol %s", PLUGIN_SYMBOL_REGISTRY)
+	}
+	var registry PluginRegistry
+	switch fn := sym.(type) {
+	case func(*Container) error:
+		registry = fn
+	case *func(*Container) error:
+		registry = *fn
+	case *PluginRegistry:
+		registry = *fn
+	default:
+		return nil, fmt.Errorf("symbol %s has type %T, want func(*ioc.Container) error", PLUGIN_SYMBOL_REGISTRY, sym)
+	}
+
+	// 注册重复的对象会 panic, 转换为插件的加载错误
+	// 注册失败时移除插件已经注册的对象以及创建的命名空间
+	namespaces, before := slices.Clone(s.store), s.wrappers()
+	defer func() {
+		if r := recover(); r != nil {
+			info, err = nil, fmt.Errorf("registry panic: %v", r)
+		}
+		if err != nil {
+			s.rollback(namespaces, before)
+		}
+	}()
+	if err := registry(s); err != nil {
+		return nil, fmt.Errorf("registry: %w", err)
+	}
+	for _, ns := range s.store {
+		for _, w := range ns.getItems() {
+			if !before[w] {
+				info.Objects = append(info.Objects, fmt.Sprintf("%s:%s@%s", ns.Namespace, w.Name, w.Version))
+			}
+		}
+	}
+	sort.Strings(info.Objects)
+	return info, nil
+}
+
+// wrappers 所有命名空间中的对象
+func (s *defaultStore) wrappers() map[*ObjectWrapper]bool {
+	items := map[*ObjectWrapper]bool{}
+	for _, ns := range s.store {
+		for _, w := range ns.getItems() {
+			items[w] = true
+		}
+	}
+	return items
+}
+
+// rollback 恢复到插件注册之前的命名空间以及对象
+func (s *defaultStore) rollback(namespaces []*NamespaceStore, before map[*ObjectWrapper]bool) {
+	s.store = slices.DeleteFunc(s.store, func(ns *NamespaceStore) bool {
+		return !slices.Contains(namespaces, ns)
+	})
+	for _, ns := range s.store {
+		ns.writeMu.Lock()
+		ns.setItems(slices.DeleteFunc(slices.Clone(ns.getItems()), func(w *ObjectWrapper) bool {
+			return !before[w]
+		}))
+		ns.writeMu.Unlock()
+	}
+}
+
+// CheckPluginApiVersion 检查插件的 API 版本是否与宿主兼容: 主版本一致且不高于宿主版本
+func CheckPluginApiVersion(version string) error {
+	if version == "" {
+		return fmt.Errorf("empty api version")
+	}
+	if parseVersion(version)[0] != parseVersion(PLUGIN_API_VERSION)[0] || CompareVersion(version, PLUGIN_API_VERSION) > 0 {
+		return fmt.Errorf("api version %s is incompatible with host api version %s", version, PLUGIN_API_VERSION)
+	}
+	return nil
+}
+
+// pluginDir 插件目录, 未配置时读取环境变量
+func (r *LoadConfigRequest) pluginDir() string {
+	if r.PluginDir != "" {
+		return r.PluginDir
+	}
+	return os.Getenv(PLUGIN_DIR_ENV)
+}
@@ -0,0 +1,121 @@
+package ioc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakePlugin 测试使用的插件, 不需要编译 .so 文件
+type fakePlugin map[string]plugin.Symbol
+
+func (p fakePlugin) Lookup(name string) (plugin.Symbol, error) {
+	if sym, ok := p[name]; ok {
+		return sym, nil
+	}
+	return nil, fmt.Errorf("symbol %s not found", name)
+}
+
+// PluginTestObject 插件注册的对象
+type PluginTestObject struct {
+	ObjectImpl
+	inited bool
+}
+
+func (o *PluginTestObject) Name() string { return "audit" }
+
+func (o *PluginTestObject) Init() error {
+	o.inited = true
+	return nil
+}
+
+func TestLoadPlugins(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"audit.so", "broken.so", "future.so", "readme.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	audit := &PluginTestObject{}
+	apiVersion := PLUGIN_API_VERSION
+	plugins := map[string]fakePlugin{
+		"audit.so": {
+			PLUGIN_SYMBOL_API_VERSION: &apiVersion,
+			PLUGIN_SYMBOL_REGISTRY: func(c *Container) error {
+				c.Api().Registry(audit)
+				return nil
+			},
+		},
+		"broken.so": {
+			PLUGIN_SYMBOL_API_VERSION: &apiVersion,
+			PLUGIN_SYMBOL_REGISTRY: func(c *Container) error {
+				c.Namespace("broken").Registry(&PluginTestObject{})
+				c.Default().Registry(&PluginTestObject{})
+				return errors.New("missing license")
+			},
+		},
+		"future.so": {
+			PLUGIN_SYMBOL_API_VERSION: "v2.0.0",
+		},
+	}
+	open := openPlugin
+	openPlugin = func(path string) (pluginSymbols, error) {
+		return plugins[filepath.Base(path)], nil
+	}
+	defer func() { openPlugin = open }()
+
+	c := NewContainer()
+	req := NewLoadConfigRequest()
+	req.PluginDir = dir
+	err := c.ConfigIocObject(req)
+	if err == nil {
+		t.Fatal("should return plugin errors")
+	}
+	for _, want := range []string{"broken.so: registry: missing license", "future.so: api version v2.0.0 is incompatible"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error should contain %q, got %s", want, err)
+		}
+	}
+
+	// 注册失败的插件不会留下已注册的对象以及创建的命名空间
+	if c.Default().Get("audit") != nil || slices.ContainsFunc(c.store, func(ns *NamespaceStore) bool { return ns.Namespace == "broken" }) {
+		t.Fatalf("objects of failed plugin should be removed, %v", c.Default().List())
+	}
+
+	// 失败的插件不影响已加载的插件, 已加载的插件不会重复加载
+	delete(plugins, "broken.so")
+	delete(plugins, "future.so")
+	os.Remove(filepath.Join(dir, "broken.so"))
+	os.Remove(filepath.Join(dir, "future.so"))
+	if err := c.ConfigIocObject(req); err != nil {
+		t.Fatal(err)
+	}
+	if !audit.inited {
+		t.Fatal("plugin object should be initialized")
+	}
+	loaded := c.Plugins()
+	if len(loaded) != 1 || strings.Join(loaded[0].Objects, ",") != "apis:audit@v1" {
+		t.Fatalf("unexpected plugins %+v", loaded)
+	}
+}
+
+func TestCheckPluginApiVersion(t *testing.T) {
+	for version, ok := range map[string]bool{
+		"v1.0.0": true,
+		"v1":     true,
+		"v1.1.0": false,
+		"v0.9.0": false,
+		"v2.0.0": false,
+		"":       false,
+	} {
+		if err := CheckPluginApiVersion(version); (err == nil) != ok {
+			t.Fatalf("version %q: unexpected result %v", version, err)
+		}
+	}
+}
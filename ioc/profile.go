@@ -0,0 +1,74 @@
+package ioc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// 激活的环境, 多个环境使用逗号分隔, 比如: dev,test
+	PROFILES_ENV = "MCUBE_PROFILES"
+	// 本地环境, 叠加配置文件时总是最后加载
+	PROFILE_LOCAL = "local"
+)
+
+var profiles struct {
+	mu     sync.RWMutex
+	set    bool
+	values []string
+}
+
+// SetProfiles 设置激活的环境, 设置后不再读取环境变量 MCUBE_PROFILES
+func SetProfiles(values ...string) {
+	profiles.mu.Lock()
+	defer profiles.mu.Unlock()
+	profiles.set = true
+	profiles.values = splitProfiles(strings.Join(values, ","))
+}
+
+// Profiles 激活的环境, 未通过 SetProfiles 设置时读取环境变量 MCUBE_PROFILES
+func Profiles() []string {
+	profiles.mu.RLock()
+	defer profiles.mu.RUnlock()
+	if profiles.set {
+		return slices.Clone(profiles.values)
+	}
+	return splitProfiles(os.Getenv(PROFILES_ENV))
+}
+
+// IsProfileActive 环境是否激活
+func IsProfileActive(profile string) bool {
+	return slices.Contains(Profiles(), profile)
+}
+
+// ProfilePaths 按环境叠加的配置文件, 后面的覆盖前面的
+// 比如 etc/application.toml 在 dev 环境下为:
+// etc/application.toml -> etc/application-dev.toml -> etc/application-local.toml
+// application-local.toml 用于本地开发时覆盖配置, 不应该提交到代码仓库
+func ProfilePaths(path string, profiles ...string) []string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	paths := []string{path}
+	for _, p := range append(slices.Clone(profiles), PROFILE_LOCAL) {
+		p = fmt.Sprintf("%s-%s%s", base, p, ext)
+		if !slices.Contains(paths, p) {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+func splitProfiles(s string) []string {
+	values := []string{}
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" && !slices.Contains(values, v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
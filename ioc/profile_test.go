@@ -0,0 +1,70 @@
+package ioc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	t.Setenv(PROFILES_ENV, "dev, local,dev")
+	if got := strings.Join(Profiles(), ","); got != "dev,local" {
+		t.Fatalf("unexpected profiles %s", got)
+	}
+
+	SetProfiles("prod")
+	defer resetProfiles()
+	if !IsProfileActive("prod") || IsProfileActive("dev") {
+		t.Fatalf("unexpected profiles %v", Profiles())
+	}
+}
+
+func TestProfilePaths(t *testing.T) {
+	got := ProfilePaths("etc/application.toml", "dev", "local")
+	want := "etc/application.toml,etc/application-dev.toml,etc/application-local.toml"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("unexpected paths %v", got)
+	}
+}
+
+// ProfileTestConfig 叠加配置文件测试使用的配置对象
+type ProfileTestConfig struct {
+	ObjectImpl
+	Host  string `toml:"host"`
+	Port  int    `toml:"port"`
+	Debug bool   `toml:"debug"`
+}
+
+func (c *ProfileTestConfig) Name() string { return "profile_config" }
+
+func TestLoadProfileConfig(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"application.toml":       "[profile_config]\nhost = \"localhost\"\nport = 3306\n",
+		"application-prod.toml":  "[profile_config]\nhost = \"prod-db\"\n",
+		"application-local.toml": "[profile_config]\ndebug = true\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	SetProfiles("prod", "test")
+	defer resetProfiles()
+
+	c := NewContainer()
+	conf := &ProfileTestConfig{}
+	c.Config().Registry(conf)
+
+	req := NewLoadConfigRequest()
+	req.ConfigFile.Enabled = true
+	req.ConfigFile.Profile = true
+	req.ConfigFile.Paths = []string{filepath.Join(dir, "application.toml")}
+	if err := c.LoadConfig(req); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Host != "prod-db" || conf.Port != 3306 || !conf.Debug {
+		t.Fatalf("unexpected config %+v", conf)
+	}
+}
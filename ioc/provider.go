@@ -0,0 +1,425 @@
+package ioc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Unwrapper 适配器对象接口, 返回被适配的值
+// 自动注入、Load 以及 GetValue 会使用被适配的值
+type Unwrapper interface {
+	Unwrap() (any, error)
+}
+
+// typeName 返回类型 T 在容器中的名称, 与 Get[T] 使用的名称一致
+func typeName[T any]() string {
+	return reflect.TypeOf((*T)(nil)).Elem().String()
+}
+
+// ValueObject 将没有实现 Object 接口的值(如 *gorm.DB、SDK 客户端)适配为 Object
+// 对象名称默认为值的类型名称, 关闭时如果值实现了 io.Closer 或者 Close(ctx) 会被调用
+type ValueObject[T any] struct {
+	ObjectImpl
+	name  string
+	value T
+	// 克隆到其他容器的对象与原对象共享值, 由原对象负责关闭
+	borrowed bool
+}
+
+// Adapt 适配任意值为 Object, name 为空时使用类型名称
+// 使用示例:
+//
+//	ioc.Default().Registry(ioc.Adapt(db))
+//	db, err := ioc.GetValue[*gorm.DB](ioc.Default())
+func Adapt[T any](value T, name ...string) *ValueObject[T] {
+	o := &ValueObject[T]{
+		name:  typeName[T](),
+		value: value,
+	}
+	if len(name) > 0 && name[0] != "" {
+		o.name = name[0]
+	}
+	return o
+}
+
+func (o *ValueObject[T]) Name() string {
+	return o.name
+}
+
+// Value 返回被适配的值
+func (o *ValueObject[T]) Value() T {
+	return o.value
+}
+
+func (o *ValueObject[T]) Unwrap() (any, error) {
+	return o.value, nil
+}
+
+func (o *ValueObject[T]) Close(ctx context.Context) {
+	if o.borrowed {
+		return
+	}
+	closeValue(ctx, o.value)
+}
+
+func (o *ValueObject[T]) cloneObject() Object {
+	return &ValueObject[T]{name: o.name, value: o.value, borrowed: true}
+}
+
+// closeValue 关闭被适配的值
+func closeValue(ctx context.Context, v any) {
+	switch c := v.(type) {
+	case Object:
+		c.Close(ctx)
+	case interface{ Close(context.Context) }:
+		c.Close(ctx)
+	case interface{ Close(context.Context) error }:
+		if err := c.Close(ctx); err != nil {
+			debug("close %T error, %s", v, err)
+		}
+	case io.Closer:
+		if err := c.Close(); err != nil {
+			debug("close %T error, %s", v, err)
+		}
+	}
+}
+
+// providerObject 通过构造函数延迟创建的对象
+type providerObject[T any] struct {
+	ObjectImpl
+	name string
+	ctor reflect.Value
+	// 注册的命名空间, 查找构造函数参数时优先查找, 注册时绑定
+	ns *NamespaceStore
+
+	// 并发调用时只执行一次构造函数, 其他调用方等待构造完成
+	state provideState
+	mu    sync.Mutex
+	built bool
+	value T
+	err   error
+}
+
+// provideState 构造函数的执行状态, 通过 provideMu 保护
+type provideState struct {
+	// 正在执行构造函数的调用链, 构造完成后为 nil
+	owner *provideChain
+	// 构造完成时关闭, 为 nil 时还未开始构造
+	done chan struct{}
+}
+
+// provideChain 一次解析过程(通常对应一个 goroutine), 多个调用链之间相互等待时为循环依赖
+type provideChain struct {
+	// 调用链正在等待其他调用链构造的对象
+	waiting *provideState
+}
+
+// provideMu 保护所有构造函数的执行状态, 等待前检查调用链之间是否相互等待
+var provideMu sync.Mutex
+
+// contextUnwrapper 通过上下文获取被适配的值, 上下文中记录了正在执行的构造函数, 用于检查循环依赖
+type contextUnwrapper interface {
+	unwrap(ctx context.Context) (any, error)
+}
+
+type provideStackKey struct{}
+
+type provideChainKey struct{}
+
+// provideStack 一次解析过程中正在执行的构造函数(调用链)
+type provideStack []interface{ Name() string }
+
+func provideStackFromCtx(ctx context.Context) provideStack {
+	stack, _ := ctx.Value(provideStackKey{}).(provideStack)
+	return stack
+}
+
+func (s provideStack) String() string {
+	names := make([]string, 0, len(s))
+	for _, item := range s {
+		names = append(names, item.Name())
+	}
+	return strings.Join(names, " -> ")
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Provide 通过构造函数注册对象, 对象以类型 T 的名称注册, 与 Get[T] 使用的名称一致
+// 构造函数的参数根据类型从容器中查找(先查找注册的命名空间, 再按命名空间优先级查找), 返回值为 T 或者 (T, error)
+// 构造函数在首次获取对象或者初始化时调用, 初始化顺序根据参数的依赖关系确定
+// 使用示例:
+//
+//	ioc.Provide[*gorm.DB](ioc.Default(), func(ds *datasource.DataSource) (*gorm.DB, error) {
+//	    return ds.DB(), nil
+//	})
+func Provide[T any](store StoreUser, constructor any) StoreUser {
+	ctor := reflect.ValueOf(constructor)
+	name := typeName[T]()
+	if err := validateConstructor[T](ctor); err != nil {
+		panic(fmt.Sprintf("ioc: provide %s: %s", name, err))
+	}
+	p := &providerObject[T]{
+		name: name,
+		ctor: ctor,
+	}
+	return store.Registry(p)
+}
+
+func validateConstructor[T any](ctor reflect.Value) error {
+	if ctor.Kind() != reflect.Func {
+		return fmt.Errorf("constructor must be a function, got %s", ctor.Kind())
+	}
+	t := ctor.Type()
+	if t.IsVariadic() {
+		return fmt.Errorf("variadic constructor is not supported")
+	}
+	want := reflect.TypeOf((*T)(nil)).Elem()
+	switch t.NumOut() {
+	case 1:
+	case 2:
+		if t.Out(1) != errorType {
+			return fmt.Errorf("second return value must be error, got %s", t.Out(1))
+		}
+	default:
+		return fmt.Errorf("constructor must return %s or (%s, error)", want, want)
+	}
+	if !t.Out(0).AssignableTo(want) {
+		return fmt.Errorf("constructor returns %s, not assignable to %s", t.Out(0), want)
+	}
+	return nil
+}
+
+func (p *providerObject[T]) Name() string {
+	return p.name
+}
+
+func (p *providerObject[T]) bindNamespace(ns *NamespaceStore) {
+	p.ns = ns
+}
+
+// cloneObject 新容器中重新调用构造函数
+func (p *providerObject[T]) cloneObject() Object {
+	return &providerObject[T]{name: p.name, ctor: p.ctor}
+}
+
+// Init 初始化时调用构造函数
+func (p *providerObject[T]) Init() error {
+	_, err := p.Unwrap()
+	return err
+}
+
+// Unwrap 返回构造函数创建的对象, 首次调用时执行构造函数
+func (p *providerObject[T]) Unwrap() (any, error) {
+	return p.unwrap(context.Background())
+}
+
+// unwrap 构造函数的参数在同一个调用链中解析, 调用链中再次出现自身时为循环依赖
+// 并发调用时等待首次调用构造完成, 不同 goroutine 的调用链相互等待时同样返回循环依赖错误
+func (p *providerObject[T]) unwrap(ctx context.Context) (any, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	stack := provideStackFromCtx(ctx)
+	for _, item := range stack {
+		if item == p {
+			return nil, fmt.Errorf("provide %s: circular dependency in constructor: %s -> %s", p.name, stack, p.name)
+		}
+	}
+
+	chain, _ := ctx.Value(provideChainKey{}).(*provideChain)
+	if chain == nil {
+		chain = &provideChain{}
+		ctx = context.WithValue(ctx, provideChainKey{}, chain)
+	}
+
+	provideMu.Lock()
+	switch {
+	case p.state.done == nil:
+		// 首次调用, 当前调用链执行构造函数
+		p.state.owner, p.state.done = chain, make(chan struct{})
+		provideMu.Unlock()
+		p.construct(context.WithValue(ctx, provideStackKey{}, append(stack[:len(stack):len(stack)], p)))
+	case p.state.owner != nil:
+		// 其他调用链正在构造, 等待之前检查对方是否(间接)在等待当前调用链
+		if p.state.waitsFor(chain) {
+			provideMu.Unlock()
+			return nil, fmt.Errorf("provide %s: circular dependency in constructor: %s -> %s (being constructed concurrently)", p.name, stack, p.name)
+		}
+		chain.waiting = &p.state
+		done := p.state.done
+		provideMu.Unlock()
+
+		<-done
+
+		provideMu.Lock()
+		chain.waiting = nil
+		provideMu.Unlock()
+	default:
+		provideMu.Unlock()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.value, p.err
+}
+
+// construct 执行构造函数, 结束后(包括 panic)唤醒等待的调用方
+func (p *providerObject[T]) construct(ctx context.Context) {
+	defer func() {
+		provideMu.Lock()
+		defer provideMu.Unlock()
+		p.state.owner = nil
+		close(p.state.done)
+	}()
+
+	value, err := p.build(ctx)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.built = true
+	p.value, p.err = value, err
+}
+
+// waitsFor 正在构造的调用链是否(间接)在等待 chain, 需要持有 provideMu
+func (s *provideState) waitsFor(chain *provideChain) bool {
+	for c := s.owner; c != nil; {
+		if c == chain {
+			return true
+		}
+		if c.waiting == nil {
+			return false
+		}
+		c = c.waiting.owner
+	}
+	return false
+}
+
+func (p *providerObject[T]) build(ctx context.Context) (T, error) {
+	var zero T
+
+	t := p.ctor.Type()
+	args := make([]reflect.Value, 0, t.NumIn())
+	for i := 0; i < t.NumIn(); i++ {
+		arg, err := resolveProvideParam(ctx, p.ns, t.In(i))
+		if err != nil {
+			return zero, fmt.Errorf("provide %s: %w", p.name, err)
+		}
+		args = append(args, arg)
+	}
+
+	out := p.ctor.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		return zero, fmt.Errorf("provide %s: %w", p.name, out[1].Interface().(error))
+	}
+	value, _ := out[0].Interface().(T)
+	return value, nil
+}
+
+// DeclareDependencies 构造函数的参数即为对象的依赖
+func (p *providerObject[T]) DeclareDependencies() []DependencyInfo {
+	var deps []DependencyInfo
+	t := p.ctor.Type()
+	for i := 0; i < t.NumIn(); i++ {
+		ns, w := findProvideParam(p.ns, t.In(i))
+		if w == nil {
+			continue
+		}
+		deps = append(deps, DependencyInfo{
+			Name:      w.Name,
+			Namespace: ns.Namespace,
+			Version:   w.Version,
+			FieldName: fmt.Sprintf("arg%d", i),
+		})
+	}
+	return deps
+}
+
+func (p *providerObject[T]) Close(ctx context.Context) {
+	p.mu.Lock()
+	built, value := p.built && p.err == nil, p.value
+	p.mu.Unlock()
+	if built {
+		closeValue(ctx, value)
+	}
+}
+
+// findProvideParam 查找构造函数参数对应的对象, 先查找 current 命名空间, 再按命名空间优先级查找
+// 先按类型名称查找, 参数为接口时再查找实现了该接口的对象
+func findProvideParam(current *NamespaceStore, t reflect.Type) (*NamespaceStore, *ObjectWrapper) {
+	namespaces := DefaultStore.namespacesByPriority()
+	if current != nil {
+		namespaces = append([]*NamespaceStore{current}, current.owner().namespacesByPriority()...)
+	}
+	for _, ns := range namespaces {
+		if w, _ := ResolveVersion(ns.versions(t.String()), LATEST_VERSION); w != nil {
+			return ns, w
+		}
+	}
+	if t.Kind() == reflect.Interface {
+		for _, ns := range namespaces {
+			if items := ns.implementWrappers(t, LATEST_VERSION); len(items) > 0 {
+				return ns, items[0]
+			}
+		}
+	}
+	return nil, nil
+}
+
+func resolveProvideParam(ctx context.Context, current *NamespaceStore, t reflect.Type) (reflect.Value, error) {
+	_, w := findProvideParam(current, t)
+	if w == nil {
+		return reflect.Value{}, fmt.Errorf("dependency %s not found", t)
+	}
+	obj, err := w.resolve(ctx)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return assignableValue(obj, t)
+}
+
+// assignableValue 返回可以赋值给类型 t 的值, 对象不能直接赋值时尝试使用被适配的值
+func assignableValue(obj Object, t reflect.Type) (reflect.Value, error) {
+	v := reflect.ValueOf(obj)
+	if v.Type().AssignableTo(t) {
+		return v, nil
+	}
+	if u, ok := obj.(Unwrapper); ok {
+		value, err := u.Unwrap()
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		uv := reflect.ValueOf(value)
+		if uv.IsValid() && uv.Type().AssignableTo(t) {
+			return uv, nil
+		}
+		if !uv.IsValid() {
+			return reflect.Zero(t), nil
+		}
+		return reflect.Value{}, fmt.Errorf("type mismatch: want %s, got %s", t, uv.Type())
+	}
+	return reflect.Value{}, fmt.Errorf("type mismatch: want %s, got %s", t, v.Type())
+}
+
+// GetValue 泛型方式获取对象, 与 Get 不同的是 T 不需要实现 Object 接口
+// 适用于通过 Adapt 或 Provide 注册的对象
+// 使用示例:
+//
+//	db, err := ioc.GetValue[*gorm.DB](ioc.Default())
+func GetValue[T any](store StoreUser, opts ...GetOption) (T, error) {
+	var zero T
+
+	name := typeName[T]()
+	obj := store.Get(name, opts...)
+	if obj == nil {
+		return zero, fmt.Errorf("object %s not found in store", name)
+	}
+
+	v, err := assignableValue(obj, reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return zero, err
+	}
+	return v.Interface().(T), nil
+}
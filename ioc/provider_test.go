@@ -0,0 +1,228 @@
+package ioc_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/infraboard/mcube/v2/ioc"
+)
+
+// ThirdPartyClient 模拟没有实现 Object 接口的第三方客户端
+type ThirdPartyClient struct {
+	Endpoint string
+	closed   bool
+}
+
+func (c *ThirdPartyClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+// ThirdPartyDB 依赖 ThirdPartyClient 的第三方对象
+type ThirdPartyDB struct {
+	Client *ThirdPartyClient
+}
+
+// ProvidedService 通过构造函数创建的 Object
+type ProvidedService struct {
+	ioc.ObjectImpl
+	DB *ThirdPartyDB
+}
+
+// ProviderConsumer 通过标签注入构造函数创建的对象
+type ProviderConsumer struct {
+	ioc.ObjectImpl
+	DB  *ThirdPartyDB    `ioc:"autowire=true;namespace=provider_test"`
+	Svc *ProvidedService `ioc:"autowire=true;namespace=provider_test"`
+}
+
+func (c *ProviderConsumer) Name() string { return "provider-consumer" }
+
+func TestProvide(t *testing.T) {
+	ns := ioc.DefaultStore.Namespace("provider_test")
+	client := &ThirdPartyClient{Endpoint: "127.0.0.1"}
+	calls := 0
+
+	// 先注册依赖方, 构造函数在首次获取时才会调用
+	ioc.Provide[*ProvidedService](ns, func(db *ThirdPartyDB) *ProvidedService {
+		return &ProvidedService{DB: db}
+	})
+	ioc.Provide[*ThirdPartyDB](ns, func(c *ThirdPartyClient) (*ThirdPartyDB, error) {
+		calls++
+		return &ThirdPartyDB{Client: c}, nil
+	})
+	ns.Registry(ioc.Adapt(client))
+
+	if calls != 0 {
+		t.Fatal("constructor should be called lazily")
+	}
+
+	// Get[T] 返回构造函数创建的 Object
+	svc, err := ioc.Get[*ProvidedService](ns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.DB == nil || svc.DB.Client != client {
+		t.Fatal("constructor parameters should be resolved from the store")
+	}
+
+	// GetValue[T] 获取没有实现 Object 接口的对象
+	db, err := ioc.GetValue[*ThirdPartyDB](ns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db != svc.DB || calls != 1 {
+		t.Fatalf("constructor should be called only once, calls=%d", calls)
+	}
+
+	consumer := &ProviderConsumer{}
+	ns.Registry(consumer)
+	if err := ns.Autowire(); err != nil {
+		t.Fatal(err)
+	}
+	if consumer.DB != db || consumer.Svc != svc {
+		t.Fatal("provided objects should be autowired")
+	}
+
+	// 适配的值关闭时调用 io.Closer
+	ns.Close(context.Background())
+	if !client.closed {
+		t.Fatal("adapted value should be closed")
+	}
+}
+
+func TestProvideInitOrder(t *testing.T) {
+	ns := ioc.DefaultStore.Namespace("provider_order_test")
+	ioc.Provide[*ThirdPartyDB](ns, func(c *ThirdPartyClient) *ThirdPartyDB {
+		return &ThirdPartyDB{Client: c}
+	})
+	ns.Registry(ioc.Adapt(&ThirdPartyClient{}))
+
+	if err := ns.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 构造函数的参数作为依赖, 被依赖的对象排在前面
+	list := ns.List()
+	if list[0] != "*ioc_test.ThirdPartyClient.v1" {
+		t.Fatalf("unexpected init order %v", list)
+	}
+}
+
+func TestProvideError(t *testing.T) {
+	ns := ioc.DefaultStore.Namespace("provider_error_test")
+	ioc.Provide[*ThirdPartyDB](ns, func() (*ThirdPartyDB, error) {
+		return nil, errors.New("connect refused")
+	})
+
+	err := ns.Init()
+	if err == nil || !strings.Contains(err.Error(), "connect refused") {
+		t.Fatalf("expect constructor error, got %v", err)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expect panic for invalid constructor")
+		}
+	}()
+	ioc.Provide[*ThirdPartyDB](ns, func() string { return "" })
+}
+
+// CycleA 与 CycleB 的构造函数相互依赖
+type CycleA struct{ B *CycleB }
+
+type CycleB struct{ A *CycleA }
+
+func TestProvideCircular(t *testing.T) {
+	ns := ioc.NewContainer().Namespace("provider_cycle_test")
+	ioc.Provide[*CycleA](ns, func(b *CycleB) *CycleA { return &CycleA{B: b} })
+	ioc.Provide[*CycleB](ns, func(a *CycleA) *CycleB { return &CycleB{A: a} })
+
+	_, err := ns.Lookup("*ioc_test.CycleA")
+	if err == nil || !strings.Contains(err.Error(), "circular dependency") {
+		t.Fatalf("expect circular dependency error, got %v", err)
+	}
+}
+
+// CrossA 与 CrossB 的构造函数相互依赖, 通过 gate 保证两个 goroutine 同时开始构造
+type CrossA struct{ B *CrossB }
+
+type CrossB struct{ A *CrossA }
+
+type gateA struct{}
+
+type gateB struct{}
+
+func TestProvideCircularConcurrent(t *testing.T) {
+	ns := ioc.NewContainer().Namespace("provider_cross_cycle_test")
+	var started sync.WaitGroup
+	started.Add(2)
+	gate := func() {
+		started.Done()
+		started.Wait()
+	}
+	ioc.Provide[*gateA](ns, func() *gateA { gate(); return &gateA{} })
+	ioc.Provide[*gateB](ns, func() *gateB { gate(); return &gateB{} })
+	ioc.Provide[*CrossA](ns, func(_ *gateA, b *CrossB) *CrossA { return &CrossA{B: b} })
+	ioc.Provide[*CrossB](ns, func(_ *gateB, a *CrossA) *CrossB { return &CrossB{A: a} })
+
+	errs := make(chan error, 2)
+	go func() {
+		_, err := ns.Lookup("*ioc_test.CrossA")
+		errs <- err
+	}()
+	go func() {
+		_, err := ns.Lookup("*ioc_test.CrossB")
+		errs <- err
+	}()
+
+	for range 2 {
+		select {
+		case err := <-errs:
+			if err == nil || !strings.Contains(err.Error(), "circular dependency") {
+				t.Fatalf("expect circular dependency error, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("deadlock resolving constructors concurrently")
+		}
+	}
+}
+
+func TestProvideConcurrent(t *testing.T) {
+	ns := ioc.NewContainer().Namespace("provider_concurrent_test")
+	var calls atomic.Int32
+	ioc.Provide[*ThirdPartyDB](ns, func() *ThirdPartyDB {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return &ThirdPartyDB{}
+	})
+
+	var wg sync.WaitGroup
+	results := make([]*ThirdPartyDB, 8)
+	errs := make([]error, 8)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = ioc.GetValue[*ThirdPartyDB](ns)
+		}()
+	}
+	wg.Wait()
+
+	for i := range results {
+		if errs[i] != nil {
+			t.Fatalf("concurrent caller should wait for the constructor, got %v", errs[i])
+		}
+		if results[i] != results[0] {
+			t.Fatal("concurrent callers should get the same object")
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("constructor should be called once, got %d", calls.Load())
+	}
+}
@@ -0,0 +1,175 @@
+package ioc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+const (
+	// DEFAULT_WATCH_INTERVAL 默认的配置文件检查间隔
+	DEFAULT_WATCH_INTERVAL = 5 * time.Second
+)
+
+// ReloadConfig 重新加载 DefaultStore 中对象的配置
+func ReloadConfig() ([]string, error) {
+	return DefaultStore.ReloadConfig()
+}
+
+// ReloadConfig 根据上次加载使用的配置(文件、配置源与环境变量)重新加载对象配置, 返回配置发生变化的对象
+// 只有实现了 ConfigChangeHook 的单例对象参与热更新:
+// 新配置会加载到根据注册时默认配置创建的新对象上, 只对比配置字段, 配置发生变化时调用 OnConfigChange(old, new),
+// old 为当前配置字段的副本, 由对象自己决定如何应用新配置, 容器不会修改对象本身
+// 多个来源(配置文件监听、配置源监听、SIGHUP)同时触发时按顺序执行
+func (s *defaultStore) ReloadConfig() ([]string, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	req := s.conf
+	if req == nil {
+		return nil, fmt.Errorf("config has not been loaded")
+	}
+
+	// 读取并解析所有配置文件以及配置源
+	type fileSection struct {
+		path    string
+		data    map[string]any
+		tagName string
+	}
+	files := []fileSection{}
+	if req.ConfigFile.Enabled {
+		for _, l := range req.ConfigFile.layers() {
+			path := l.path
+			if !IsFileExists(path) {
+				if !req.ConfigFile.skip(l) {
+					return nil, fmt.Errorf("file %s not exist", path)
+				}
+				continue
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+			}
+			data, tagName, err := parseFileContent(content, filepath.Ext(path))
+			if err != nil {
+				return nil, fmt.Errorf("file %s: %w", path, err)
+			}
+			files = append(files, fileSection{path: path, data: data, tagName: tagName})
+		}
+	}
+	// 配置源在配置文件之后加载
+	for _, src := range req.ConfigSources {
+		content, format, err := readConfigSource(src)
+		if err != nil {
+			return nil, err
+		}
+		data, tagName, err := parseFileContent(content, format)
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", src.Name(), err)
+		}
+		files = append(files, fileSection{path: src.Name(), data: data, tagName: tagName})
+	}
+
+	var (
+		changed []string
+		errs    []error
+	)
+	for _, ns := range s.store {
+		ns.ForEach(func(w *ObjectWrapper) {
+			hook, ok := w.Value.(ConfigChangeHook)
+			if !ok || !w.IsSingleton() || w.Disabled() {
+				return
+			}
+
+			oldObj, newObj := newConfigObject(w.Value), w.newDefaultObject()
+			if oldObj == nil || newObj == nil {
+				return
+			}
+			for _, f := range files {
+				if section, exists := f.data[w.Value.Name()]; exists {
+					if err := decodeSection(newObj, w.Value.Name(), section, f.tagName); err != nil {
+						errs = append(errs, fmt.Errorf("[%s] %s: %w", ns.Namespace, f.path, err))
+						return
+					}
+				}
+			}
+			if req.ConfigEnv.Enabled {
+				if err := decodeEnv(newObj, w.Name, req.ConfigEnv.Prefix); err != nil {
+					errs = append(errs, fmt.Errorf("[%s] %s env: %w", ns.Namespace, w.Name, err))
+					return
+				}
+			}
+
+			if err := decryptObject(newObj); err != nil {
+				errs = append(errs, fmt.Errorf("[%s] %w", ns.Namespace, err))
+				return
+			}
+
+			if reflect.DeepEqual(oldObj, newObj) {
+				return
+			}
+
+			debug("[IOC:%s] config of %s changed, calling OnConfigChange hook", ns.Namespace, w.Name)
+			if err := hook.OnConfigChange(oldObj, newObj); err != nil {
+				errs = append(errs, fmt.Errorf("[%s] OnConfigChange hook failed for %s: %w", ns.Namespace, w.Name, err))
+				return
+			}
+			changed = append(changed, fmt.Sprintf("%s:%s", ns.Namespace, ObjectUid(w)))
+		})
+	}
+
+	return changed, errors.Join(errs...)
+}
+
+// WatchConfig 定期检查配置文件是否发生变化, 发生变化时重新加载配置, 直到 ctx 结束
+// callback 在每次重新加载后调用, 可用于记录日志
+func (s *defaultStore) WatchConfig(ctx context.Context, interval time.Duration, callback func(changed []string, err error)) {
+	if interval <= 0 {
+		interval = DEFAULT_WATCH_INTERVAL
+	}
+
+	last := s.configFileStats()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := s.configFileStats()
+			if reflect.DeepEqual(last, current) {
+				continue
+			}
+			last = current
+
+			debug("config file changed, reloading config")
+			changed, err := s.ReloadConfig()
+			if callback != nil {
+				callback(changed, err)
+			}
+		}
+	}
+}
+
+// configFileStats 返回配置文件的修改时间与大小, 用于判断文件是否发生变化
+func (s *defaultStore) configFileStats() map[string]string {
+	stats := map[string]string{}
+	if s.conf == nil || !s.conf.ConfigFile.Enabled {
+		return stats
+	}
+	for _, l := range s.conf.ConfigFile.layers() {
+		path := l.path
+		info, err := os.Stat(path)
+		if err != nil {
+			stats[path] = ""
+			continue
+		}
+		stats[path] = fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size())
+	}
+	return stats
+}
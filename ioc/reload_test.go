@@ -0,0 +1,265 @@
+package ioc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ReloadTestConfig 实现了 ConfigChangeHook 的配置对象
+type ReloadTestConfig struct {
+	ObjectImpl
+	name   string
+	Level  string            `toml:"level"`
+	Labels map[string]string `toml:"labels"`
+
+	changes int
+}
+
+func (c *ReloadTestConfig) Name() string { return c.name }
+
+func (c *ReloadTestConfig) OnConfigChange(old, new Object) error {
+	c.changes++
+	c.Level = new.(*ReloadTestConfig).Level
+	c.Labels = new.(*ReloadTestConfig).Labels
+	return nil
+}
+
+func newReloadTestStore(t *testing.T, content string) (*defaultStore, string) {
+	path := filepath.Join(t.TempDir(), "application.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := &defaultStore{
+		store: []*NamespaceStore{newNamespaceStore("reload_test")},
+	}
+	return store, path
+}
+
+func TestReloadConfig(t *testing.T) {
+	store, path := newReloadTestStore(t, `
+[log]
+level = "debug"
+labels = { env = "dev" }
+
+[cache]
+level = "info"
+`)
+	logConf := &ReloadTestConfig{name: "log"}
+	cacheConf := &ReloadTestConfig{name: "cache"}
+	store.Namespace("reload_test").Registry(logConf).Registry(cacheConf)
+
+	if _, err := store.ReloadConfig(); err == nil {
+		t.Fatal("reload before load should fail")
+	}
+
+	req := NewLoadConfigRequest()
+	req.ConfigFile.Enabled = true
+	req.ConfigFile.Paths = []string{path}
+	if err := store.LoadConfig(req); err != nil {
+		t.Fatal(err)
+	}
+	if logConf.Level != "debug" || logConf.Labels["env"] != "dev" {
+		t.Fatalf("unexpected config %+v", logConf)
+	}
+
+	// 配置没有变化时不会通知对象
+	changed, err := store.ReloadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 0 {
+		t.Fatalf("expect no changes, got %v", changed)
+	}
+
+	if err := os.WriteFile(path, []byte(`
+[log]
+level = "error"
+labels = { env = "prod" }
+
+[cache]
+level = "info"
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err = store.ReloadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 || changed[0] != "reload_test:log.v1" {
+		t.Fatalf("expect only log changed, got %v", changed)
+	}
+	if logConf.changes != 1 || cacheConf.changes != 0 {
+		t.Fatalf("unexpected hook calls, log=%d cache=%d", logConf.changes, cacheConf.changes)
+	}
+	if logConf.Level != "error" || logConf.Labels["env"] != "prod" {
+		t.Fatalf("new config not applied %+v", logConf)
+	}
+}
+
+// ReloadBackend 嵌套的配置
+type ReloadBackend struct {
+	Addr string            `toml:"addr"`
+	Tags map[string]string `toml:"tags"`
+}
+
+// ReloadNestedConfig 包含指针、锁以及函数字段的配置对象, 配置变化时只记录新旧配置
+type ReloadNestedConfig struct {
+	ObjectImpl
+	Backend *ReloadBackend `toml:"backend"`
+	Timeout int            `toml:"timeout"`
+	OnError func(error)
+
+	mu       sync.Mutex
+	old, new *ReloadNestedConfig
+}
+
+func (c *ReloadNestedConfig) Name() string { return "nested" }
+
+func (c *ReloadNestedConfig) OnConfigChange(old, new Object) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.old, c.new = old.(*ReloadNestedConfig), new.(*ReloadNestedConfig)
+	return nil
+}
+
+func TestReloadConfigIsolation(t *testing.T) {
+	store, path := newReloadTestStore(t, `
+[nested]
+timeout = 10
+backend = { addr = "a:1", tags = { zone = "a" } }
+`)
+	conf := &ReloadNestedConfig{Timeout: 3, OnError: func(error) {}}
+	store.Namespace("reload_test").Registry(conf)
+
+	req := NewLoadConfigRequest()
+	req.ConfigFile.Enabled = true
+	req.ConfigFile.Paths = []string{path}
+	if err := store.LoadConfig(req); err != nil {
+		t.Fatal(err)
+	}
+	live := conf.Backend
+
+	// 函数字段不参与对比
+	changed, err := store.ReloadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 0 {
+		t.Fatalf("expect no changes, got %v", changed)
+	}
+
+	// 删除 timeout 后恢复为默认值, 新配置不会写入运行中的对象
+	if err := os.WriteFile(path, []byte(`
+[nested]
+backend = { addr = "b:1", tags = { zone = "b" } }
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.ReloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Backend != live || live.Addr != "a:1" || live.Tags["zone"] != "a" || conf.Timeout != 10 {
+		t.Fatalf("running object modified by reload: %+v %+v", conf, live)
+	}
+	if conf.new == nil || conf.new.Backend.Addr != "b:1" || conf.new.Backend.Tags["zone"] != "b" || conf.new.Timeout != 3 {
+		t.Fatalf("unexpected new config %+v", conf.new)
+	}
+	if conf.old.Backend == live || conf.old.Backend.Addr != "a:1" || conf.old.Timeout != 10 {
+		t.Fatalf("unexpected old config %+v", conf.old)
+	}
+}
+
+func TestReloadConfigConcurrent(t *testing.T) {
+	store, path := newReloadTestStore(t, `
+[log]
+level = "debug"
+`)
+	logConf := &ReloadTestConfig{name: "log"}
+	store.Namespace("reload_test").Registry(logConf)
+
+	req := NewLoadConfigRequest()
+	req.ConfigFile.Enabled = true
+	req.ConfigFile.Paths = []string{path}
+	if err := store.LoadConfig(req); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`
+[log]
+level = "warn"
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 多个来源同时触发时只有一次重新加载看到配置变化
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		total int
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			changed, err := store.ReloadConfig()
+			if err != nil {
+				t.Error(err)
+			}
+			mu.Lock()
+			total += len(changed)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if total != 1 || logConf.changes != 1 {
+		t.Fatalf("expect config changed once, got changed=%d hook=%d", total, logConf.changes)
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	store, path := newReloadTestStore(t, `
+[log]
+level = "debug"
+`)
+	logConf := &ReloadTestConfig{name: "log"}
+	store.Namespace("reload_test").Registry(logConf)
+
+	req := NewLoadConfigRequest()
+	req.ConfigFile.Enabled = true
+	req.ConfigFile.Paths = []string{path}
+	if err := store.LoadConfig(req); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan []string, 1)
+	go store.WatchConfig(ctx, 10*time.Millisecond, func(changed []string, err error) {
+		if err != nil {
+			t.Error(err)
+		}
+		done <- changed
+	})
+
+	// 等待 watcher 记录初始文件状态
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(path, []byte(`
+[log]
+level = "warn"
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case changed := <-done:
+		if len(changed) != 1 || logConf.Level != "warn" {
+			t.Fatalf("unexpected reload result %v, level=%s", changed, logConf.Level)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("config change not detected")
+	}
+}
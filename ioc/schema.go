@@ -0,0 +1,451 @@
+package ioc
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mitchellh/mapstructure"
+)
+
+// ConfigSchema 所有已注册对象的配置结构, 根据对象的结构体标签生成
+type ConfigSchema struct {
+	// 配置段, 按命名空间优先级排序
+	Sections []*ConfigSection `json:"sections"`
+}
+
+// ConfigSection 配置段, 对应配置文件中的一个表, 名称为对象名称
+type ConfigSection struct {
+	// 配置段名称
+	Name string `json:"name"`
+	// 使用该配置段的对象, 格式: 命名空间:类型(版本)
+	Objects []string `json:"objects"`
+	// 配置字段
+	Fields []*ConfigField `json:"fields"`
+}
+
+// ConfigField 配置字段
+type ConfigField struct {
+	// Go 字段名称
+	Name string `json:"name"`
+	// 各个配置格式使用的配置键: toml, yaml, json
+	Keys map[string]string `json:"keys"`
+	// 环境变量名称, 包含前缀, 为空表示不支持环境变量配置
+	Env string `json:"env,omitempty"`
+	// JSON Schema 类型
+	Type string `json:"type"`
+	// Go 类型
+	GoType string `json:"go_type"`
+	// 默认值, 即对象注册时的配置值
+	Default any `json:"default,omitempty"`
+	// 嵌套结构体的字段
+	Fields []*ConfigField `json:"fields,omitempty"`
+
+	typ reflect.Type
+}
+
+// Key 返回配置格式对应的配置键, tagName 为 toml, yaml 或者 json
+func (f *ConfigField) Key(tagName string) string {
+	if key, ok := f.Keys[tagName]; ok {
+		return key
+	}
+	return f.Name
+}
+
+// ExportConfigSchema 导出 DefaultStore 中所有对象的配置结构
+// envPrefix 为加载环境变量使用的前缀
+func ExportConfigSchema(envPrefix string) *ConfigSchema {
+	return DefaultStore.ConfigSchema(envPrefix)
+}
+
+// ConfigSchema 遍历所有命名空间中的对象, 根据结构体标签生成配置结构
+// 同名对象共用一个配置段
+func (s *defaultStore) ConfigSchema(prefix string) *ConfigSchema {
+	schema := &ConfigSchema{}
+	sections := map[string]*ConfigSection{}
+
+	for _, ns := range s.namespacesByPriority() {
+		ns.ForEach(func(w *ObjectWrapper) {
+			// 默认值取自注册时的配置快照, 不会导出加载后的配置(比如密码)
+			if w.defaults == nil {
+				return
+			}
+			v := reflect.ValueOf(w.defaults)
+			fields := configFields(v.Elem().Type(), v.Elem(), envPrefix(w.Name, prefix), map[reflect.Type]bool{})
+			if len(fields) == 0 {
+				return
+			}
+
+			section, ok := sections[w.Name]
+			if !ok {
+				section = &ConfigSection{Name: w.Name}
+				sections[w.Name] = section
+				schema.Sections = append(schema.Sections, section)
+			}
+			section.Objects = append(section.Objects, fmt.Sprintf("%s:%T(%s)", ns.Namespace, w.Value, w.Version))
+			section.Fields = mergeConfigFields(section.Fields, fields)
+		})
+	}
+	return schema
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// configFields 根据结构体字段生成配置字段, 与加载配置时的规则保持一致:
+// 嵌入的结构体会展开(Squash), 忽略未导出字段、注入的依赖(ioc 标签)以及函数、通道、接口类型的字段
+func configFields(t reflect.Type, v reflect.Value, envPrefix string, visited map[reflect.Type]bool) []*ConfigField {
+	if visited[t] {
+		return nil
+	}
+	visited[t] = true
+	defer delete(visited, t)
+
+	var fields []*ConfigField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		var fv reflect.Value
+		if v.IsValid() {
+			fv = v.Field(i)
+		}
+
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, configFields(sf.Type, fv, envPrefix, visited)...)
+			continue
+		}
+		if !isConfigField(sf) {
+			continue
+		}
+
+		f := &ConfigField{
+			Name:   sf.Name,
+			Keys:   map[string]string{},
+			GoType: sf.Type.String(),
+			typ:    sf.Type,
+		}
+		for _, tagName := range []string{"toml", "yaml", "json"} {
+			if key := strings.Split(sf.Tag.Get(tagName), ",")[0]; key != "" {
+				f.Keys[tagName] = key
+			}
+		}
+		if name := strings.Split(sf.Tag.Get("env"), ",")[0]; name != "" {
+			f.Env = envPrefix + name
+		}
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+			if fv.IsValid() {
+				fv = fv.Elem()
+			}
+		}
+		f.Type = jsonSchemaType(ft)
+		if ft.Kind() == reflect.Struct && ft != timeType {
+			f.Fields = configFields(ft, fv, envPrefix+sf.Tag.Get("envPrefix"), visited)
+		} else if fv.IsValid() && !isNilValue(fv) {
+			f.Default = fv.Interface()
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// mergeConfigFields 合并同名对象的配置字段
+func mergeConfigFields(exist, fields []*ConfigField) []*ConfigField {
+	for _, f := range fields {
+		found := false
+		for _, e := range exist {
+			if strings.EqualFold(e.Key("toml"), f.Key("toml")) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			exist = append(exist, f)
+		}
+	}
+	return exist
+}
+
+func jsonSchemaType(t reflect.Type) string {
+	if t == timeType {
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	case reflect.Ptr:
+		return jsonSchemaType(t.Elem())
+	}
+	return ""
+}
+
+// JSONSchema 生成 JSON Schema(draft 2020-12), 配置键使用 toml 标签
+func (s *ConfigSchema) JSONSchema() ([]byte, error) {
+	properties := map[string]any{}
+	for _, section := range s.Sections {
+		properties[section.Name] = map[string]any{
+			"type":                 "object",
+			"description":          strings.Join(section.Objects, ", "),
+			"properties":           jsonSchemaProperties(section.Fields),
+			"additionalProperties": false,
+		}
+	}
+	return json.MarshalIndent(map[string]any{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}, "", "  ")
+}
+
+func jsonSchemaProperties(fields []*ConfigField) map[string]any {
+	properties := map[string]any{}
+	for _, f := range fields {
+		p := map[string]any{
+			"description": f.description(),
+		}
+		if f.Type != "" {
+			p["type"] = f.Type
+		}
+		if len(f.Fields) > 0 {
+			p["properties"] = jsonSchemaProperties(f.Fields)
+		}
+		if f.Default != nil {
+			if _, err := json.Marshal(f.Default); err == nil {
+				p["default"] = f.Default
+			}
+		}
+		properties[f.Key("toml")] = p
+	}
+	return properties
+}
+
+func (f *ConfigField) description() string {
+	desc := fmt.Sprintf("%s (%s)", f.Name, f.GoType)
+	if f.Env != "" {
+		desc += fmt.Sprintf(", env: %s", f.Env)
+	}
+	return desc
+}
+
+// SampleTOML 生成带注释的 application.toml 示例, 配置值为对象的默认值
+func (s *ConfigSchema) SampleTOML() string {
+	b := &strings.Builder{}
+	b.WriteString("# 配置文件示例, 配置值为对象的默认值\n")
+	for _, section := range s.Sections {
+		b.WriteString("\n")
+		for _, obj := range section.Objects {
+			fmt.Fprintf(b, "# %s\n", obj)
+		}
+		writeTOMLTable(b, section.Name, section.Fields)
+	}
+	return b.String()
+}
+
+func writeTOMLTable(b *strings.Builder, table string, fields []*ConfigField) {
+	fmt.Fprintf(b, "[%s]\n", table)
+
+	// 普通字段需要写在子表之前
+	var tables []*ConfigField
+	for _, f := range fields {
+		if len(f.Fields) > 0 {
+			tables = append(tables, f)
+			continue
+		}
+		fmt.Fprintf(b, "# %s\n", f.description())
+		value, ok := tomlLiteral(f.Default)
+		if !ok {
+			fmt.Fprintf(b, "# %s =\n", tomlKey(f.Key("toml")))
+			continue
+		}
+		fmt.Fprintf(b, "%s = %s\n", tomlKey(f.Key("toml")), value)
+	}
+	for _, f := range tables {
+		b.WriteString("\n")
+		fmt.Fprintf(b, "# %s\n", f.description())
+		writeTOMLTable(b, table+"."+tomlKey(f.Key("toml")), f.Fields)
+	}
+}
+
+// tomlLiteral 返回值的 TOML 表示, map 使用内联表
+func tomlLiteral(v any) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+	rv := reflect.ValueOf(v)
+	// 加载配置时 time.Duration 按照整数(纳秒)解码
+	if rv.Type() == durationType {
+		return strconv.FormatInt(rv.Int(), 10), true
+	}
+	if rv.Kind() == reflect.Map {
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		items := make([]string, 0, len(keys))
+		for _, k := range keys {
+			value, ok := tomlLiteral(rv.MapIndex(k).Interface())
+			if !ok {
+				return "", false
+			}
+			items = append(items, fmt.Sprintf("%s = %s", tomlKey(fmt.Sprint(k.Interface())), value))
+		}
+		if len(items) == 0 {
+			return "{}", true
+		}
+		return "{ " + strings.Join(items, ", ") + " }", true
+	}
+
+	out, err := toml.Marshal(map[string]any{"v": v})
+	if err != nil {
+		return "", false
+	}
+	line := strings.TrimSpace(string(out))
+	if !strings.HasPrefix(line, "v = ") || strings.Contains(line, "\n") {
+		return "", false
+	}
+	return strings.TrimPrefix(line, "v = "), true
+}
+
+// tomlKey 非裸键需要加引号
+func tomlKey(key string) string {
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return strconv.Quote(key)
+		}
+	}
+	if key == "" {
+		return `""`
+	}
+	return key
+}
+
+// ConfigIssue 配置校验发现的问题
+type ConfigIssue struct {
+	// 配置段
+	Section string
+	// 配置键, 嵌套的键使用 . 连接
+	Key string
+	// 问题描述
+	Message string
+}
+
+func (i ConfigIssue) String() string {
+	if i.Key == "" {
+		return fmt.Sprintf("[%s] %s", i.Section, i.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %s", i.Section, i.Key, i.Message)
+}
+
+// Validate 校验配置内容, 返回未知的配置段、未知的配置键以及类型不匹配的配置
+// format 为配置格式: .toml, .yaml, .yml, .json
+func (s *ConfigSchema) Validate(content []byte, format string) ([]ConfigIssue, error) {
+	data, tagName, err := parseFileContent(content, format)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := map[string]*ConfigSection{}
+	for _, section := range s.Sections {
+		sections[section.Name] = section
+	}
+
+	var issues []ConfigIssue
+	for _, name := range sortedKeys(data) {
+		section, ok := sections[name]
+		if !ok {
+			issues = append(issues, ConfigIssue{Section: name, Message: "section matches no registered object"})
+			continue
+		}
+		values, ok := data[name].(map[string]any)
+		if !ok {
+			issues = append(issues, ConfigIssue{Section: name, Message: fmt.Sprintf("expect table, got %T", data[name])})
+			continue
+		}
+		issues = append(issues, validateFields(name, "", values, section.Fields, tagName)...)
+	}
+	return issues, nil
+}
+
+func validateFields(section, prefix string, values map[string]any, fields []*ConfigField, tagName string) []ConfigIssue {
+	var issues []ConfigIssue
+	for _, key := range sortedKeys(values) {
+		var field *ConfigField
+		for _, f := range fields {
+			if strings.EqualFold(f.Key(tagName), key) {
+				field = f
+				break
+			}
+		}
+
+		path := prefix + key
+		if field == nil {
+			issues = append(issues, ConfigIssue{Section: section, Key: path, Message: "unknown key"})
+			continue
+		}
+
+		value := values[key]
+		if len(field.Fields) > 0 {
+			nested, ok := value.(map[string]any)
+			if !ok {
+				issues = append(issues, ConfigIssue{Section: section, Key: path, Message: fmt.Sprintf("type mismatch, expect %s, got %T", field.GoType, value)})
+				continue
+			}
+			issues = append(issues, validateFields(section, path+".", nested, field.Fields, tagName)...)
+			continue
+		}
+
+		// 使用与加载配置相同的解码规则检查类型
+		target := reflect.New(field.typ)
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			Result:           target.Interface(),
+			TagName:          tagName,
+			Squash:           true,
+			WeaklyTypedInput: true,
+			MatchName:        strings.EqualFold,
+		})
+		if err == nil {
+			err = decoder.Decode(value)
+		}
+		if err != nil {
+			issues = append(issues, ConfigIssue{Section: section, Key: path, Message: fmt.Sprintf("type mismatch, expect %s, got %T", field.GoType, value)})
+		}
+	}
+	return issues
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,167 @@
+package ioc
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type schemaTestTLS struct {
+	Enable bool   `toml:"enable" env:"ENABLE"`
+	Cert   string `toml:"cert" env:"CERT"`
+}
+
+// SchemaTestConfig 用于配置结构导出测试的对象
+type SchemaTestConfig struct {
+	ObjectImpl
+	Host    string            `toml:"host" json:"host" yaml:"host" env:"HOST"`
+	Port    int               `toml:"port" json:"port" yaml:"port" env:"PORT"`
+	Timeout time.Duration     `toml:"timeout" env:"TIMEOUT"`
+	Tags    []string          `toml:"tags"`
+	Labels  map[string]string `toml:"labels"`
+	TLS     schemaTestTLS     `toml:"tls" envPrefix:"TLS_"`
+	Ignored string            `toml:"-"`
+
+	Dep    *SchemaTestConfig `ioc:"autowire=true"`
+	secret string
+}
+
+func (c *SchemaTestConfig) Name() string { return "schema_test" }
+
+func newSchemaTestStore() *defaultStore {
+	store := &defaultStore{
+		store: []*NamespaceStore{newNamespaceStore("schema_test")},
+	}
+	store.Namespace("schema_test").Registry(&SchemaTestConfig{
+		Host:    "127.0.0.1",
+		Port:    8080,
+		Timeout: 5 * time.Second,
+		Tags:    []string{"a"},
+		Labels:  map[string]string{"env": "dev"},
+	})
+	return store
+}
+
+func TestConfigSchema(t *testing.T) {
+	schema := newSchemaTestStore().ConfigSchema("APP")
+	if len(schema.Sections) != 1 {
+		t.Fatalf("unexpected sections %+v", schema.Sections)
+	}
+	section := schema.Sections[0]
+	if section.Name != "schema_test" || section.Objects[0] != "schema_test:*ioc.SchemaTestConfig(v1)" {
+		t.Fatalf("unexpected section %+v", section)
+	}
+
+	keys := []string{}
+	for _, f := range section.Fields {
+		keys = append(keys, f.Key("toml"))
+	}
+	if strings.Join(keys, ",") != "host,port,timeout,tags,labels,tls" {
+		t.Fatalf("unexpected fields %v", keys)
+	}
+
+	port := section.Fields[1]
+	if port.Env != "APP_SCHEMA_TEST_PORT" || port.Type != "integer" || port.Default != 8080 {
+		t.Fatalf("unexpected port field %+v", port)
+	}
+	tls := section.Fields[5]
+	if len(tls.Fields) != 2 || tls.Fields[1].Env != "APP_SCHEMA_TEST_TLS_CERT" {
+		t.Fatalf("unexpected nested field %+v", tls.Fields)
+	}
+
+	out, err := schema.JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]any{}
+	if err := json.Unmarshal(out, &data); err != nil {
+		t.Fatal(err)
+	}
+	props := data["properties"].(map[string]any)["schema_test"].(map[string]any)["properties"].(map[string]any)
+	if props["host"].(map[string]any)["default"] != "127.0.0.1" {
+		t.Fatalf("unexpected json schema %s", out)
+	}
+}
+
+func TestConfigSchemaSampleTOML(t *testing.T) {
+	schema := newSchemaTestStore().ConfigSchema("")
+	sample := schema.SampleTOML()
+	t.Log(sample)
+
+	for _, line := range []string{
+		"[schema_test]",
+		`host = "127.0.0.1"`,
+		"timeout = 5000000000",
+		`labels = { env = "dev" }`,
+		"[schema_test.tls]",
+		"# Cert (string), env: SCHEMA_TEST_TLS_CERT",
+	} {
+		if !strings.Contains(sample, line) {
+			t.Fatalf("sample should contain %q", line)
+		}
+	}
+
+	if strings.Contains(sample, " \n") {
+		t.Fatal("sample should not contain trailing spaces")
+	}
+
+	// 示例配置本身应该通过校验
+	issues, err := schema.Validate([]byte(sample), ".toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) > 0 {
+		t.Fatalf("sample should be valid, got %v", issues)
+	}
+}
+
+func TestConfigSchemaDefaults(t *testing.T) {
+	store := newSchemaTestStore()
+	conf := store.Namespace("schema_test").Get("schema_test").(*SchemaTestConfig)
+	// 加载配置后的值不会作为默认值导出
+	conf.Host = "secret-host"
+	conf.Labels["token"] = "secret"
+
+	schema := store.ConfigSchema("")
+	host := schema.Sections[0].Fields[0]
+	if host.Default != "127.0.0.1" {
+		t.Fatalf("default should come from registration, got %v", host.Default)
+	}
+	if sample := schema.SampleTOML(); strings.Contains(sample, "secret") {
+		t.Fatalf("sample should not contain loaded values:\n%s", sample)
+	}
+}
+
+func TestConfigSchemaValidate(t *testing.T) {
+	schema := newSchemaTestStore().ConfigSchema("")
+	issues, err := schema.Validate([]byte(`
+[schema_test]
+HOST = "localhost"
+port = "abc"
+unknown = 1
+[schema_test.tls]
+enable = "true"
+key = "x"
+
+[not_registered]
+a = 1
+`), ".toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		"[not_registered] section matches no registered object",
+		"[schema_test] port: type mismatch, expect int, got string",
+		"[schema_test] tls.key: unknown key",
+		"[schema_test] unknown: unknown key",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected issues:\n%s", strings.Join(got, "\n"))
+	}
+}
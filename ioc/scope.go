@@ -0,0 +1,301 @@
+package ioc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Scope 对象的生命周期范围
+type Scope string
+
+const (
+	// SCOPE_SINGLETON 单例, 整个进程共享同一个实例（默认）
+	SCOPE_SINGLETON Scope = "singleton"
+	// SCOPE_PROTOTYPE 原型, 每次 Get/自动注入 都会创建新的实例
+	SCOPE_PROTOTYPE Scope = "prototype"
+	// SCOPE_REQUEST 请求范围, 同一个请求范围(context.Context)内共享同一个实例, 范围结束时关闭
+	SCOPE_REQUEST Scope = "request"
+)
+
+// ObjectFactory 对象工厂, 用于创建非单例对象的实例
+type ObjectFactory func() Object
+
+// WithContext 指定获取对象时使用的上下文
+// 获取请求范围(SCOPE_REQUEST)的对象时, 需要通过该上下文找到所属的请求范围
+func WithContext(ctx context.Context) GetOption {
+	return func(o *option) {
+		o.ctx = ctx
+	}
+}
+
+// RegistryFactory 通过工厂注册对象, 并指定对象的生命周期范围
+// 注册时会调用一次工厂创建模板对象, 用于获取对象的名称、版本、优先级以及加载配置
+// 使用示例:
+//
+//	ioc.Controller().RegistryFactory(func() ioc.Object { return &UnitOfWork{} }, ioc.SCOPE_REQUEST)
+func (s *NamespaceStore) RegistryFactory(factory ObjectFactory, scope Scope) StoreUser {
+	switch scope {
+	case "", SCOPE_SINGLETON:
+		return s.Registry(factory())
+	case SCOPE_PROTOTYPE, SCOPE_REQUEST:
+	default:
+		panic(fmt.Sprintf("ioc: unknown scope %q", scope))
+	}
+
+	template := factory()
+	if reflect.TypeOf(template).Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("ioc: factory of scope %s must return a pointer, got %T", scope, template))
+	}
+	return s.registry(template, func(w *ObjectWrapper) {
+		w.Scope = scope
+		w.factory = factory
+	})
+}
+
+// container 返回对象所属的容器
+func (w *ObjectWrapper) container() *defaultStore {
+	if w.ns != nil {
+		return w.ns.owner()
+	}
+	return DefaultStore
+}
+
+// IsSingleton 是否为单例对象
+func (w *ObjectWrapper) IsSingleton() bool {
+	return w.Scope == "" || w.Scope == SCOPE_SINGLETON
+}
+
+// addConfigurer 记录对象的配置加载过程, 非单例对象创建新实例时重放
+func (w *ObjectWrapper) addConfigurer(fn func(Object) error) {
+	if w.IsSingleton() {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.configurers = append(w.configurers, fn)
+}
+
+// resetConfigurers 重新加载配置前清空已记录的配置加载过程
+func (w *ObjectWrapper) resetConfigurers() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.configurers = nil
+}
+
+// newInstance 创建非单例对象的新实例: 加载配置 -> 依赖注入 -> 初始化
+func (w *ObjectWrapper) newInstance(ctx context.Context) (Object, error) {
+	obj := w.factory()
+
+	w.mu.Lock()
+	configurers := w.configurers
+	w.mu.Unlock()
+	for _, configure := range configurers {
+		if err := configure(obj); err != nil {
+			return nil, fmt.Errorf("config %s error, %w", w.Name, err)
+		}
+	}
+
+	if errs := autowireObject(ctx, w.container(), obj, w.Scope); len(errs) > 0 {
+		return nil, fmt.Errorf("autowire %s error, %v", w.Name, errs)
+	}
+
+	if hook, ok := obj.(PostConfigHook); ok {
+		if err := hook.OnPostConfig(); err != nil {
+			return nil, fmt.Errorf("PostConfig hook failed for %s: %w", w.Name, err)
+		}
+	}
+	if hook, ok := obj.(PreInitHook); ok {
+		if err := hook.OnPreInit(); err != nil {
+			return nil, fmt.Errorf("PreInit hook failed for %s: %w", w.Name, err)
+		}
+	}
+	if err := obj.Init(); err != nil {
+		return nil, fmt.Errorf("init object %s error, %s", w.Name, err)
+	}
+	if hook, ok := obj.(PostInitHook); ok {
+		if err := hook.OnPostInit(); err != nil {
+			debug("PostInit hook failed for %s: %v", w.Name, err)
+		}
+	}
+	return obj, nil
+}
+
+// resolve 根据对象的生命周期范围返回实例
+func (w *ObjectWrapper) resolve(ctx context.Context) (Object, error) {
+	switch w.Scope {
+	case SCOPE_PROTOTYPE:
+		return w.newInstance(ctx)
+	case SCOPE_REQUEST:
+		scope := requestScopeFromCtx(ctx)
+		if scope == nil {
+			return nil, fmt.Errorf("object %s is request scoped, but no request scope found in context", w.Name)
+		}
+		return scope.get(ctx, w)
+	default:
+		// 通过构造函数注册的对象, 返回构造函数创建的对象
+		var value any
+		var err error
+		switch u := w.Value.(type) {
+		case contextUnwrapper:
+			value, err = u.unwrap(ctx)
+		case Unwrapper:
+			value, err = u.Unwrap()
+		default:
+			return w.Value, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if obj, ok := value.(Object); ok {
+			return obj, nil
+		}
+		return w.Value, nil
+	}
+}
+
+// checkInjectScope 请求范围对象只能注入到非单例对象中, 单例对象的生命周期比请求范围长
+func checkInjectScope(owner Scope, dep *ObjectWrapper) error {
+	if dep.Scope == SCOPE_REQUEST && (owner == "" || owner == SCOPE_SINGLETON) {
+		return fmt.Errorf("request scoped object %s can not be injected into singleton object, use ioc.FromCtx to get it per request", dep.Name)
+	}
+	return nil
+}
+
+type requestScopeKey struct{}
+
+// requestScope 请求范围, 保存该范围内创建的对象
+type requestScope struct {
+	// 范围所属的容器, FromCtx 在该容器中查找对象
+	store   *defaultStore
+	mu      sync.Mutex
+	objects map[*ObjectWrapper]Object
+	order   []*ObjectWrapper
+	closed  bool
+}
+
+func requestScopeFromCtx(ctx context.Context) *requestScope {
+	if ctx == nil {
+		return nil
+	}
+	scope, _ := ctx.Value(requestScopeKey{}).(*requestScope)
+	return scope
+}
+
+// WithRequestScope 创建一个请求范围
+// 范围内的请求范围对象只会创建一次, 调用返回的 closeScope 时按创建顺序倒序关闭
+// 使用示例:
+//
+//	ctx, closeScope := ioc.WithRequestScope(r.Context())
+//	defer closeScope()
+//	uow, err := ioc.FromCtx[*UnitOfWork](ctx)
+func WithRequestScope(ctx context.Context) (context.Context, func()) {
+	return DefaultStore.WithRequestScope(ctx)
+}
+
+// WithRequestScope 创建一个属于该容器的请求范围, FromCtx 会在该容器中查找对象
+func (s *defaultStore) WithRequestScope(ctx context.Context) (context.Context, func()) {
+	scope := &requestScope{
+		store:   s,
+		objects: map[*ObjectWrapper]Object{},
+	}
+	return context.WithValue(ctx, requestScopeKey{}, scope), func() {
+		scope.close(ctx)
+	}
+}
+
+func (r *requestScope) get(ctx context.Context, w *ObjectWrapper) (Object, error) {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("request scope has been closed")
+	}
+	if obj, ok := r.objects[w]; ok {
+		r.mu.Unlock()
+		return obj, nil
+	}
+	r.mu.Unlock()
+
+	// 在锁外创建对象, 对象依赖的其他请求范围对象也会从该范围获取
+	obj, err := w.newInstance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if exist, ok := r.objects[w]; ok {
+		// 并发创建时保留先创建的对象
+		obj.Close(ctx)
+		return exist, nil
+	}
+	r.objects[w] = obj
+	r.order = append(r.order, w)
+	return obj, nil
+}
+
+func (r *requestScope) close(ctx context.Context) {
+	r.mu.Lock()
+	r.closed = true
+	order, objects := r.order, r.objects
+	r.order, r.objects = nil, map[*ObjectWrapper]Object{}
+	r.mu.Unlock()
+
+	for i := len(order) - 1; i >= 0; i-- {
+		obj := objects[order[i]]
+		if hook, ok := obj.(PreStopHook); ok {
+			if err := hook.OnPreStop(ctx); err != nil {
+				debug("PreStop hook failed for %s: %v", order[i].Name, err)
+			}
+		}
+		obj.Close(ctx)
+		if hook, ok := obj.(PostStopHook); ok {
+			if err := hook.OnPostStop(ctx); err != nil {
+				debug("PostStop hook failed for %s: %v", order[i].Name, err)
+			}
+		}
+	}
+}
+
+// FromCtx 泛型方式从请求上下文中获取对象
+// 会在请求范围所属容器(没有请求范围时为 DefaultStore)的所有命名空间中查找类型名称对应的对象,
+// 请求范围对象在同一个请求范围内只创建一次
+// 使用示例:
+//
+//	uow, err := ioc.FromCtx[*UnitOfWork](ctx)
+func FromCtx[T Object](ctx context.Context, opts ...GetOption) (T, error) {
+	var zero T
+
+	t := reflect.TypeOf(zero)
+	if t == nil {
+		return zero, fmt.Errorf("cannot get type information for nil")
+	}
+	name := t.String()
+
+	store := DefaultStore
+	if scope := requestScopeFromCtx(ctx); scope != nil && scope.store != nil {
+		store = scope.store
+	}
+
+	opt := defaultOption().Apply(opts...)
+	for _, ns := range store.store {
+		w, err := ResolveVersion(ns.versions(name), opt.version)
+		if err != nil {
+			return zero, err
+		}
+		if w == nil {
+			continue
+		}
+		obj, err := w.resolve(ctx)
+		if err != nil {
+			return zero, err
+		}
+		result, ok := obj.(T)
+		if !ok {
+			return zero, fmt.Errorf("type mismatch: want %T, got %T", zero, obj)
+		}
+		return result, nil
+	}
+	return zero, fmt.Errorf("object %s not found in store", name)
+}
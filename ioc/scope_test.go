@@ -0,0 +1,177 @@
+package ioc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/infraboard/mcube/v2/ioc"
+)
+
+// ScopeCounter 记录实例创建与关闭的次数
+type ScopeCounter struct {
+	created int
+	closed  int
+}
+
+// PrototypeObject 原型对象
+type PrototypeObject struct {
+	ioc.ObjectImpl
+	counter *ScopeCounter
+	Inited  bool
+}
+
+func (o *PrototypeObject) Name() string { return "scope_prototype" }
+
+func (o *PrototypeObject) Init() error {
+	o.counter.created++
+	o.Inited = true
+	return nil
+}
+
+// UnitOfWork 请求范围对象
+type UnitOfWork struct {
+	ioc.ObjectImpl
+	counter *ScopeCounter
+	Dsn     string `env:"DSN"`
+}
+
+func (u *UnitOfWork) Init() error {
+	u.counter.created++
+	return nil
+}
+
+func (u *UnitOfWork) Close(ctx context.Context) {
+	u.counter.closed++
+}
+
+// UnitOfWorkUser 依赖请求范围对象的原型对象
+type UnitOfWorkUser struct {
+	ioc.ObjectImpl
+	Uow *UnitOfWork `ioc:"autowire=true;namespace=scope_test"`
+}
+
+func (u *UnitOfWorkUser) Name() string { return "scope_uow_user" }
+
+func TestPrototypeScope(t *testing.T) {
+	ns := ioc.DefaultStore.Namespace("scope_prototype_test")
+	counter := &ScopeCounter{}
+	ns.RegistryFactory(func() ioc.Object {
+		return &PrototypeObject{counter: counter}
+	}, ioc.SCOPE_PROTOTYPE)
+
+	if err := ns.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if counter.created != 0 {
+		t.Fatalf("prototype template should not be initialized, created=%d", counter.created)
+	}
+
+	a := ns.Get("scope_prototype").(*PrototypeObject)
+	b := ns.Get("scope_prototype").(*PrototypeObject)
+	if a == b {
+		t.Fatal("prototype should return a new instance on every Get")
+	}
+	if !a.Inited || !b.Inited || counter.created != 2 {
+		t.Fatalf("prototype instances should be initialized, created=%d", counter.created)
+	}
+}
+
+func TestRequestScope(t *testing.T) {
+	ns := ioc.DefaultStore.Namespace("scope_test")
+	counter := &ScopeCounter{}
+	ns.RegistryFactory(func() ioc.Object {
+		return &UnitOfWork{counter: counter}
+	}, ioc.SCOPE_REQUEST)
+	ns.RegistryFactory(func() ioc.Object {
+		return &UnitOfWorkUser{}
+	}, ioc.SCOPE_PROTOTYPE)
+
+	t.Setenv("*IOC_TEST.UNITOFWORK_DSN", "memory")
+	if err := ns.LoadFromEnv(""); err != nil {
+		t.Fatal(err)
+	}
+
+	// 没有请求范围时无法获取
+	if _, err := ioc.FromCtx[*UnitOfWork](context.Background()); err == nil {
+		t.Fatal("expect error without request scope")
+	}
+
+	ctx, closeScope := ioc.WithRequestScope(context.Background())
+	uow1, err := ioc.FromCtx[*UnitOfWork](ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uow2, err := ioc.FromCtx[*UnitOfWork](ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uow1 != uow2 {
+		t.Fatal("request scoped object should be shared in the same scope")
+	}
+	if uow1.Dsn != "memory" {
+		t.Fatalf("request scoped object should be configured, got dsn %q", uow1.Dsn)
+	}
+
+	// 原型对象注入同一请求范围内的对象
+	user := ns.Get("scope_uow_user", ioc.WithContext(ctx)).(*UnitOfWorkUser)
+	if user.Uow != uow1 {
+		t.Fatal("prototype should be autowired with the request scoped object")
+	}
+
+	// 不同请求范围创建不同实例
+	ctx2, closeScope2 := ioc.WithRequestScope(context.Background())
+	uow3, err := ioc.FromCtx[*UnitOfWork](ctx2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uow3 == uow1 {
+		t.Fatal("different request scope should create a new instance")
+	}
+
+	closeScope()
+	closeScope2()
+	if counter.created != 2 || counter.closed != 2 {
+		t.Fatalf("expect 2 created and 2 closed, got created=%d closed=%d", counter.created, counter.closed)
+	}
+
+	if _, err := ioc.FromCtx[*UnitOfWork](ctx); err == nil {
+		t.Fatal("expect error after scope closed")
+	}
+}
+
+// UnitOfWorkHolder 依赖请求范围对象的单例对象
+type UnitOfWorkHolder struct {
+	ioc.ObjectImpl
+	Uow *UnitOfWork `ioc:"autowire=true;namespace=scope_singleton_test"`
+}
+
+func (u *UnitOfWorkHolder) Name() string { return "scope_uow_holder" }
+
+func TestRequestScopeInjectIntoSingleton(t *testing.T) {
+	ns := ioc.DefaultStore.Namespace("scope_singleton_test")
+	ns.RegistryFactory(func() ioc.Object {
+		return &UnitOfWork{counter: &ScopeCounter{}}
+	}, ioc.SCOPE_REQUEST)
+	ns.Registry(&UnitOfWorkHolder{})
+
+	if err := ns.Autowire(); err == nil {
+		t.Fatal("expect error when injecting request scoped object into singleton")
+	}
+}
+
+func TestContainerRequestScope(t *testing.T) {
+	c := ioc.NewContainer()
+	counter := &ScopeCounter{}
+	c.Default().RegistryFactory(func() ioc.Object {
+		return &UnitOfWork{counter: counter}
+	}, ioc.SCOPE_REQUEST)
+
+	ctx, closeScope := c.WithRequestScope(context.Background())
+	defer closeScope()
+	if _, err := ioc.FromCtx[*UnitOfWork](ctx); err != nil {
+		t.Fatal(err)
+	}
+	if counter.created != 1 {
+		t.Fatalf("expect object created by the container, got created=%d", counter.created)
+	}
+}
@@ -50,6 +50,7 @@ var encryptCmd = &cobra.Command{
 		cipherText, err := application.Get().EncryptString(planText)
 		cobra.CheckErr(err)
 		fmt.Printf("加密完成: 明文[%s] -> 密文[%s]\n", planText, cipherText)
+		fmt.Printf("配置文件中使用: %s%s\n", application.Get().CipherPrefix, cipherText)
 
 	},
 }
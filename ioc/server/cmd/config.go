@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/infraboard/mcube/v2/ioc"
+	"github.com/infraboard/mcube/v2/ioc/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	schemaFormat string
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "配置工具",
+	// 配置工具只需要已注册的对象, 不加载配置也不初始化对象
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		ioc.SetDebug(debug)
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "导出所有对象的配置结构(JSON Schema 或者 application.toml 示例)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema := ioc.ExportConfigSchema(server.DefaultConfig.ConfigEnv.Prefix)
+		switch schemaFormat {
+		case "json":
+			out, err := schema.JSONSchema()
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+		case "toml":
+			fmt.Print(schema.SampleTOML())
+		default:
+			return fmt.Errorf("unsupported format %s, support json/toml", schemaFormat)
+		}
+		return nil
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate <file>",
+	Short: "校验配置文件: 未知的配置段、未知的配置键以及类型不匹配的配置",
+	Args:  cobra.ExactArgs(1),
+	// 校验失败时只输出问题列表
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := args[0]
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		schema := ioc.ExportConfigSchema(server.DefaultConfig.ConfigEnv.Prefix)
+		issues, err := schema.Validate(content, filepath.Ext(path))
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if len(issues) == 0 {
+			fmt.Printf("配置校验通过: %s\n", path)
+			return nil
+		}
+
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+		return fmt.Errorf("found %d problems in %s", len(issues), path)
+	},
+}
+
+func init() {
+	configSchemaCmd.Flags().StringVarP(&schemaFormat, "format", "o", "toml", "the schema format [json/toml]")
+	configCmd.AddCommand(configSchemaCmd)
+	configCmd.AddCommand(configValidateCmd)
+	Root.AddCommand(configCmd)
+}
@@ -23,11 +23,21 @@ var Root = &cobra.Command{
 		if vers {
 			return nil
 		}
+		// start 命令指定的环境优先于环境变量 MCUBE_PROFILES
+		if len(profiles) > 0 {
+			ioc.SetProfiles(profiles...)
+		}
+
 		req := server.DefaultConfig
+		if pluginDir != "" {
+			req.PluginDir = pluginDir
+		}
 		switch confType {
 		case "file":
 			req.ConfigFile.Enabled = true
 			req.ConfigFile.Paths = confFiles
+			// 按激活的环境叠加配置文件: application.toml -> application-{profile}.toml -> application-local.toml
+			req.ConfigFile.Profile = true
 		default:
 			req.ConfigEnv.Enabled = true
 		}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"github.com/infraboard/mcube/v2/ioc"
 	"github.com/infraboard/mcube/v2/ioc/server"
 	"github.com/spf13/cobra"
 )
@@ -14,6 +15,13 @@ var startCmd = &cobra.Command{
 	},
 }
 
+var (
+	profiles  []string
+	pluginDir string
+)
+
 func init() {
+	startCmd.Flags().StringSliceVarP(&profiles, "profile", "p", nil, "the active profiles, override env "+ioc.PROFILES_ENV)
+	startCmd.Flags().StringVar(&pluginDir, "plugin-dir", "", "the go plugin(.so) dir, override env "+ioc.PLUGIN_DIR_ENV)
 	Root.AddCommand(startCmd)
 }
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"time"
+
+	"github.com/infraboard/mcube/v2/ioc"
+)
+
+const (
+	AppName = "server"
+)
+
+func init() {
+	ioc.Config().Registry(defaultConfig)
+}
+
+var defaultConfig = &Config{
+	ShutdownTimeoutSecond:   30,
+	PreStopDelaySecond:      0,
+	ObjectStopTimeoutSecond: 5,
+}
+
+func Get() *Config {
+	obj := ioc.Config().Get(AppName)
+	if obj == nil {
+		return defaultConfig
+	}
+	return obj.(*Config)
+}
+
+type Config struct {
+	ioc.ObjectImpl
+
+	// 优雅关闭的总超时时间, 包括等待负载均衡摘除流量、排空请求以及关闭ioc对象, 小于等于0时不限制
+	ShutdownTimeoutSecond int `json:"shutdown_timeout" yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	// 标记为未就绪后, 等待负载均衡摘除流量的时间
+	PreStopDelaySecond int `json:"pre_stop_delay" yaml:"pre_stop_delay" toml:"pre_stop_delay" env:"PRE_STOP_DELAY"`
+	// 单个ioc对象关闭钩子(OnPreStop/Close/OnPostStop)的超时时间
+	ObjectStopTimeoutSecond int `json:"object_stop_timeout" yaml:"object_stop_timeout" toml:"object_stop_timeout" env:"OBJECT_STOP_TIMEOUT"`
+	// 初始化对象的并发数, 大于1时没有依赖关系的对象并发初始化, 默认按顺序初始化
+	InitParallelism int `json:"init_parallelism" yaml:"init_parallelism" toml:"init_parallelism" env:"INIT_PARALLELISM"`
+}
+
+func (c *Config) Name() string {
+	return AppName
+}
+
+func (c *Config) ShutdownTimeout() time.Duration {
+	return time.Duration(c.ShutdownTimeoutSecond) * time.Second
+}
+
+func (c *Config) PreStopDelay() time.Duration {
+	return time.Duration(c.PreStopDelaySecond) * time.Second
+}
+
+func (c *Config) ObjectStopTimeout() time.Duration {
+	return time.Duration(c.ObjectStopTimeoutSecond) * time.Second
+}
@@ -0,0 +1,67 @@
+package lifecycle
+
+import (
+	"sync"
+)
+
+// Phase 服务运行阶段
+type Phase string
+
+const (
+	// 加载配置, 注入依赖
+	PHASE_CONFIGURING Phase = "configuring"
+	// 初始化对象, 绑定监听地址
+	PHASE_INITIALIZING Phase = "initializing"
+	// 所有监听都已就绪, 正在提供服务
+	PHASE_SERVING Phase = "serving"
+	// 收到退出信号, 停止接收新请求, 处理存量请求
+	PHASE_DRAINING Phase = "draining"
+	// 服务已停止
+	PHASE_STOPPED Phase = "stopped"
+)
+
+// PhaseChangeHandler 阶段变化回调, 回调在切换阶段的 goroutine 中同步执行
+type PhaseChangeHandler func(from, to Phase)
+
+var (
+	mu       sync.RWMutex
+	current  Phase
+	handlers []PhaseChangeHandler
+)
+
+// Current 当前阶段, 未通过 server 启动时为空
+func Current() Phase {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// IsReady 所有监听都已就绪, 可以接收流量
+func IsReady() bool {
+	return Current() == PHASE_SERVING
+}
+
+// OnPhaseChange 订阅阶段变化
+func OnPhaseChange(fn PhaseChangeHandler) {
+	mu.Lock()
+	defer mu.Unlock()
+	handlers = append(handlers, fn)
+}
+
+// SetPhase 切换阶段并通知订阅者, 阶段未变化时不通知
+func SetPhase(p Phase) {
+	mu.Lock()
+	from := current
+	if from == p {
+		mu.Unlock()
+		return
+	}
+	current = p
+	hs := make([]PhaseChangeHandler, len(handlers))
+	copy(hs, handlers)
+	mu.Unlock()
+
+	for _, fn := range hs {
+		fn(from, p)
+	}
+}
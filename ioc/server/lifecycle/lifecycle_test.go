@@ -0,0 +1,48 @@
+package lifecycle_test
+
+import (
+	"testing"
+
+	"github.com/infraboard/mcube/v2/ioc/server/lifecycle"
+)
+
+func TestPhaseChange(t *testing.T) {
+	// 阶段为全局状态, 重置后再订阅, 避免受其他测试或者重复执行(-count)的影响
+	lifecycle.SetPhase("")
+	t.Cleanup(func() { lifecycle.SetPhase("") })
+
+	changes := []string{}
+	lifecycle.OnPhaseChange(func(from, to lifecycle.Phase) {
+		changes = append(changes, string(from)+"->"+string(to))
+	})
+
+	if lifecycle.IsReady() {
+		t.Fatal("should not be ready before serving")
+	}
+	lifecycle.SetPhase(lifecycle.PHASE_CONFIGURING)
+	lifecycle.SetPhase(lifecycle.PHASE_INITIALIZING)
+	lifecycle.SetPhase(lifecycle.PHASE_INITIALIZING)
+	lifecycle.SetPhase(lifecycle.PHASE_SERVING)
+	if !lifecycle.IsReady() {
+		t.Fatal("should be ready when serving")
+	}
+	lifecycle.SetPhase(lifecycle.PHASE_DRAINING)
+	if lifecycle.IsReady() {
+		t.Fatal("should not be ready when draining")
+	}
+
+	want := []string{
+		"->configuring",
+		"configuring->initializing",
+		"initializing->serving",
+		"serving->draining",
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("unexpected changes %v", changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("unexpected changes %v", changes)
+		}
+	}
+}
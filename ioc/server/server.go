@@ -2,16 +2,23 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/infraboard/mcube/v2/ioc"
-	"github.com/infraboard/mcube/v2/ioc/config/grpc"
-	"github.com/infraboard/mcube/v2/ioc/config/http"
-	"github.com/infraboard/mcube/v2/ioc/config/jsonrpc"
 	"github.com/infraboard/mcube/v2/ioc/config/log"
+	"github.com/infraboard/mcube/v2/ioc/server/lifecycle"
 	"github.com/rs/zerolog"
+
+	// 内置服务
+	_ "github.com/infraboard/mcube/v2/ioc/config/grpc"
+	_ "github.com/infraboard/mcube/v2/ioc/config/http"
+	_ "github.com/infraboard/mcube/v2/ioc/config/jsonrpc"
 )
 
 var (
@@ -34,11 +41,10 @@ type Server struct {
 	ioc.ObjectImpl
 	setupHook func()
 
-	http    *http.Http
-	grpc    *grpc.Grpc
-	jsonrpc *jsonrpc.JsonRpc
+	transports []Transport
 
 	ch     chan os.Signal
+	errs   chan error
 	log    *zerolog.Logger
 	ctx    context.Context
 	cancle context.CancelFunc
@@ -55,21 +61,34 @@ func (s *Server) setup() {
 	signal.Notify(s.ch, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP, syscall.SIGQUIT)
 	s.ctx, s.cancle = context.WithCancel(context.Background())
 
-	s.http = http.Get()
-	s.grpc = grpc.Get()
-	s.jsonrpc = jsonrpc.Get()
 	s.log = log.Sub("server")
 	if s.setupHook != nil {
 		s.setupHook()
 	}
+	s.transports = Transports()
 }
 
 func (s *Server) Run(ctx context.Context) error {
 	// 初始化ioc
-	err := ioc.ConfigIocObject(DefaultConfig)
+	start := time.Now()
+	lifecycle.SetPhase(lifecycle.PHASE_CONFIGURING)
+	req := *DefaultConfig
+	req.BeforeInit = func() {
+		if n := Get().InitParallelism; n > 0 {
+			ioc.DefaultStore.SetInitParallelism(n)
+		}
+		if DefaultConfig.BeforeInit != nil {
+			DefaultConfig.BeforeInit()
+		}
+		lifecycle.SetPhase(lifecycle.PHASE_INITIALIZING)
+	}
+	err := ioc.ConfigIocObject(&req)
 	if err != nil {
+		lifecycle.SetPhase(lifecycle.PHASE_STOPPED)
 		return err
 	}
+	// 对象已经提前加载时不会执行 BeforeInit
+	lifecycle.SetPhase(lifecycle.PHASE_INITIALIZING)
 
 	// ioc setup
 	s.setup()
@@ -78,20 +97,65 @@ func (s *Server) Run(ctx context.Context) error {
 	ioc.DefaultStore.ForEatch(func(ns *ioc.NamespaceStore) {
 		s.log.Info().Msgf("loaded %s: %s", ns.Namespace, ns.List())
 	})
+	s.logInitStats(time.Since(start))
+	for _, t := range s.transports {
+		s.log.Info().Msgf("transport: %s", t.Name())
+	}
 
-	if s.http.IsEnable() {
-		go s.http.Start(ctx)
+	// 同步绑定监听, 任意一个失败则退出
+	if err := s.listen(); err != nil {
+		s.log.Error().Msg(err.Error())
+		s.shutdown()
+		return err
 	}
-	if s.grpc.IsEnable() {
-		go s.grpc.Start(ctx)
+	s.serve(ctx)
+
+	// 所有监听都已就绪
+	lifecycle.SetPhase(lifecycle.PHASE_SERVING)
+	return s.waitSign(ctx)
+}
+
+// logInitStats 输出对象的初始化耗时
+func (s *Server) logInitStats(cost time.Duration) {
+	stats := ioc.DefaultStore.InitStats()
+	for _, stat := range stats {
+		s.log.Info().Msgf("init %s", stat)
 	}
-	if s.jsonrpc.IsEnable() {
-		go s.jsonrpc.Start(ctx)
+	s.log.Info().Msgf("init %d objects in %s, parallelism: %d",
+		len(stats), cost.Round(time.Millisecond), ioc.DefaultStore.InitParallelism())
+}
+
+// listen 并行绑定所有服务的监听
+func (s *Server) listen() error {
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(s.transports))
+	)
+	for i, t := range s.transports {
+		l, ok := t.(TransportListener)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = l.Listen()
+		}()
 	}
-	s.waitSign()
+	wg.Wait()
+	return errors.Join(errs...)
+}
 
-	// TODO: 判断所有服务启动成功
-	return nil
+// serve 并行启动服务, 服务异常退出的错误通过 errs 返回给 Run
+func (s *Server) serve(ctx context.Context) {
+	s.errs = make(chan error, len(s.transports))
+	for _, t := range s.transports {
+		go func() {
+			if err := t.Start(ctx); err != nil {
+				s.errs <- fmt.Errorf("%s: %w", t.Name(), err)
+			}
+		}()
+	}
 }
 
 func (s *Server) HandleError(err error) {
@@ -100,38 +164,38 @@ func (s *Server) HandleError(err error) {
 	}
 }
 
-func (s *Server) waitSign() {
+func (s *Server) waitSign(ctx context.Context) error {
 	defer s.cancle()
 
-	for sg := range s.ch {
-		switch v := sg.(type) {
-		default:
-			s.log.Info().Msgf("receive signal '%v', start graceful shutdown", v.String())
-
-			if s.grpc.IsEnable() {
-				if err := s.grpc.Stop(s.ctx); err != nil {
-					s.log.Error().Msgf("grpc graceful shutdown err: %s, force exit", err)
-				} else {
-					s.log.Info().Msg("grpc service stop complete")
-				}
-			}
-
-			if s.http.IsEnable() {
-				if err := s.http.Stop(s.ctx); err != nil {
-					s.log.Error().Msgf("http graceful shutdown err: %s, force exit", err)
-				} else {
-					s.log.Info().Msgf("http service stop complete")
-				}
+	for {
+		select {
+		case sg := <-s.ch:
+			if sg == syscall.SIGHUP {
+				// SIGHUP 重新加载配置, 不退出
+				s.reloadConfig()
+				continue
 			}
-			if s.jsonrpc.IsEnable() {
-				if err := s.jsonrpc.Stop(s.ctx); err != nil {
-					s.log.Error().Msgf("jsonrpc graceful shutdown err: %s, force exit", err)
-				} else {
-					s.log.Info().Msgf("jsonrpc service stop complete")
-				}
-			}
-			ioc.DefaultStore.Stop(s.ctx)
-			return
+			s.log.Info().Msgf("receive signal '%v', start graceful shutdown", sg.String())
+			s.shutdown()
+			return nil
+		case err := <-s.errs:
+			s.log.Error().Msgf("serve error, %s, start graceful shutdown", err)
+			s.shutdown()
+			return err
+		case <-ctx.Done():
+			s.log.Info().Msg("context canceled, start graceful shutdown")
+			s.shutdown()
+			return nil
 		}
 	}
 }
+
+// reloadConfig 重新加载配置, 只有实现了 ioc.ConfigChangeHook 的对象会被通知
+func (s *Server) reloadConfig() {
+	s.log.Info().Msg("receive signal 'hangup', start reload config")
+	changed, err := ioc.ReloadConfig()
+	if err != nil {
+		s.log.Error().Msgf("reload config error, %s", err)
+	}
+	s.log.Info().Msgf("reload config complete, changed objects: %v", changed)
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/infraboard/mcube/v2/ioc"
+	"github.com/infraboard/mcube/v2/ioc/server/lifecycle"
+)
+
+// 再次收到退出信号时强制退出
+var forceExit = os.Exit
+
+// shutdown 优雅关闭, 所有阶段共享总超时时间:
+// 1. 标记为未就绪 2. 等待负载均衡摘除流量 3. 排空并关闭服务 4. 关闭ioc对象
+func (s *Server) shutdown() {
+	defer s.watchForceExit()()
+
+	conf := Get()
+	start := time.Now()
+	ctx, cancel := context.WithCancel(s.ctx)
+	if conf.ShutdownTimeout() > 0 {
+		ctx, cancel = context.WithTimeout(s.ctx, conf.ShutdownTimeout())
+	}
+	defer cancel()
+
+	// 1. 先标记为未就绪, 避免新的流量进入
+	s.log.Info().Msgf("[shutdown 1/4] mark not ready, shutdown timeout: %s", conf.ShutdownTimeout())
+	lifecycle.SetPhase(lifecycle.PHASE_DRAINING)
+
+	// 2. 等待负载均衡摘除流量
+	if delay := conf.PreStopDelay(); delay > 0 {
+		s.log.Info().Msgf("[shutdown 2/4] waiting %s for load balancers to stop sending traffic", delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+	} else {
+		s.log.Info().Msg("[shutdown 2/4] no pre stop delay")
+	}
+
+	// 3. 排空请求并关闭服务
+	s.log.Info().Msgf("[shutdown 3/4] draining transports: %s", s.transportNames())
+	slow := s.stopTransports(ctx)
+
+	// 4. 关闭ioc对象
+	s.log.Info().Msgf("[shutdown 4/4] closing ioc objects, hook timeout: %s, time left: %s",
+		conf.ObjectStopTimeout(), timeLeft(ctx))
+	for _, t := range ioc.DefaultStore.StopWithBudget(ctx, conf.ObjectStopTimeout()) {
+		slow = append(slow, t.String())
+	}
+	lifecycle.SetPhase(lifecycle.PHASE_STOPPED)
+
+	// 关闭汇总
+	if len(slow) == 0 {
+		s.log.Info().Msgf("shutdown complete in %s", time.Since(start).Round(time.Millisecond))
+		return
+	}
+	s.log.Warn().Msgf("shutdown complete in %s, %d steps exceeded budget:", time.Since(start).Round(time.Millisecond), len(slow))
+	for _, item := range slow {
+		s.log.Warn().Msgf("  - %s", item)
+	}
+}
+
+// stopTransports 按启动顺序倒序关闭服务, 每个服务单独计算超时, 返回超时或者关闭失败的服务
+func (s *Server) stopTransports(ctx context.Context) (slow []string) {
+	for i := len(s.transports) - 1; i >= 0; i-- {
+		t := s.transports[i]
+		start := time.Now()
+		if err := s.stopTransport(ctx, t); err != nil {
+			s.log.Error().Msgf("%s graceful shutdown err: %s, force exit", t.Name(), err)
+			slow = append(slow, fmt.Sprintf("transport %s stop failed after %s, %s", t.Name(), time.Since(start).Round(time.Millisecond), err))
+		} else {
+			s.log.Info().Msgf("%s service stop complete", t.Name())
+		}
+	}
+	return
+}
+
+func (s *Server) stopTransport(ctx context.Context, t Transport) error {
+	ctx, cancel := context.WithTimeout(ctx, stopTimeout(t))
+	defer cancel()
+
+	// 排空期间定时输出正在处理的请求数
+	if v, ok := t.(TransportInFlight); ok {
+		s.log.Info().Msgf("draining %s, %d requests in flight", t.Name(), v.InFlight())
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					s.log.Info().Msgf("waiting %s, %d requests in flight", t.Name(), v.InFlight())
+				}
+			}
+		}()
+	}
+	return t.Stop(ctx)
+}
+
+// watchForceExit 优雅关闭期间再次收到 SIGINT/SIGTERM 时强制退出, 返回停止监听的函数
+func (s *Server) watchForceExit() func() {
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sg := <-s.ch:
+				if sg == syscall.SIGHUP {
+					continue
+				}
+				s.log.Warn().Msgf("receive signal '%v' again, force exit", sg)
+				forceExit(1)
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+func (s *Server) transportNames() string {
+	names := make([]string, 0, len(s.transports))
+	for i := len(s.transports) - 1; i >= 0; i-- {
+		names = append(names, s.transports[i].Name())
+	}
+	return strings.Join(names, ", ")
+}
+
+func timeLeft(ctx context.Context) string {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return "unlimited"
+	}
+	return time.Until(deadline).Round(time.Millisecond).String()
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/infraboard/mcube/v2/ioc"
+)
+
+const (
+	// 单个服务的默认关闭超时时间
+	DEFAULT_TRANSPORT_STOP_TIMEOUT = 30 * time.Second
+)
+
+// Transport 由 server 统一启动和关闭的服务, 比如 HTTP、GRPC、Websocket、消息消费者等
+// 注册到 ioc 中的对象实现该接口即可被 server 发现
+type Transport interface {
+	ioc.Object
+	// 是否启用, 未启用的服务不会启动
+	IsEnable() bool
+	// 启动服务, 阻塞直到服务关闭, 正常关闭时返回 nil
+	// 运行中返回的错误会导致 server 退出
+	Start(ctx context.Context) error
+	// 关闭服务, ctx 超时后应该强制关闭
+	Stop(ctx context.Context) error
+}
+
+// TransportListener 需要在启动前同步绑定监听的服务
+// server 会在所有服务的监听都绑定成功后才标记为就绪, 任意一个绑定失败则 Run 返回错误
+type TransportListener interface {
+	Listen() error
+}
+
+// TransportStopTimeout 自定义服务的关闭超时时间, 默认为 DEFAULT_TRANSPORT_STOP_TIMEOUT
+type TransportStopTimeout interface {
+	StopTimeout() time.Duration
+}
+
+// TransportInFlight 可以统计正在处理的请求数的服务, 优雅关闭排空请求时会输出该数量
+type TransportInFlight interface {
+	InFlight() int64
+}
+
+var transportType = reflect.TypeOf((*Transport)(nil)).Elem()
+
+// Transports 按命名空间顺序查找所有已启用的服务
+func Transports() (transports []Transport) {
+	ioc.DefaultStore.ForEatch(func(ns *ioc.NamespaceStore) {
+		for _, obj := range ns.ImplementInterface(transportType) {
+			t := obj.(Transport)
+			if t.IsEnable() {
+				transports = append(transports, t)
+			}
+		}
+	})
+	return
+}
+
+func stopTimeout(t Transport) time.Duration {
+	if v, ok := t.(TransportStopTimeout); ok && v.StopTimeout() > 0 {
+		return v.StopTimeout()
+	}
+	return DEFAULT_TRANSPORT_STOP_TIMEOUT
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/infraboard/mcube/v2/ioc"
+	"github.com/infraboard/mcube/v2/ioc/config/log"
+)
+
+type fakeTransport struct {
+	ioc.ObjectImpl
+	name      string
+	listenErr error
+	block     bool
+	timeout   time.Duration
+
+	mu     *sync.Mutex
+	events *[]string
+	done   chan struct{}
+}
+
+func newFakeTransport(name string, mu *sync.Mutex, events *[]string) *fakeTransport {
+	return &fakeTransport{name: name, mu: mu, events: events, block: true, done: make(chan struct{})}
+}
+
+func (t *fakeTransport) Name() string   { return t.name }
+func (t *fakeTransport) IsEnable() bool { return true }
+
+func (t *fakeTransport) record(e string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*t.events = append(*t.events, e)
+}
+
+func (t *fakeTransport) Listen() error {
+	return t.listenErr
+}
+
+func (t *fakeTransport) Start(ctx context.Context) error {
+	if !t.block {
+		return errors.New("crashed")
+	}
+	<-t.done
+	return nil
+}
+
+func (t *fakeTransport) Stop(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if t.timeout > 0 {
+		deadline, _ := ctx.Deadline()
+		if time.Until(deadline) > t.timeout {
+			return errors.New("unexpected timeout")
+		}
+	}
+	t.record("stop " + t.name)
+	close(t.done)
+	return nil
+}
+
+func (t *fakeTransport) StopTimeout() time.Duration {
+	return t.timeout
+}
+
+func newTestServer(ts ...Transport) *Server {
+	s := NewServer()
+	s.log = log.Sub("server")
+	s.ctx, s.cancle = context.WithCancel(context.Background())
+	s.transports = ts
+	return s
+}
+
+func TestTransportStartStop(t *testing.T) {
+	mu, events := &sync.Mutex{}, []string{}
+	a := newFakeTransport("a", mu, &events)
+	b := newFakeTransport("b", mu, &events)
+	b.timeout = time.Second
+	s := newTestServer(a, b)
+
+	if err := s.listen(); err != nil {
+		t.Fatal(err)
+	}
+	s.serve(context.Background())
+	s.stopTransports(context.Background())
+
+	if len(events) != 2 || events[0] != "stop b" || events[1] != "stop a" {
+		t.Fatalf("transports should stop in reverse order, got %v", events)
+	}
+}
+
+func TestTransportErrors(t *testing.T) {
+	mu, events := &sync.Mutex{}, []string{}
+	a := newFakeTransport("a", mu, &events)
+	a.listenErr = errors.New("address already in use")
+	s := newTestServer(a)
+	if err := s.listen(); err == nil || err.Error() != "address already in use" {
+		t.Fatalf("expect listen error, got %v", err)
+	}
+
+	b := newFakeTransport("b", mu, &events)
+	b.block = false
+	s = newTestServer(b)
+	s.serve(context.Background())
+	select {
+	case err := <-s.errs:
+		if err.Error() != "b: crashed" {
+			t.Fatalf("unexpected error %s", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("serve error should be reported")
+	}
+}
+
+func TestTransportStopTimeout(t *testing.T) {
+	mu, events := &sync.Mutex{}, []string{}
+	a := newFakeTransport("a", mu, &events)
+	b := newFakeTransport("b", mu, &events)
+	b.timeout = time.Second
+	s := newTestServer(a, b)
+
+	// 总超时已经用完
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	slow := s.stopTransports(ctx)
+	if len(slow) != 2 || !strings.HasPrefix(slow[0], "transport b stop failed") {
+		t.Fatalf("unexpected slow transports %v", slow)
+	}
+}
+
+func TestForceExitOnSecondSignal(t *testing.T) {
+	code := make(chan int, 1)
+	forceExit = func(c int) { code <- c }
+	t.Cleanup(func() { forceExit = os.Exit })
+
+	s := newTestServer()
+	s.ch = make(chan os.Signal, 1)
+	stop := s.watchForceExit()
+	defer stop()
+
+	// SIGHUP 不会强制退出
+	s.ch <- syscall.SIGHUP
+	s.ch <- syscall.SIGTERM
+	select {
+	case c := <-code:
+		if c != 1 {
+			t.Fatalf("unexpected exit code %d", c)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("second signal should force exit")
+	}
+}
@@ -0,0 +1,178 @@
+package ioc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// ConfigSource 配置源, 用于从远程(Vault、KV存储、HTTP等)加载配置
+// 配置源返回的内容与配置文件格式相同, 按对象名称划分配置段
+type ConfigSource interface {
+	// 配置源名称, 用于日志和错误信息
+	Name() string
+	// 读取配置内容, format 为配置格式: .toml, .yaml, .yml, .json
+	Read(ctx context.Context) (content []byte, format string, err error)
+}
+
+// WatchableConfigSource 支持监听变化的配置源
+// 配置发生变化时调用 notify, 容器会重新加载配置并通知实现了 ConfigChangeHook 的对象
+type WatchableConfigSource interface {
+	ConfigSource
+	// 监听配置变化, 直到 ctx 结束, 不需要监听时直接返回
+	Watch(ctx context.Context, notify func()) error
+}
+
+// PollConfigSource 定期读取配置源, 内容发生变化时调用 notify, 直到 ctx 结束
+// 用于不支持推送的配置源实现 Watch
+func PollConfigSource(ctx context.Context, src ConfigSource, interval time.Duration, notify func()) error {
+	if interval <= 0 {
+		interval = DEFAULT_WATCH_INTERVAL
+	}
+
+	last, _, err := src.Read(ctx)
+	if err != nil {
+		debug("read config source %s error, %s", src.Name(), err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			current, _, err := src.Read(ctx)
+			if err != nil {
+				debug("read config source %s error, %s", src.Name(), err)
+				continue
+			}
+			if bytes.Equal(last, current) {
+				continue
+			}
+			last = current
+			debug("config source %s changed", src.Name())
+			notify()
+		}
+	}
+}
+
+// readConfigSource 读取并校验配置源的内容
+func readConfigSource(src ConfigSource) ([]byte, string, error) {
+	content, format, err := src.Read(context.Background())
+	if err != nil {
+		return nil, "", fmt.Errorf("read config source %s error, %w", src.Name(), err)
+	}
+	if err := ValidateFileType(format); err != nil {
+		return nil, "", fmt.Errorf("config source %s: %w", src.Name(), err)
+	}
+	return content, format, nil
+}
+
+// NewHTTPSource 从 HTTP 接口加载配置, 接口返回 TOML、YAML 或者 JSON 格式的配置
+// 配置格式依次根据 Format、响应的 Content-Type、URL 的扩展名确定
+// 使用示例:
+//
+//	src := ioc.NewHTTPSource("http://config-center/apps/demo/application.toml")
+//	src.Header.Set("Authorization", "Bearer "+token)
+//	src.WatchInterval = 30 * time.Second
+//	ioc.LoadConfig().FromFile("etc/application.toml").FromSource(src).Load()
+func NewHTTPSource(url string) *HTTPSource {
+	return &HTTPSource{
+		URL:     url,
+		Header:  http.Header{},
+		Timeout: 10 * time.Second,
+	}
+}
+
+// HTTPSource 通过 HTTP GET 加载配置的配置源
+type HTTPSource struct {
+	// 配置地址
+	URL string
+	// 请求头, 比如认证信息
+	Header http.Header
+	// 配置格式, 为空时自动识别
+	Format string
+	// 请求超时时间
+	Timeout time.Duration
+	// 检查配置变化的间隔, 为0时不监听配置变化
+	WatchInterval time.Duration
+	// 自定义 HTTP 客户端
+	Client *http.Client
+}
+
+func (s *HTTPSource) Name() string {
+	return s.URL
+}
+
+func (s *HTTPSource) Read(ctx context.Context) ([]byte, string, error) {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	for k, v := range s.Header {
+		req.Header[k] = v
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, "", fmt.Errorf("status code %d, %s", resp.StatusCode, content)
+	}
+	return content, s.format(resp.Header.Get("Content-Type")), nil
+}
+
+// format 根据 Content-Type 或者 URL 扩展名识别配置格式
+func (s *HTTPSource) format(contentType string) string {
+	if s.Format != "" {
+		return "." + strings.TrimPrefix(s.Format, ".")
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.Contains(mediaType, "toml"):
+		return ".toml"
+	case strings.Contains(mediaType, "yaml"):
+		return ".yaml"
+	case strings.Contains(mediaType, "json"):
+		return ".json"
+	}
+
+	u := s.URL
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		u = u[:i]
+	}
+	return path.Ext(u)
+}
+
+// Watch 按照 WatchInterval 轮询配置变化
+func (s *HTTPSource) Watch(ctx context.Context, notify func()) error {
+	if s.WatchInterval <= 0 {
+		return nil
+	}
+	return PollConfigSource(ctx, s, s.WatchInterval, notify)
+}
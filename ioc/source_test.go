@@ -0,0 +1,138 @@
+package ioc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// configServer 返回固定配置内容的 HTTP 服务
+type configServer struct {
+	mu          sync.Mutex
+	contentType string
+	content     string
+}
+
+func (s *configServer) set(content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.content = content
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer test" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", s.contentType)
+	w.Write([]byte(s.content))
+}
+
+func TestHTTPSource(t *testing.T) {
+	cs := &configServer{contentType: "application/json", content: `{"log": {"level": "info"}}`}
+	server := httptest.NewServer(cs)
+	defer server.Close()
+
+	// 文件 -> 配置源 -> 环境变量, 后面的覆盖前面的
+	store, path := newReloadTestStore(t, `
+[log]
+level = "debug"
+labels = { env = "dev" }
+
+[cache]
+level = "debug"
+`)
+	logConf := &ReloadTestConfig{name: "log"}
+	cacheConf := &ReloadTestConfig{name: "cache"}
+	store.Namespace("reload_test").Registry(logConf).Registry(cacheConf)
+
+	src := NewHTTPSource(server.URL)
+	src.Header.Set("Authorization", "Bearer test")
+
+	req := NewLoadConfigRequest()
+	req.ConfigFile.Enabled = true
+	req.ConfigFile.Paths = []string{path}
+	req.ConfigSources = []ConfigSource{src}
+	if err := store.LoadConfig(req); err != nil {
+		t.Fatal(err)
+	}
+	if logConf.Level != "info" || logConf.Labels["env"] != "dev" {
+		t.Fatalf("source should override file config, got %+v", logConf)
+	}
+	if cacheConf.Level != "debug" {
+		t.Fatalf("unexpected cache config %+v", cacheConf)
+	}
+
+	// 配置源的变化可以热更新
+	cs.set(`{"log": {"level": "error"}}`)
+	changed, err := store.ReloadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 || logConf.Level != "error" {
+		t.Fatalf("unexpected reload result %v, level=%s", changed, logConf.Level)
+	}
+
+	// 认证失败
+	src.Header.Del("Authorization")
+	if err := store.LoadConfig(req); err == nil {
+		t.Fatal("expect error when source returns 401")
+	}
+}
+
+func TestHTTPSourceFormat(t *testing.T) {
+	cases := []struct {
+		url         string
+		format      string
+		contentType string
+		want        string
+	}{
+		{url: "http://config/app", contentType: "application/toml", want: ".toml"},
+		{url: "http://config/app", contentType: "application/x-yaml; charset=utf-8", want: ".yaml"},
+		{url: "http://config/app.yml?version=1", contentType: "text/plain", want: ".yml"},
+		{url: "http://config/app", format: "json", contentType: "text/plain", want: ".json"},
+	}
+	for _, c := range cases {
+		src := NewHTTPSource(c.url)
+		src.Format = c.format
+		if got := src.format(c.contentType); got != c.want {
+			t.Fatalf("%s: want %s, got %s", c.url, c.want, got)
+		}
+	}
+}
+
+func TestPollConfigSource(t *testing.T) {
+	cs := &configServer{contentType: "application/toml", content: `[log]
+level = "info"`}
+	server := httptest.NewServer(cs)
+	defer server.Close()
+
+	src := NewHTTPSource(server.URL)
+	src.Header.Set("Authorization", "Bearer test")
+
+	// 没有设置 WatchInterval 时不监听
+	if err := src.Watch(context.Background(), func() {}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notified := make(chan struct{}, 1)
+	src.WatchInterval = 10 * time.Millisecond
+	go src.Watch(ctx, func() { notified <- struct{}{} })
+
+	time.Sleep(50 * time.Millisecond)
+	cs.set(`[log]
+level = "warn"`)
+
+	select {
+	case <-notified:
+	case <-time.After(3 * time.Second):
+		t.Fatal("source change not detected")
+	}
+}
@@ -0,0 +1,123 @@
+package ioc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// StopTimeout 对象关闭钩子超出预算的记录
+type StopTimeout struct {
+	// 对象, 格式为 namespace:name@version
+	Object string
+	// 钩子: OnPreStop/Close/OnPostStop
+	Hook string
+	// 等待的时间
+	Elapsed time.Duration
+}
+
+func (t StopTimeout) String() string {
+	return fmt.Sprintf("%s %s exceeded budget, gave up after %s", t.Object, t.Hook, t.Elapsed.Round(time.Millisecond))
+}
+
+type stopHook struct {
+	name string
+	call func(ctx context.Context) error
+}
+
+// stopHooks 对象的关闭钩子, 按执行顺序排列
+func (obj *ObjectWrapper) stopHooks() []stopHook {
+	hooks := []stopHook{}
+	if hook, ok := obj.Value.(PreStopHook); ok {
+		hooks = append(hooks, stopHook{name: "OnPreStop", call: func(ctx context.Context) error {
+			debug("calling PreStop hook for %s", obj.Name)
+			if err := hook.OnPreStop(ctx); err != nil {
+				debug("PreStop hook failed for %s: %v", obj.Name, err)
+				return fmt.Errorf("PreStop hook failed for %s: %w", obj.Name, err)
+			}
+			return nil
+		}})
+	}
+
+	// 主要清理
+	hooks = append(hooks, stopHook{name: "Close", call: func(ctx context.Context) error {
+		obj.Value.Close(ctx)
+		debug("closed app %s", obj.Value.Name())
+		return nil
+	}})
+
+	if hook, ok := obj.Value.(PostStopHook); ok {
+		hooks = append(hooks, stopHook{name: "OnPostStop", call: func(ctx context.Context) error {
+			debug("calling PostStop hook for %s", obj.Name)
+			if err := hook.OnPostStop(ctx); err != nil {
+				debug("PostStop hook failed for %s: %v", obj.Name, err)
+				return fmt.Errorf("PostStop hook failed for %s: %w", obj.Name, err)
+			}
+			return nil
+		}})
+	}
+	return hooks
+}
+
+// closeWithBudget 关闭对象, 每个钩子最多等待 budget, 超时后不再等待该钩子
+// 钩子的错误以及超时记录在 Stopped 状态中
+func (obj *ObjectWrapper) closeWithBudget(ctx context.Context, id string, budget time.Duration) (timeouts []StopTimeout) {
+	obj.setState(OBJECT_STOPPING, nil)
+	var errs []error
+	for _, hook := range obj.stopHooks() {
+		hctx, cancel := context.WithCancel(ctx)
+		if budget > 0 {
+			hctx, cancel = context.WithTimeout(ctx, budget)
+		}
+		start := time.Now()
+		done := make(chan error, 1)
+		go func() {
+			done <- hook.call(hctx)
+		}()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				errs = append(errs, err)
+			}
+		case <-hctx.Done():
+			timeout := StopTimeout{Object: id, Hook: hook.name, Elapsed: time.Since(start)}
+			timeouts = append(timeouts, timeout)
+			errs = append(errs, errors.New(timeout.String()))
+		}
+		cancel()
+	}
+	obj.setState(OBJECT_STOPPED, errors.Join(errs...))
+	return
+}
+
+// StopWithBudget 按初始化顺序倒序关闭对象, 与 Stop 不同的是每个关闭钩子(OnPreStop/Close/OnPostStop)
+// 最多等待 budget(小于等于0时不限制), ctx 超时后剩余的钩子不再等待, 返回超出预算的钩子
+func (s *defaultStore) StopWithBudget(ctx context.Context, budget time.Duration) (timeouts []StopTimeout) {
+	if s.stopWatch != nil {
+		s.stopWatch()
+		s.stopWatch = nil
+	}
+
+	if s.initOrder == nil {
+		for i := len(s.store) - 1; i >= 0; i-- {
+			ns := s.store[i]
+			items := ns.activeItems()
+			for j := len(items) - 1; j >= 0; j-- {
+				if !items[j].IsSingleton() {
+					continue
+				}
+				id := fmt.Sprintf("%s:%s@%s", ns.Namespace, items[j].Name, items[j].Version)
+				timeouts = append(timeouts, items[j].closeWithBudget(ctx, id, budget)...)
+			}
+		}
+		return
+	}
+
+	for i := len(s.initOrder) - 1; i >= 0; i-- {
+		node := s.initOrder[i]
+		timeouts = append(timeouts, node.w.closeWithBudget(ctx, node.String(), budget)...)
+	}
+	return
+}
@@ -0,0 +1,64 @@
+package ioc
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// StopTestObject 关闭时阻塞指定时间的对象
+type StopTestObject struct {
+	ObjectImpl
+	name    string
+	preStop time.Duration
+	close   time.Duration
+	closed  chan struct{}
+}
+
+func (o *StopTestObject) Name() string { return o.name }
+
+func (o *StopTestObject) OnPreStop(ctx context.Context) error {
+	time.Sleep(o.preStop)
+	return nil
+}
+
+func (o *StopTestObject) Close(ctx context.Context) {
+	time.Sleep(o.close)
+	close(o.closed)
+}
+
+func TestStopWithBudget(t *testing.T) {
+	fast := &StopTestObject{name: "fast", closed: make(chan struct{})}
+	slowClose := &StopTestObject{name: "slow_close", close: time.Second, closed: make(chan struct{})}
+	slowPreStop := &StopTestObject{name: "slow_pre_stop", preStop: time.Second, closed: make(chan struct{})}
+
+	store := &defaultStore{
+		store: []*NamespaceStore{newNamespaceStore("stop_test")},
+	}
+	store.Namespace("stop_test").Registry(fast).Registry(slowClose).Registry(slowPreStop)
+	if err := store.InitIocObject(); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	timeouts := store.StopWithBudget(context.Background(), 50*time.Millisecond)
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("stop should not wait for slow hooks, took %s", time.Since(start))
+	}
+
+	got := []string{}
+	for _, timeout := range timeouts {
+		got = append(got, timeout.Object+" "+timeout.Hook)
+	}
+	want := "stop_test:slow_pre_stop@v1 OnPreStop,stop_test:slow_close@v1 Close"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("unexpected timeouts %v", got)
+	}
+
+	select {
+	case <-fast.closed:
+	default:
+		t.Fatal("fast object should be closed")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/caarlos0/env/v6"
@@ -23,6 +24,70 @@ import (
 type defaultStore struct {
 	conf  *LoadConfigRequest
 	store []*NamespaceStore
+	// 对象的初始化顺序, 关闭时倒序
+	initOrder []*graphNode
+	// 严格注入模式: 依赖不存在或者接口存在多个实现且未指定 name 时返回错误
+	strictAutowire bool
+	// 是否已经加载过配置并初始化对象
+	loaded bool
+	// 初始化对象的并发数, 小于等于1时按顺序初始化
+	initParallelism int
+	// 对象状态变化的监听函数
+	listenerMu sync.RWMutex
+	listeners  []ObjectEventListener
+	// 停止监听配置文件
+	stopWatch context.CancelFunc
+	// 配置文件监听、配置源监听以及 SIGHUP 可能同时触发重新加载, 重新加载时互斥
+	reloadMu sync.Mutex
+	// 已加载的插件
+	plugins []PluginInfo
+}
+
+// startWatch 后台监听配置文件以及配置源的变化, 重复调用会停止之前的监听
+func (s *defaultStore) startWatch(req *LoadConfigRequest) {
+	if s.stopWatch != nil {
+		s.stopWatch()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopWatch = cancel
+
+	onReload := func(changed []string, err error) {
+		if err != nil {
+			debug("[IOC] reload config error, %s", err)
+		}
+		if len(changed) > 0 {
+			debug("[IOC] reload config, changed objects: %v", changed)
+		}
+	}
+
+	if req.ConfigFile.Enabled && req.ConfigFile.Watch {
+		go s.WatchConfig(ctx, req.ConfigFile.WatchInterval, onReload)
+	}
+	for _, src := range req.ConfigSources {
+		w, ok := src.(WatchableConfigSource)
+		if !ok {
+			continue
+		}
+		go func() {
+			err := w.Watch(ctx, func() {
+				onReload(s.ReloadConfig())
+			})
+			if err != nil {
+				debug("[IOC] watch config source %s error, %s", w.Name(), err)
+			}
+		}()
+	}
+}
+
+// SetStrictAutowire 设置严格注入模式
+func (s *defaultStore) SetStrictAutowire(strict bool) *defaultStore {
+	s.strictAutowire = strict
+	return s
+}
+
+// IsStrictAutowire 是否开启了严格注入模式, 通过 SetStrictAutowire 或者当前加载配置的请求开启
+func (s *defaultStore) IsStrictAutowire() bool {
+	return s.strictAutowire || (s.conf != nil && s.conf.StrictAutowire)
 }
 
 func (s *defaultStore) Len() int {
@@ -48,6 +113,16 @@ func (s *defaultStore) Sort() {
 	sort.Sort(s)
 }
 
+// namespacesByPriority 返回按优先级排序的命名空间快照（不修改原顺序）
+func (s *defaultStore) namespacesByPriority() []*NamespaceStore {
+	namespaces := make([]*NamespaceStore, len(s.store))
+	copy(namespaces, s.store)
+	sort.SliceStable(namespaces, func(i, j int) bool {
+		return namespaces[i].Priority > namespaces[j].Priority
+	})
+	return namespaces
+}
+
 // Namespace 获取或创建一个对象存储空间
 func (s *defaultStore) Namespace(namespace string) *NamespaceStore {
 	for i := range s.store {
@@ -58,6 +133,7 @@ func (s *defaultStore) Namespace(namespace string) *NamespaceStore {
 	}
 
 	ns := newNamespaceStore(namespace)
+	ns.container = s
 	s.store = append(s.store, ns)
 	return ns
 }
@@ -66,17 +142,27 @@ func (s *defaultStore) Namespace(namespace string) *NamespaceStore {
 func (s *defaultStore) LoadConfig(req *LoadConfigRequest) error {
 	errs := []string{}
 
+	// 重新加载时清空非单例对象已记录的配置
+	for i := range s.store {
+		s.store[i].ForEach(func(w *ObjectWrapper) {
+			w.resetConfigurers()
+		})
+	}
+
 	// 先加载配置文件（多个文件按顺序加载，后面的覆盖前面的）
 	if req.ConfigFile.Enabled {
-		for _, path := range req.ConfigFile.Paths {
+		loaded := []string{}
+		for _, l := range req.ConfigFile.layers() {
+			path := l.path
 			// 检查文件是否存在
 			if !IsFileExists(path) {
-				if !req.ConfigFile.SkipIFNotExist {
+				if !req.ConfigFile.skip(l) {
 					return fmt.Errorf("file %s not exist", path)
 				}
 				debug("skip not exist config file: %s", path)
 				continue
 			}
+			loaded = append(loaded, path)
 
 			fileType := filepath.Ext(path)
 			if err := ValidateFileType(fileType); err != nil {
@@ -93,31 +179,89 @@ func (s *defaultStore) LoadConfig(req *LoadConfigRequest) error {
 			// 为每个namespace加载配置（配置会合并）
 			for i := range s.store {
 				item := s.store[i]
-				err := item.LoadFromFileContent(content, fileType)
+				err := item.loadFromFileContent(content, fileType)
 				if err != nil {
 					errs = append(errs, fmt.Sprintf("file %s: %s", path, err.Error()))
 				}
 			}
 		}
+		if req.ConfigFile.Profile {
+			debug("[IOC] profiles: %v, config files: %s", Profiles(), strings.Join(loaded, " -> "))
+		}
 	}
 
-	// 最后加载环境变量（优先级最高，会覆盖文件配置）
-	if req.ConfigEnv.Enabled {
+	loadEnv := func() {
 		debug("loading config from environment variables with prefix: %s", req.ConfigEnv.Prefix)
 		for i := range s.store {
 			item := s.store[i]
-			err := item.LoadFromEnv(req.ConfigEnv.Prefix)
+			err := item.loadFromEnv(req.ConfigEnv.Prefix)
 			if err != nil {
 				errs = append(errs, err.Error())
 			}
 		}
 	}
 
+	// 再加载配置源（按添加顺序，覆盖文件配置）
+	if len(req.ConfigSources) > 0 {
+		// 配置源的客户端(比如 vault)可能通过环境变量配置, 先加载一次环境变量
+		if req.ConfigEnv.Enabled {
+			loadEnv()
+		}
+		// 配置源客户端的密文配置(比如 vault token)需要在读取配置源之前解密
+		if err := s.decrypt(); err != nil {
+			return err
+		}
+		for _, src := range req.ConfigSources {
+			content, format, err := readConfigSource(src)
+			if err != nil {
+				return err
+			}
+
+			debug("loading config source: %s", src.Name())
+			for i := range s.store {
+				item := s.store[i]
+				err := item.loadFromFileContent(content, format)
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("source %s: %s", src.Name(), err.Error()))
+				}
+			}
+		}
+	}
+
+	// 最后加载环境变量（优先级最高，会覆盖文件和配置源的配置）
+	if req.ConfigEnv.Enabled {
+		loadEnv()
+	}
+
 	if len(errs) > 0 {
 		return fmt.Errorf("%s", strings.Join(errs, ","))
 	}
 
+	// 所有配置加载完成后解密密文配置, 密钥可能通过环境变量配置
+	if err := s.decrypt(); err != nil {
+		return err
+	}
+
 	s.conf = req
+	// 配置加载完成后才能计算依赖配置的注册条件
+	if err := s.ResolveConditions(); err != nil {
+		return err
+	}
+	for i := range s.store {
+		for _, w := range s.store[i].activeItems() {
+			w.setState(OBJECT_CONFIGURED, nil)
+		}
+	}
+	return nil
+}
+
+// decrypt 解密所有命名空间中对象的密文配置
+func (s *defaultStore) decrypt() error {
+	for i := range s.store {
+		if err := s.store[i].decrypt(); err != nil {
+			return fmt.Errorf("[%s] %s", s.store[i].Namespace, err)
+		}
+	}
 	return nil
 }
 
@@ -134,36 +278,70 @@ func (s *defaultStore) CallPostConfigHooks() error {
 }
 
 // InitIocObject 初始化托管的所有对象
+// 跨命名空间根据依赖关系进行拓扑排序, 命名空间优先级与对象优先级只用于没有依赖关系的对象之间排序
 func (s *defaultStore) InitIocObject() error {
 	s.Sort()
+	if err := s.ResolveConditions(); err != nil {
+		return err
+	}
 
-	for i := range s.store {
-		item := s.store[i]
-		err := item.Init()
-		if err != nil {
-			return fmt.Errorf("[%s] %s", item.Namespace, err)
-		}
+	order, err := newDependencyGraph(s, s.store...).Sort()
+	if err != nil {
+		return err
 	}
-	return nil
+
+	s.initOrder = order[:0:0]
+	if s.initParallelism > 1 {
+		return s.initConcurrent(order)
+	}
+	return s.initSequential(order)
+}
+
+// InitOrder 返回对象的初始化顺序, 格式为 namespace:name@version
+func (s *defaultStore) InitOrder() []string {
+	order := make([]string, 0, len(s.initOrder))
+	for _, node := range s.initOrder {
+		order = append(order, node.String())
+	}
+	return order
 }
 
-// Stop 倒序遍历关闭对象
+// Stop 按初始化顺序倒序关闭对象
 func (s *defaultStore) Stop(ctx context.Context) {
-	for i := len(s.store) - 1; i >= 0; i-- {
-		item := s.store[i]
-		item.Close(ctx)
+	if s.stopWatch != nil {
+		s.stopWatch()
+		s.stopWatch = nil
+	}
+
+	if s.initOrder == nil {
+		for i := len(s.store) - 1; i >= 0; i-- {
+			item := s.store[i]
+			item.Close(ctx)
+		}
+		return
+	}
+
+	for i := len(s.initOrder) - 1; i >= 0; i-- {
+		s.initOrder[i].w.close(ctx)
 	}
 }
 
 // Autowire 自动装配依赖
+// 所有命名空间的注入错误会合并为一个错误返回
 func (s *defaultStore) Autowire() error {
+	if err := s.ResolveConditions(); err != nil {
+		return err
+	}
+	var errs []string
 	for i := range s.store {
 		item := s.store[i]
-		err := item.Autowire()
-		if err != nil {
-			return fmt.Errorf("[%s] %s", item.Namespace, err)
+		for _, err := range item.autowire() {
+			errs = append(errs, fmt.Sprintf("[%s] %s", item.Namespace, err))
 		}
 	}
+	if len(errs) > 0 {
+		return fmt.Errorf("autowire errors:\n  %s", strings.Join(errs, "\n  "))
+	}
 	return nil
 }
 
@@ -172,7 +350,30 @@ type ObjectWrapper struct {
 	Name     string
 	Version  string
 	Priority int
-	Value    Object
+	// 单例对象为对象本身, 非单例对象为注册时创建的模板对象
+	Value Object
+	// 对象的生命周期范围, 默认为单例
+	Scope Scope
+
+	// 注册时配置字段的快照, 即对象的默认配置, 不是结构体指针的对象为空
+	defaults Object
+
+	// 非单例对象的工厂
+	factory ObjectFactory
+	// 非单例对象的配置加载过程, 创建新实例时重放
+	configurers []func(Object) error
+	mu          sync.Mutex
+	// 对象注册的命名空间
+	ns *NamespaceStore
+	// 初始化耗时
+	initDuration time.Duration
+	// PostInit 钩子的错误, 不影响初始化结果
+	postInitErr error
+	// 生命周期状态
+	lifecycle objectState
+	// 注册条件, 条件不满足时对象被禁用
+	conditions []Condition
+	disabled   atomic.Bool
 }
 
 // NewObjectWrapper 创建对象包装器（手动指定优先级）
@@ -183,6 +384,8 @@ func NewObjectWrapper(obj Object, priority int) *ObjectWrapper {
 		Version:  version,
 		Priority: priority,
 		Value:    obj,
+		Scope:    SCOPE_SINGLETON,
+		defaults: newConfigObject(obj),
 	}
 }
 
@@ -195,7 +398,17 @@ func NewObjectWrapperAuto(obj Object) *ObjectWrapper {
 		Version:  version,
 		Priority: priority,
 		Value:    obj,
+		Scope:    SCOPE_SINGLETON,
+		defaults: newConfigObject(obj),
+	}
+}
+
+// newDefaultObject 根据注册时的默认配置创建新对象, 不是结构体指针的对象返回 nil
+func (w *ObjectWrapper) newDefaultObject() Object {
+	if w.defaults == nil {
+		return nil
 	}
+	return newConfigObject(w.defaults)
 }
 
 // NamespaceStore 使用 Copy-on-Write 模式的命名空间存储
@@ -216,6 +429,11 @@ type NamespaceStore struct {
 	Namespace string
 	// 命名空间优先级
 	Priority int
+
+	// 所属的容器, 自动注入以及依赖查找在该容器中进行, 为空时为 DefaultStore
+	container *defaultStore
+	// 并发初始化时该命名空间的对象仍然按顺序初始化
+	sequentialInit bool
 }
 
 // cowItems 不可变的 items 包装（Copy-on-Write）
@@ -234,6 +452,14 @@ func newNamespaceStore(namespace string) *NamespaceStore {
 	return store
 }
 
+// owner 返回命名空间所属的容器
+func (s *NamespaceStore) owner() *defaultStore {
+	if s.container != nil {
+		return s.container
+	}
+	return DefaultStore
+}
+
 // getItems 获取当前的 items（无锁读取）
 func (s *NamespaceStore) getItems() []*ObjectWrapper {
 	return s.items.Load().(*cowItems).items
@@ -251,6 +477,10 @@ func (s *NamespaceStore) Items() []*ObjectWrapper {
 
 // Registry 注册对象（Copy-on-Write）
 func (s *NamespaceStore) Registry(v Object) StoreUser {
+	return s.registry(v)
+}
+
+func (s *NamespaceStore) registry(v Object, opts ...func(*ObjectWrapper)) StoreUser {
 	// 1. 在锁外调用 Priority()（用户可以安全地调用 Get 等方法）
 	name, version := GetIocObjectUid(v)
 	priority := v.Priority()
@@ -259,6 +489,22 @@ func (s *NamespaceStore) Registry(v Object) StoreUser {
 		s.Namespace, name, version, priority)
 
 	obj := NewObjectWrapper(v, priority)
+	obj.ns = s
+	for _, opt := range opts {
+		opt(obj)
+	}
+	if b, ok := v.(namespaceBinder); ok {
+		b.bindNamespace(s)
+	}
+
+	s.insert(obj)
+	obj.setState(OBJECT_REGISTERED, nil)
+	return s
+}
+
+// insert 添加对象（Copy-on-Write）, 非条件对象同一版本重复注册时 panic
+func (s *NamespaceStore) insert(obj *ObjectWrapper) {
+	name := obj.Name
 
 	// 2. 获取写锁（只有写操作之间需要互斥）
 	s.writeMu.Lock()
@@ -270,47 +516,23 @@ func (s *NamespaceStore) Registry(v Object) StoreUser {
 	// 3. 读取当前的 items（无锁，原子操作）
 	current := s.getItems()
 
-	// 4. 查找是否存在同名对象
-	oldIndex := -1
-	for i, item := range current {
-		if item.Name == name {
-			oldIndex = i
-			break
+	// 4. 同名对象允许多个版本并存, 但同一版本只能注册一次
+	// 条件对象可以重复注册, 启用的对象是否重复在计算注册条件时检查
+	for _, item := range current {
+		if item.Name == name && CompareVersion(item.Version, obj.Version) == 0 &&
+			len(item.conditions) == 0 && len(obj.conditions) == 0 {
+			panic(fmt.Sprintf("ioc obj %s (version %s) has already registered", obj.Name, obj.Version))
 		}
 	}
 
 	// 5. 创建新的 items 副本（Copy-on-Write）
-	var newItems []*ObjectWrapper
-
-	if oldIndex == -1 {
-		// 没有同名对象，追加
-		newItems = make([]*ObjectWrapper, len(current)+1)
-		copy(newItems, current)
-		newItems[len(current)] = obj
-		debug("[IOC:%s] Registry: successfully registered new object %s", s.Namespace, name)
-	} else {
-		// 存在同名对象，检查版本并替换
-		old := current[oldIndex]
-		cmp := CompareVersion(obj.Version, old.Version)
-
-		if cmp > 0 {
-			// 新版本更高，允许覆盖
-			newItems = make([]*ObjectWrapper, len(current))
-			copy(newItems, current)
-			newItems[oldIndex] = obj
-			debug("[IOC:%s] Registry: upgraded %s from version %s to %s",
-				s.Namespace, name, old.Version, obj.Version)
-		} else if cmp == 0 {
-			panic(fmt.Sprintf("ioc obj %s (version %s) has already registered", obj.Name, obj.Version))
-		} else {
-			panic(fmt.Sprintf("ioc obj %s: cannot register lower version %s (current: %s)", obj.Name, obj.Version, old.Version))
-		}
-	}
+	newItems := make([]*ObjectWrapper, len(current)+1)
+	copy(newItems, current)
+	newItems[len(current)] = obj
+	debug("[IOC:%s] Registry: successfully registered object %s (version=%s)", s.Namespace, name, obj.Version)
 
 	// 6. 原子地更新 items（其他 goroutine 会立即看到新版本）
 	s.setItems(newItems)
-
-	return s
 }
 
 // RegistryAll 批量注册
@@ -321,23 +543,89 @@ func (s *NamespaceStore) RegistryAll(objs ...Object) StoreUser {
 	return s
 }
 
+// Replace 替换同名对象的所有版本, 同名对象不存在时直接注册（Copy-on-Write）
+// 一般用于测试中使用假对象替换真实对象
+func (s *NamespaceStore) Replace(v Object) StoreUser {
+	name, _ := GetIocObjectUid(v)
+
+	s.writeMu.Lock()
+	current := s.getItems()
+	newItems := make([]*ObjectWrapper, 0, len(current))
+	for _, item := range current {
+		if item.Name != name {
+			newItems = append(newItems, item)
+		}
+	}
+	s.setItems(newItems)
+	s.writeMu.Unlock()
+
+	debug("[IOC:%s] Replace: removed %d versions of %s", s.Namespace, len(current)-len(newItems), name)
+	return s.Registry(v)
+}
+
 // Get 获取对象（完全无锁）
+// 同名对象存在多个版本时, 根据 WithVersion 选择器返回满足条件的最高版本
+// 对象不存在或者获取失败时返回 nil, 需要区分失败原因(比如版本选择器格式错误)时使用 Lookup
 func (s *NamespaceStore) Get(name string, opts ...GetOption) Object {
-	debug("[IOC:%s] Get: reading %s (lock-free)", s.Namespace, name)
+	obj, err := s.Lookup(name, opts...)
+	if err != nil {
+		debug("[IOC:%s] Get: %s", s.Namespace, err)
+		return nil
+	}
+	return obj
+}
 
-	// 无锁读取当前 items
-	items := s.getItems()
+// Lookup 获取对象, 版本选择器格式错误或者非单例对象创建失败时返回错误
+// 对象不存在时返回 nil, nil
+func (s *NamespaceStore) Lookup(name string, opts ...GetOption) (Object, error) {
+	opt := defaultOption().Apply(opts...)
+	debug("[IOC:%s] Get: reading %s (version=%s, lock-free)", s.Namespace, name, opt.version)
 
-	// 查找对象
+	item, err := s.lookupWrapper(name, opt.version)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		debug("[IOC:%s] Get: object %s not found", s.Namespace, name)
+		return nil, nil
+	}
+
+	debug("[IOC:%s] Get: found object %s (version=%s, scope=%s)", s.Namespace, name, item.Version, item.Scope)
+	obj, err := item.resolve(opt.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("resolve object %s error, %w", name, err)
+	}
+	return obj, nil
+}
+
+// lookupWrapper 无锁读取当前 items, 并根据版本选择器选择对象包装
+func (s *NamespaceStore) lookupWrapper(name, selector string) (*ObjectWrapper, error) {
+	item, err := ResolveVersion(s.versions(name), selector)
+	if err != nil {
+		return nil, fmt.Errorf("get object %s error, %w", name, err)
+	}
+	return item, nil
+}
+
+// Versions 返回同名对象的所有版本（无锁）
+func (s *NamespaceStore) Versions(name string) []string {
+	items := s.versions(name)
+	versions := make([]string, 0, len(items))
 	for _, item := range items {
+		versions = append(versions, item.Version)
+	}
+	return versions
+}
+
+// versions 返回同名对象的所有版本包装
+func (s *NamespaceStore) versions(name string) []*ObjectWrapper {
+	var candidates []*ObjectWrapper
+	for _, item := range s.activeItems() {
 		if item.Name == name {
-			debug("[IOC:%s] Get: found object %s (version=%s)", s.Namespace, name, item.Version)
-			return item.Value
+			candidates = append(candidates, item)
 		}
 	}
-
-	debug("[IOC:%s] Get: object %s not found", s.Namespace, name)
-	return nil
+	return candidates
 }
 
 // Load 根据对象类型加载对象（完全无锁）
@@ -348,17 +636,32 @@ func (s *NamespaceStore) Load(target any, opts ...GetOption) error {
 	var obj Object
 	switch t.Kind() {
 	case reflect.Interface:
-		objs := s.ImplementInterface(t)
+		if err := ValidateVersionSelector(defaultOption().Apply(opts...).version); err != nil {
+			return err
+		}
+		objs := s.ImplementInterface(t, opts...)
 		if len(objs) > 0 {
 			obj = objs[0]
 		}
 	default:
-		obj = s.Get(t.String(), opts...)
+		var err error
+		obj, err = s.Lookup(t.String(), opts...)
+		if err != nil {
+			return err
+		}
 	}
 
 	// 注入值
 	if obj != nil {
 		objValue := reflect.ValueOf(obj)
+		if u, ok := obj.(Unwrapper); ok && !objValue.Type().AssignableTo(t) {
+			// 适配器对象, 加载被适配的值
+			value, err := u.Unwrap()
+			if err != nil {
+				return err
+			}
+			objValue = reflect.ValueOf(value)
+		}
 		if !(v.Kind() == reflect.Ptr && objValue.Kind() == reflect.Ptr) {
 			return fmt.Errorf("target and object must both be pointers or non-pointers")
 		}
@@ -379,27 +682,62 @@ func (s *NamespaceStore) ForEach(fn func(*ObjectWrapper)) {
 }
 
 // ImplementInterface 查找实现了指定接口的对象（完全无锁）
+// 同名对象存在多个版本时, 每个名称只返回满足版本选择器的最高版本
 func (s *NamespaceStore) ImplementInterface(objType reflect.Type, opts ...GetOption) []Object {
-	items := s.getItems()
 	opt := defaultOption().Apply(opts...)
 
 	var objs []Object
+	for _, item := range s.implementWrappers(objType, opt.version) {
+		obj, err := item.resolve(opt.ctx)
+		if err != nil {
+			debug("[IOC:%s] ImplementInterface: resolve object %s error, %s", s.Namespace, item.Name, err)
+			continue
+		}
+		objs = append(objs, obj)
+	}
+
+	return objs
+}
+
+// implementWrappers 查找实现了指定接口(或可赋值给指定类型)的对象包装, 每个名称只保留满足版本选择器的最高版本
+func (s *NamespaceStore) implementWrappers(objType reflect.Type, selector string) []*ObjectWrapper {
+	items := s.activeItems()
+
+	// 按名称分组, 保持注册顺序
+	names := []string{}
+	groups := map[string][]*ObjectWrapper{}
 	for _, item := range items {
-		if item != nil && reflect.TypeOf(item.Value).Implements(objType) {
-			if item.Version == opt.version {
-				objs = append(objs, item.Value)
+		if item != nil && reflect.TypeOf(item.Value).AssignableTo(objType) {
+			if _, ok := groups[item.Name]; !ok {
+				names = append(names, item.Name)
 			}
+			groups[item.Name] = append(groups[item.Name], item)
 		}
 	}
 
-	return objs
+	var wrappers []*ObjectWrapper
+	for _, name := range names {
+		item, err := ResolveVersion(groups[name], selector)
+		if err != nil {
+			debug("[IOC:%s] %s", s.Namespace, err)
+			return nil
+		}
+		if item != nil {
+			wrappers = append(wrappers, item)
+		}
+	}
+	return wrappers
 }
 
-// List 返回所有对象的 UID 列表（无锁）
+// List 返回所有对象的 UID 列表（无锁）, 注册条件不满足的对象标记为 (disabled)
 func (s *NamespaceStore) List() []string {
 	items := s.getItems()
 	uids := make([]string, 0, len(items))
 	for _, item := range items {
+		if item.Disabled() {
+			uids = append(uids, ObjectUid(item)+" (disabled)")
+			continue
+		}
 		uids = append(uids, ObjectUid(item))
 	}
 	return uids
@@ -428,6 +766,13 @@ func (s *NamespaceStore) Last() Object {
 	return items[len(items)-1].Value
 }
 
+// SetSequentialInit 并发初始化时该命名空间的对象仍然按顺序初始化
+// Api 命名空间默认按顺序初始化, 因为注册路由的 Web 框架(如 gin)不是并发安全的
+func (s *NamespaceStore) SetSequentialInit(v bool) *NamespaceStore {
+	s.sequentialInit = v
+	return s
+}
+
 // SetPriority 设置命名空间优先级
 func (s *NamespaceStore) SetPriority(v int) *NamespaceStore {
 	s.Priority = v
@@ -455,80 +800,141 @@ func (s *NamespaceStore) Sort() {
 }
 
 // Init 初始化所有对象（无死锁风险）
+// 根据命名空间内对象的依赖关系进行拓扑排序, 优先级只用于没有依赖关系的对象之间排序
 func (s *NamespaceStore) Init() error {
-	// 1. 先排序
-	s.Sort()
+	// 1. 先根据依赖关系排序
+	if err := s.SortByDependency(); err != nil {
+		return err
+	}
 
 	// 2. 读取已排序的 items（无锁）
-	items := s.getItems()
+	items := s.activeItems()
 
 	debug("init namespace [%s] app ...", s.Namespace)
 
 	// 3. 遍历并初始化（用户可以在回调中安全地调用任何方法）
 	for _, obj := range items {
-		// PreInit 钩子
-		if hook, ok := obj.Value.(PreInitHook); ok {
-			debug("calling PreInit hook for %s", obj.Name)
-			if err := hook.OnPreInit(); err != nil {
-				return fmt.Errorf("PreInit hook failed for %s: %w", obj.Name, err)
-			}
+		// 非单例对象在获取时创建并初始化
+		if !obj.IsSingleton() {
+			continue
+		}
+		if err := obj.init(); err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
+
+// SortByDependency 根据命名空间内对象的依赖关系排序（Copy-on-Write）
+// 存在循环依赖时返回 *CircularDependencyError
+func (s *NamespaceStore) SortByDependency() error {
+	// 在锁外构建依赖图（DeclareDependencies 中可以安全地调用任何方法）
+	sorted, err := newDependencyGraph(s.owner(), s).Sort()
+	if err != nil {
+		return err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
 
-		// 主要初始化
-		if err := obj.Value.Init(); err != nil {
-			return fmt.Errorf("init object %s error, %s", obj.Name, err)
+	current := s.getItems()
+	exists := make(map[*ObjectWrapper]bool, len(current))
+	for _, item := range current {
+		exists[item] = true
+	}
+
+	// 排序期间新注册的对象与非单例对象保持原顺序放在最后
+	items := make([]*ObjectWrapper, 0, len(current))
+	for _, node := range sorted {
+		if exists[node.w] {
+			items = append(items, node.w)
+			delete(exists, node.w)
+		}
+	}
+	for _, item := range current {
+		if exists[item] {
+			items = append(items, item)
 		}
-		debug("init app %s[priority: %d] ok.", obj.Value.Name(), obj.Value.Priority())
+	}
+	s.setItems(items)
+	return nil
+}
 
-		// PostInit 钩子
-		if hook, ok := obj.Value.(PostInitHook); ok {
-			debug("calling PostInit hook for %s", obj.Name)
-			if err := hook.OnPostInit(); err != nil {
-				debug("PostInit hook failed for %s: %v", obj.Name, err)
-			}
+// init 执行单例对象的初始化流程: PreInit -> Init -> PostInit
+// PostInit 钩子失败不影响初始化结果, 错误记录在 Initialized 状态中
+func (obj *ObjectWrapper) init() error {
+	start := time.Now()
+	err := obj.runInit()
+	obj.initDuration = time.Since(start)
+	if err != nil {
+		obj.setState(OBJECT_INIT_FAILED, err)
+		return err
+	}
+	obj.setState(OBJECT_INITIALIZED, obj.postInitErr)
+	return nil
+}
+
+func (obj *ObjectWrapper) runInit() error {
+	obj.postInitErr = nil
+	// PreInit 钩子
+	if hook, ok := obj.Value.(PreInitHook); ok {
+		debug("calling PreInit hook for %s", obj.Name)
+		if err := hook.OnPreInit(); err != nil {
+			return fmt.Errorf("PreInit hook failed for %s: %w", obj.Name, err)
 		}
 	}
 
+	// 主要初始化
+	if err := obj.Value.Init(); err != nil {
+		return fmt.Errorf("init object %s error, %s", obj.Name, err)
+	}
+	debug("init app %s[priority: %d] ok.", obj.Value.Name(), obj.Value.Priority())
+
+	// PostInit 钩子
+	if hook, ok := obj.Value.(PostInitHook); ok {
+		debug("calling PostInit hook for %s", obj.Name)
+		if err := hook.OnPostInit(); err != nil {
+			debug("PostInit hook failed for %s: %v", obj.Name, err)
+			obj.postInitErr = fmt.Errorf("PostInit hook failed for %s: %w", obj.Name, err)
+		}
+	}
 	return nil
 }
 
 // Close 关闭所有对象（无死锁风险）
 func (s *NamespaceStore) Close(ctx context.Context) {
 	// 读取当前 items（无锁）
-	items := s.getItems()
+	items := s.activeItems()
 
 	debug("closing namespace [%s] app ...", s.Namespace)
 
 	// 倒序关闭
 	for i := len(items) - 1; i >= 0; i-- {
 		obj := items[i]
-
-		// PreStop 钩子
-		if hook, ok := obj.Value.(PreStopHook); ok {
-			debug("calling PreStop hook for %s", obj.Name)
-			if err := hook.OnPreStop(ctx); err != nil {
-				debug("PreStop hook failed for %s: %v", obj.Name, err)
-			}
+		if !obj.IsSingleton() {
+			continue
 		}
+		obj.close(ctx)
+	}
+}
 
-		// 主要清理
-		obj.Value.Close(ctx)
-		debug("closed app %s", obj.Value.Name())
-
-		// PostStop 钩子
-		if hook, ok := obj.Value.(PostStopHook); ok {
-			debug("calling PostStop hook for %s", obj.Name)
-			if err := hook.OnPostStop(ctx); err != nil {
-				debug("PostStop hook failed for %s: %v", obj.Name, err)
-			}
+// close 执行单例对象的关闭流程: PreStop -> Close -> PostStop
+func (obj *ObjectWrapper) close(ctx context.Context) {
+	obj.setState(OBJECT_STOPPING, nil)
+	var errs []error
+	for _, hook := range obj.stopHooks() {
+		if err := hook.call(ctx); err != nil {
+			errs = append(errs, err)
 		}
 	}
+	obj.setState(OBJECT_STOPPED, errors.Join(errs...))
 }
 
 // CallPostConfigHooks 调用命名空间内所有对象的 PostConfig 钩子
 func (s *NamespaceStore) CallPostConfigHooks() error {
 	// 无锁读取快照
-	items := s.getItems()
+	items := s.activeItems()
 
 	// 在无锁状态下调用用户回调
 	for i := range items {
@@ -543,21 +949,30 @@ func (s *NamespaceStore) CallPostConfigHooks() error {
 	return nil
 }
 
-// LoadFromEnv 从环境变量中加载对象配置
+// LoadFromEnv 从环境变量中加载对象配置, 并解密其中的密文配置
 func (s *NamespaceStore) LoadFromEnv(prefix string) error {
+	if err := s.loadFromEnv(prefix); err != nil {
+		return err
+	}
+	return s.decrypt()
+}
+
+func (s *NamespaceStore) loadFromEnv(prefix string) error {
 	errs := []string{}
 	// ForEach 已是无锁快照，安全地遍历对象
 	s.ForEach(func(w *ObjectWrapper) {
-		prefixList := strings.ToUpper(w.Name) + "_"
-		if prefix != "" {
-			prefixList = fmt.Sprintf("%s_%s", strings.ToUpper(prefix), prefixList)
+		configure := func(target Object) error {
+			if err := decodeEnv(target, w.Name, prefix); err != nil {
+				return err
+			}
+			return decryptObject(target)
 		}
-		err := env.Parse(w.Value, env.Options{
-			Prefix: prefixList,
-		})
+		err := decodeEnv(w.Value, w.Name, prefix)
 		if err != nil {
 			errs = append(errs, err.Error())
+			return
 		}
+		w.addConfigurer(configure)
 	})
 	if len(errs) > 0 {
 		return fmt.Errorf("%s", strings.Join(errs, ","))
@@ -566,66 +981,211 @@ func (s *NamespaceStore) LoadFromEnv(prefix string) error {
 	return nil
 }
 
+// decodeEnv 从环境变量中加载对象配置, 环境变量前缀为 [PREFIX_]NAME_
+func decodeEnv(target Object, name, prefix string) error {
+	return env.Parse(target, env.Options{
+		Prefix: envPrefix(name, prefix),
+	})
+}
+
+// envPrefix 对象环境变量的前缀: [PREFIX_]NAME_
+func envPrefix(name, prefix string) string {
+	prefixList := strings.ToUpper(name) + "_"
+	if prefix != "" {
+		prefixList = fmt.Sprintf("%s_%s", strings.ToUpper(prefix), prefixList)
+	}
+	return prefixList
+}
+
 // Autowire 自动装配依赖
 func (s *NamespaceStore) Autowire() error {
+	errs := s.autowire()
+	if len(errs) > 0 {
+		return fmt.Errorf("autowire errors:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return nil
+}
+
+func (s *NamespaceStore) autowire() []string {
 	var errs []string
 
-	// ForEach 已是无锁快照，回调中可以安全地调用 Get() 等方法
-	s.ForEach(func(w *ObjectWrapper) {
-		pt := reflect.TypeOf(w.Value).Elem()
-		// go语言所有函数传的都是值，所以要想修改原来的值就需要传指
-		// 通过Elem()返回指针指向的对象
-		v := reflect.ValueOf(w.Value).Elem()
-
-		for i := 0; i < pt.NumField(); i++ {
-			fieldTag := pt.Field(i).Tag.Get("ioc")
-			if fieldTag == "" {
-				continue
-			}
+	// activeItems 是无锁快照，可以安全地调用 Get() 等方法
+	for _, w := range s.activeItems() {
+		// 非单例对象在创建实例时注入
+		if !w.IsSingleton() {
+			continue
+		}
+		errs = append(errs, autowireObject(nil, s.owner(), w.Value, w.Scope)...)
+	}
+	return errs
+}
 
-			tag, err := ParseInjectTagWithError(fieldTag)
-			if err != nil {
-				errs = append(errs, fmt.Sprintf("parse tag for %s.%s: %v",
-					pt.Name(), pt.Field(i).Name, err))
-				continue
-			}
+// autowireObject 在容器 store 中为单个对象注入依赖, ctx 用于获取请求范围的依赖对象, scope 为对象的生命周期范围
+// 标签指定 required=true 或者开启严格注入模式时, 依赖不存在以及接口存在多个实现未指定 name 会返回错误
+// 请求范围的依赖注入到单例对象时返回错误
+func autowireObject(ctx context.Context, store *defaultStore, o Object, scope Scope) []string {
+	var errs []string
+
+	objName, _ := GetIocObjectUid(o)
+	pt := reflect.TypeOf(o).Elem()
+	// go语言所有函数传的都是值，所以要想修改原来的值就需要传指
+	// 通过Elem()返回指针指向的对象
+	v := reflect.ValueOf(o).Elem()
+	strict := store.IsStrictAutowire()
+
+	for i := 0; i < pt.NumField(); i++ {
+		fieldTag := pt.Field(i).Tag.Get("ioc")
+		if fieldTag == "" {
+			continue
+		}
 
-			if tag.Autowire {
-				fieldType := v.Field(i).Type()
-				var obj Object
-				// 根据字段的类型获取值
-				switch fieldType.Kind() {
-				case reflect.Interface:
-					// 为接口类型注入值
-					if tag.Name != "" {
-						// 如果指定了 name，直接获取指定的对象（支持接口字段指定具体实现）
-						obj = DefaultStore.Namespace(tag.Namespace).Get(tag.Name, WithVersion(tag.Version))
-					} else {
-						// 否则自动查找实现该接口的对象（取第一个）
-						objs := DefaultStore.Namespace(tag.Namespace).ImplementInterface(fieldType)
-						if len(objs) > 0 {
-							obj = objs[0]
+		tag, err := ParseInjectTagWithError(fieldTag)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("parse tag for %s.%s: %v",
+				pt.Name(), pt.Field(i).Name, err))
+			continue
+		}
+
+		if tag.Autowire {
+			fieldType := v.Field(i).Type()
+			ns := store.Namespace(tag.Namespace)
+			var dep *ObjectWrapper
+			// 根据字段的类型获取值
+			switch fieldType.Kind() {
+			case reflect.Interface:
+				// 为接口类型注入值
+				if tag.Name != "" {
+					// 如果指定了 name，直接获取指定的对象（支持接口字段指定具体实现）
+					dep, err = ns.lookupWrapper(tag.Name, tag.VersionSelector())
+				} else {
+					// 否则自动查找实现该接口的对象（取第一个）
+					candidates := ns.implementWrappers(fieldType, tag.VersionSelector())
+					if strict && len(candidates) > 1 {
+						names := make([]string, 0, len(candidates))
+						for _, c := range candidates {
+							names = append(names, ObjectUid(c))
 						}
+						errs = append(errs, fmt.Sprintf("object %s field %s: %d objects in namespace %s implement %s %v, use name= to choose one",
+							objName, pt.Field(i).Name, len(candidates), tag.Namespace, fieldType, names))
+						continue
 					}
-				default:
-					// 为结构体变量注入值
-					if tag.Name == "" {
-						tag.Name = fieldType.String()
+					if len(candidates) > 0 {
+						dep = candidates[0]
 					}
-					obj = DefaultStore.Namespace(tag.Namespace).Get(tag.Name, WithVersion(tag.Version))
 				}
-				// 注入值
-				if obj != nil {
-					v.Field(i).Set(reflect.ValueOf(obj))
+			case reflect.Slice, reflect.Map:
+				// 集合注入: 注入所有满足条件的对象
+				n, err := injectCollection(ctx, ns, v.Field(i), tag, o, scope)
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("object %s field %s: %s", objName, pt.Field(i).Name, err))
+				} else if n == 0 && tag.Required {
+					errs = append(errs, fmt.Sprintf("object %s field %s: no object in namespace %s matches %s",
+						objName, pt.Field(i).Name, tag.Namespace, fieldType.Elem()))
+				}
+				continue
+			default:
+				// 为结构体变量注入值
+				if tag.Name == "" {
+					tag.Name = fieldType.String()
+				}
+				dep, err = ns.lookupWrapper(tag.Name, tag.VersionSelector())
+			}
+			var obj Object
+			if err == nil && dep != nil {
+				if err = checkInjectScope(scope, dep); err == nil {
+					obj, err = dep.resolve(ctx)
 				}
 			}
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("object %s field %s: %s", objName, pt.Field(i).Name, err))
+				continue
+			}
+			// 注入值
+			if obj != nil {
+				value, err := assignableValue(obj, fieldType)
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("object %s field %s: %s", objName, pt.Field(i).Name, err))
+					continue
+				}
+				v.Field(i).Set(value)
+			} else if tag.Required || strict {
+				target := tag.Name
+				if target == "" {
+					target = "implementation of " + fieldType.String()
+				}
+				errs = append(errs, fmt.Sprintf("object %s field %s: required %s (version %s) not found in namespace %s",
+					objName, pt.Field(i).Name, target, tag.VersionSelector(), tag.Namespace))
+			}
+		}
+	}
+
+	return errs
+}
+
+// collectionWrappers 查找集合注入的所有对象, 按优先级从高到低排序, 优先级相同时保持注册顺序
+func (s *NamespaceStore) collectionWrappers(elemType reflect.Type, tag *InjectTag) []*ObjectWrapper {
+	var items []*ObjectWrapper
+	for _, item := range s.implementWrappers(elemType, tag.VersionSelector()) {
+		if tag.MatchLabels(item.Value) {
+			items = append(items, item)
 		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Priority > items[j].Priority
 	})
+	return items
+}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("autowire errors:\n  %s", strings.Join(errs, "\n  "))
+// injectCollection 为 []T 或 map[string]T 类型的字段注入所有满足条件的对象, 返回注入对象的数量
+// 字段所属的对象 owner 不会注入到自身的集合中, scope 为 owner 的生命周期范围
+func injectCollection(ctx context.Context, ns *NamespaceStore, field reflect.Value, tag *InjectTag, owner Object, scope Scope) (int, error) {
+	fieldType := field.Type()
+	if fieldType.Kind() == reflect.Map && fieldType.Key().Kind() != reflect.String {
+		return 0, fmt.Errorf("collection map key must be string, got %s", fieldType.Key())
+	}
+
+	items := []*ObjectWrapper{}
+	for _, item := range ns.collectionWrappers(fieldType.Elem(), tag) {
+		if item.Value == owner {
+			continue
+		}
+		if err := checkInjectScope(scope, item); err != nil {
+			return 0, err
+		}
+		items = append(items, item)
+	}
+	switch fieldType.Kind() {
+	case reflect.Slice:
+		values := reflect.MakeSlice(fieldType, 0, len(items))
+		for _, item := range items {
+			obj, err := item.resolve(ctx)
+			if err != nil {
+				return 0, err
+			}
+			value, err := assignableValue(obj, fieldType.Elem())
+			if err != nil {
+				return 0, err
+			}
+			values = reflect.Append(values, value)
+		}
+		field.Set(values)
+		return values.Len(), nil
+	default:
+		values := reflect.MakeMapWithSize(fieldType, len(items))
+		for _, item := range items {
+			obj, err := item.resolve(ctx)
+			if err != nil {
+				return 0, err
+			}
+			value, err := assignableValue(obj, fieldType.Elem())
+			if err != nil {
+				return 0, err
+			}
+			values.SetMapIndex(reflect.ValueOf(item.Name).Convert(fieldType.Key()), value)
+		}
+		field.Set(values)
+		return values.Len(), nil
 	}
-	return nil
 }
 
 // ValidateFileType 验证文件类型
@@ -644,8 +1204,63 @@ func ValidateFileType(ext string) error {
 	return nil
 }
 
-// LoadFromFileContent 从文件内容加载配置
+// LoadFromFileContent 从文件内容加载配置, 并解密其中的密文配置
 func (s *NamespaceStore) LoadFromFileContent(fileContent []byte, fileType string) error {
+	if err := s.loadFromFileContent(fileContent, fileType); err != nil {
+		return err
+	}
+	return s.decrypt()
+}
+
+// decrypt 解密所有对象中的密文配置
+// 在所有配置加载完成后调用, 解密器(application)的密钥可能与密文在同一批配置中
+func (s *NamespaceStore) decrypt() error {
+	var errs []error
+	s.ForEach(func(w *ObjectWrapper) {
+		if err := decryptObject(w.Value); err != nil {
+			errs = append(errs, err)
+		}
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("decrypt config errors: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (s *NamespaceStore) loadFromFileContent(fileContent []byte, fileType string) error {
+	fileData, tagName, err := parseFileContent(fileContent, fileType)
+	if err != nil {
+		return err
+	}
+
+	// 使用mapstructure直接加载到目标对象
+	var errs []error
+	s.ForEach(func(w *ObjectWrapper) {
+		if configData, exists := fileData[w.Value.Name()]; exists {
+			configure := func(target Object) error {
+				if err := decodeSection(target, w.Value.Name(), configData, tagName); err != nil {
+					return err
+				}
+				return decryptObject(target)
+			}
+
+			if err := decodeSection(w.Value, w.Value.Name(), configData, tagName); err != nil {
+				errs = append(errs, err)
+				return
+			}
+			w.addConfigurer(configure)
+		}
+	})
+
+	if len(errs) > 0 {
+		return fmt.Errorf("load config errors: %v", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// parseFileContent 根据文件类型解析配置文件内容, 返回按对象名称划分的配置以及解码使用的标签名称
+func parseFileContent(fileContent []byte, fileType string) (map[string]any, string, error) {
 	// 准备一个临时结构体来解析文件内容
 	fileData := make(map[string]any)
 
@@ -654,49 +1269,41 @@ func (s *NamespaceStore) LoadFromFileContent(fileContent []byte, fileType string
 	switch fileType {
 	case ".toml":
 		if _, err := toml.Decode(string(fileContent), &fileData); err != nil {
-			return fmt.Errorf("toml decode error: %w", err)
+			return nil, "", fmt.Errorf("toml decode error: %w", err)
 		}
 	case ".yml", ".yaml":
 		tagName = "yaml"
 		if err := yaml.Unmarshal(fileContent, &fileData); err != nil {
-			return fmt.Errorf("yaml decode error: %w", err)
+			return nil, "", fmt.Errorf("yaml decode error: %w", err)
 		}
 	case ".json":
 		tagName = "json"
 		if err := json.Unmarshal(fileContent, &fileData); err != nil {
-			return fmt.Errorf("json decode error: %w", err)
+			return nil, "", fmt.Errorf("json decode error: %w", err)
 		}
 	default:
-		return fmt.Errorf("unsupported format: %s", fileType)
+		return nil, "", fmt.Errorf("unsupported format: %s", fileType)
 	}
+	return fileData, tagName, nil
+}
 
-	// 使用mapstructure直接加载到目标对象
-	var errs []error
-	s.ForEach(func(w *ObjectWrapper) {
-		if configData, exists := fileData[w.Value.Name()]; exists {
-			decoderConfig := &mapstructure.DecoderConfig{
-				Result:           w.Value,
-				TagName:          tagName,
-				Squash:           true,              // 嵌套结构体
-				WeaklyTypedInput: true,              // 允许弱类型转换
-				MatchName:        strings.EqualFold, // 大小写不敏感匹配
-			}
-
-			decoder, err := mapstructure.NewDecoder(decoderConfig)
-			if err != nil {
-				errs = append(errs, fmt.Errorf("create decoder for %s error: %w", w.Value.Name(), err))
-				return
-			}
-
-			if err := decoder.Decode(configData); err != nil {
-				errs = append(errs, fmt.Errorf("decode %s error: %w", w.Value.Name(), err))
-			}
-		}
-	})
+// decodeSection 将对象对应的配置段加载到目标对象
+func decodeSection(target Object, name string, configData any, tagName string) error {
+	decoderConfig := &mapstructure.DecoderConfig{
+		Result:           target,
+		TagName:          tagName,
+		Squash:           true,              // 嵌套结构体
+		WeaklyTypedInput: true,              // 允许弱类型转换
+		MatchName:        strings.EqualFold, // 大小写不敏感匹配
+	}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("load config errors: %v", errors.Join(errs...))
+	decoder, err := mapstructure.NewDecoder(decoderConfig)
+	if err != nil {
+		return fmt.Errorf("create decoder for %s error: %w", name, err)
 	}
 
+	if err := decoder.Decode(configData); err != nil {
+		return fmt.Errorf("decode %s error: %w", name, err)
+	}
 	return nil
 }
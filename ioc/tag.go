@@ -25,7 +25,9 @@ func ParseInjectTag(v string) *InjectTag {
 //   - autowire: 是否自动注入 (true/false)
 //   - namespace: 对象所在命名空间
 //   - name: 注入对象的名称
-//   - version: 注入对象的版本
+//   - version: 注入对象的版本, 支持精确版本(v2)、语义化版本范围(>=v1.2 <v2) 以及 latest
+//   - required: 依赖是否必须存在 (true/false), 找不到依赖对象时 Autowire 返回错误
+//   - labels: 集合注入时按对象标签(Meta().Extra)过滤, 格式为 key:value, 多个标签用逗号分隔
 //
 // 示例:
 //
@@ -84,12 +86,33 @@ func ParseInjectTagWithError(v string) (*InjectTag, error) {
 			ins.Name = value
 
 		case "version":
+			ins.versionSpecified = true
 			if value == "" {
 				ins.Version = DEFAULT_VERSION
 			} else {
+				if err := ValidateVersionSelector(value); err != nil {
+					return nil, err
+				}
 				ins.Version = value
 			}
 
+		case "required":
+			switch value {
+			case "", "true":
+				ins.Required = true
+			case "false":
+				ins.Required = false
+			default:
+				return nil, fmt.Errorf("invalid required value %q, expected true or false", value)
+			}
+
+		case "labels":
+			labels, err := parseLabels(value)
+			if err != nil {
+				return nil, err
+			}
+			ins.Labels = labels
+
 		default:
 			return nil, fmt.Errorf("unknown tag key: %q", key)
 		}
@@ -112,6 +135,56 @@ type InjectTag struct {
 	Namespace string
 	// 注入对象的名称
 	Name string
-	// 注入对象的版本, 默认v1
+	// 注入对象的版本选择器, 默认v1, 标签中未显式指定时按最新版本注入(见 VersionSelector)
+	// 支持精确版本、语义化版本范围(>=v1.2 <v2) 以及 latest
 	Version string
+
+	// 依赖是否必须存在, 严格模式下所有依赖都必须存在
+	Required bool
+	// 集合注入时的标签过滤条件, 对象的 Meta().Extra 需要包含所有标签
+	Labels map[string]string
+
+	// 标签中是否显式指定了版本
+	versionSpecified bool
+}
+
+// parseLabels 解析标签过滤条件, 格式: key:value,key:value
+func parseLabels(v string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, ":", 2)
+		key := strings.TrimSpace(kv[0])
+		if len(kv) != 2 || key == "" {
+			return nil, fmt.Errorf("invalid label %q, expected key:value", item)
+		}
+		labels[key] = strings.TrimSpace(kv[1])
+	}
+	return labels, nil
+}
+
+// MatchLabels 判断对象的元数据是否包含所有标签
+func (t *InjectTag) MatchLabels(obj Object) bool {
+	if len(t.Labels) == 0 {
+		return true
+	}
+	extra := obj.Meta().Extra
+	for k, v := range t.Labels {
+		if extra[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// VersionSelector 返回注入时使用的版本选择器
+// 标签中未显式指定版本时, 注入同名对象的最新版本
+func (t *InjectTag) VersionSelector() string {
+	if !t.versionSpecified {
+		return LATEST_VERSION
+	}
+	return t.Version
 }
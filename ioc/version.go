@@ -0,0 +1,120 @@
+package ioc
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// LATEST_VERSION 版本选择器: 选择同名对象中的最高版本
+	LATEST_VERSION = "latest"
+)
+
+// IsLatestVersion 判断版本选择器是否表示最新版本（空、latest 或 *）
+func IsLatestVersion(selector string) bool {
+	switch strings.ToLower(strings.TrimSpace(selector)) {
+	case "", LATEST_VERSION, "*":
+		return true
+	}
+	return false
+}
+
+// ValidateVersionSelector 校验版本选择器的格式, 格式错误时返回错误
+func ValidateVersionSelector(selector string) error {
+	_, err := parseVersionSelector(selector)
+	return err
+}
+
+// versionCondition 版本选择器中的比较条件
+type versionCondition struct {
+	op      string
+	version string
+}
+
+// parseVersionSelector 解析版本选择器中的比较条件, 表示最新版本时返回空
+func parseVersionSelector(selector string) ([]versionCondition, error) {
+	if IsLatestVersion(selector) {
+		return nil, nil
+	}
+
+	var conds []versionCondition
+	for _, cond := range strings.FieldsFunc(selector, func(r rune) bool {
+		return r == ' ' || r == ','
+	}) {
+		op, target := splitVersionOperator(cond)
+		if target == "" {
+			return nil, fmt.Errorf("invalid version selector %q: missing version after %q", selector, op)
+		}
+		conds = append(conds, versionCondition{op: op, version: target})
+	}
+	return conds, nil
+}
+
+// MatchVersion 判断版本是否满足版本选择器
+// 支持的选择器:
+//   - 精确版本: v1、v1.2.0、1.2.0（v1 与 1.0.0 视为同一版本）
+//   - 最新版本: latest、* 或空字符串
+//   - 语义化版本范围: 多个比较条件用空格或逗号分隔，如 ">=v1.2 <v2"
+//     支持的操作符: >=、<=、>、<、=、!=
+func MatchVersion(version, selector string) (bool, error) {
+	conds, err := parseVersionSelector(selector)
+	if err != nil {
+		return false, err
+	}
+	return matchConditions(version, conds), nil
+}
+
+func matchConditions(version string, conds []versionCondition) bool {
+	for _, cond := range conds {
+		cmp := CompareVersion(version, cond.version)
+		var ok bool
+		switch cond.op {
+		case ">=":
+			ok = cmp >= 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case "<":
+			ok = cmp < 0
+		case "!=":
+			ok = cmp != 0
+		default:
+			ok = cmp == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// splitVersionOperator 拆分比较条件中的操作符与版本号
+func splitVersionOperator(cond string) (op, version string) {
+	for _, prefix := range []string{">=", "<=", "!=", ">", "<", "="} {
+		if strings.HasPrefix(cond, prefix) {
+			return prefix, strings.TrimSpace(strings.TrimPrefix(cond, prefix))
+		}
+	}
+	return "", strings.TrimSpace(cond)
+}
+
+// ResolveVersion 从候选对象中选出满足版本选择器的最高版本
+// 没有满足条件的对象时返回 nil, 版本选择器格式错误时返回错误(与候选对象是否存在无关)
+func ResolveVersion(candidates []*ObjectWrapper, selector string) (*ObjectWrapper, error) {
+	conds, err := parseVersionSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched *ObjectWrapper
+	for _, item := range candidates {
+		if !matchConditions(item.Version, conds) {
+			continue
+		}
+		if matched == nil || CompareVersion(item.Version, matched.Version) > 0 {
+			matched = item
+		}
+	}
+	return matched, nil
+}
@@ -60,24 +60,34 @@ func TestCompareVersion(t *testing.T) {
 	}
 }
 
-func TestRegistryVersionOverwrite(t *testing.T) {
-	// 测试版本覆盖策略
-	t.Run("higher version overwrites lower version", func(t *testing.T) {
+func TestRegistryMultiVersion(t *testing.T) {
+	// 测试多版本并存策略
+	t.Run("higher version registered side by side", func(t *testing.T) {
 		store := ioc.DefaultStore.Namespace("test_version_overwrite_high")
 
 		// 注册 1.0.0 版本
 		obj1 := &TestVersionObject{name: "test_obj_v1", version: "1.0.0"}
 		store.Registry(obj1)
 
-		// 注册 2.0.0 版本（应该成功覆盖）
+		// 注册 2.0.0 版本（与 1.0.0 并存）
 		obj2 := &TestVersionObject{name: "test_obj_v1", version: "2.0.0"}
 		store.Registry(obj2)
 
-		// 验证已被覆盖为新版本
+		// 默认获取最新版本
 		got := store.Get("test_obj_v1")
 		if got.Version() != "2.0.0" {
 			t.Errorf("Expected version 2.0.0, got %s", got.Version())
 		}
+
+		// 旧版本仍然可以获取
+		got = store.Get("test_obj_v1", ioc.WithVersion("v1"))
+		if got == nil || got.Version() != "1.0.0" {
+			t.Errorf("Expected version 1.0.0, got %v", got)
+		}
+
+		if store.Len() != 2 {
+			t.Errorf("Expected 2 objects, got %d", store.Len())
+		}
 	})
 
 	t.Run("same version panics", func(t *testing.T) {
@@ -96,22 +106,22 @@ func TestRegistryVersionOverwrite(t *testing.T) {
 		store.Registry(obj2)
 	})
 
-	t.Run("lower version panics", func(t *testing.T) {
-		defer func() {
-			if r := recover(); r == nil {
-				t.Error("Expected panic for lower version")
-			}
-		}()
-
+	t.Run("lower version registered side by side", func(t *testing.T) {
 		store := ioc.DefaultStore.Namespace("test_lower_version")
 
-		// 注册 2.0.0 版本
-		obj1 := &TestVersionObject{name: "test_obj_v3", version: "2.0.0"}
-		store.Registry(obj1)
+		// 先注册 2.0.0 版本, 再注册 1.0.0 版本
+		store.Registry(&TestVersionObject{name: "test_obj_v3", version: "2.0.0"})
+		store.Registry(&TestVersionObject{name: "test_obj_v3", version: "1.0.0"})
 
-		// 注册 1.0.0 版本（应该 panic）
-		obj2 := &TestVersionObject{name: "test_obj_v3", version: "1.0.0"}
-		store.Registry(obj2)
+		got := store.Get("test_obj_v3")
+		if got.Version() != "2.0.0" {
+			t.Errorf("Expected version 2.0.0, got %s", got.Version())
+		}
+
+		list := store.List()
+		if len(list) != 2 {
+			t.Errorf("Expected 2 objects in list, got %v", list)
+		}
 	})
 
 	t.Run("v1 and 1.0.0 treated as duplicate", func(t *testing.T) {
@@ -129,6 +139,109 @@ func TestRegistryVersionOverwrite(t *testing.T) {
 	})
 }
 
+func TestMatchVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		selector string
+		expected bool
+	}{
+		{version: "v1", selector: "", expected: true},
+		{version: "v1", selector: "latest", expected: true},
+		{version: "v1", selector: "*", expected: true},
+		{version: "1.0.0", selector: "v1", expected: true},
+		{version: "v1.2.0", selector: "v1", expected: false},
+		{version: "v1.2.0", selector: ">=v1.2 <v2", expected: true},
+		{version: "v1.5.3", selector: ">=v1.2, <v2", expected: true},
+		{version: "v1.1.9", selector: ">=v1.2 <v2", expected: false},
+		{version: "v2", selector: ">=v1.2 <v2", expected: false},
+		{version: "v2", selector: ">v1", expected: true},
+		{version: "v1", selector: "<=v1", expected: true},
+		{version: "v1", selector: "!=v1", expected: false},
+		{version: "v1.1", selector: "=v1.1.0", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.selector, func(t *testing.T) {
+			ok, err := ioc.MatchVersion(tt.version, tt.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.expected {
+				t.Errorf("MatchVersion(%q, %q) = %v, want %v", tt.version, tt.selector, ok, tt.expected)
+			}
+		})
+	}
+
+	if _, err := ioc.MatchVersion("v1", ">="); err == nil {
+		t.Error("Expected error for selector without version")
+	}
+}
+
+func TestGetWithVersionSelector(t *testing.T) {
+	store := ioc.DefaultStore.Namespace("test_version_selector")
+	store.Registry(&TestVersionObject{name: "api", version: "v1"})
+	store.Registry(&TestVersionObject{name: "api", version: "v1.3.0"})
+	store.Registry(&TestVersionObject{name: "api", version: "v2"})
+
+	tests := []struct {
+		selector string
+		expected string
+	}{
+		{selector: "latest", expected: "v2"},
+		{selector: "v1", expected: "v1"},
+		{selector: ">=v1.2 <v2", expected: "v1.3.0"},
+		{selector: "<v2", expected: "v1.3.0"},
+		{selector: "v3", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			got := store.Get("api", ioc.WithVersion(tt.selector))
+			if tt.expected == "" {
+				if got != nil {
+					t.Errorf("Expected nil, got %s", got.Version())
+				}
+				return
+			}
+			if got == nil || got.Version() != tt.expected {
+				t.Errorf("Get(api, %q) = %v, want %s", tt.selector, got, tt.expected)
+			}
+		})
+	}
+
+	versions := store.Versions("api")
+	if len(versions) != 3 {
+		t.Errorf("Expected 3 versions, got %v", versions)
+	}
+}
+
+func TestAutowireWithVersion(t *testing.T) {
+	store := ioc.DefaultStore.Namespace("test_autowire_version")
+	store.Registry(&TestVersionObject{name: "repo", version: "v1"})
+	store.Registry(&TestVersionObject{name: "repo", version: "v2"})
+
+	consumer := &VersionConsumer{}
+	store.Registry(consumer)
+
+	if err := store.Autowire(); err != nil {
+		t.Fatal(err)
+	}
+
+	if consumer.Old == nil || consumer.Old.Version() != "v1" {
+		t.Errorf("Expected Old to be v1, got %v", consumer.Old)
+	}
+	if consumer.New == nil || consumer.New.Version() != "v2" {
+		t.Errorf("Expected New to be v2, got %v", consumer.New)
+	}
+}
+
+// VersionConsumer 按版本注入依赖的对象
+type VersionConsumer struct {
+	ioc.ObjectImpl
+	Old ioc.Object `ioc:"autowire=true;namespace=test_autowire_version;name=repo;version=v1"`
+	New ioc.Object `ioc:"autowire=true;namespace=test_autowire_version;name=repo;version=>=v2"`
+}
+
 // TestVersionObject 用于版本测试的对象
 type TestVersionObject struct {
 	ioc.ObjectImpl
@@ -143,3 +256,24 @@ func (o *TestVersionObject) Name() string {
 func (o *TestVersionObject) Version() string {
 	return o.version
 }
+
+func TestMalformedVersionSelector(t *testing.T) {
+	if err := ioc.ValidateVersionSelector(">="); err == nil {
+		t.Error("Expected error for selector without version")
+	}
+	if _, err := ioc.ResolveVersion(nil, ">="); err == nil {
+		t.Error("Expected ResolveVersion to report malformed selector")
+	}
+
+	store := ioc.DefaultStore.Namespace("test_malformed_selector")
+	store.Registry(&TestVersionObject{name: "api", version: "v1"})
+	if _, err := store.Lookup("api", ioc.WithVersion(">=")); err == nil {
+		t.Error("Expected Lookup to report malformed selector")
+	}
+	if obj, err := store.Lookup("missing"); obj != nil || err != nil {
+		t.Errorf("Expected nil, nil for missing object, got %v, %v", obj, err)
+	}
+	if _, err := ioc.ParseInjectTagWithError("autowire=true;version=>="); err == nil {
+		t.Error("Expected inject tag with malformed version to fail")
+	}
+}